@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultContextPackBytes is the budget used when none is given
+const DefaultContextPackBytes = 8000
+
+// minContextPackBytes leaves room for at least the summary and omission note
+const minContextPackBytes = 256
+
+// contextPackManifests lists the manifest files included in a context pack
+var contextPackManifests = []string{
+	"go.mod",
+	"package.json",
+	"Cargo.toml",
+	"pyproject.toml",
+	"requirements.txt",
+	"pom.xml",
+	"build.gradle",
+	"Gemfile",
+	"composer.json",
+}
+
+// ContextPack is repository context assembled to fit a byte budget
+type ContextPack struct {
+	Content   string   `json:"content"`
+	Included  []string `json:"included"`
+	Truncated []string `json:"truncated,omitempty"`
+	Omitted   []string `json:"omitted,omitempty"`
+}
+
+// contextSection is one block of a context pack. Truncatable sections may be
+// cut at a line boundary instead of being dropped when they do not fit.
+type contextSection struct {
+	title       string
+	body        string
+	truncatable bool
+}
+
+// BuildContextPack greedily fills maxBytes with repository context in priority
+// order: summary, README, manifests, recent commits, then the file tree
+func BuildContextPack(repoPath string, maxBytes int) (*ContextPack, error) {
+	if maxBytes < minContextPackBytes {
+		return nil, fmt.Errorf("max_bytes must be at least %d", minContextPackBytes)
+	}
+
+	info, err := GetRepositoryInfo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []contextSection{{title: "Summary", body: contextPackSummary(info)}}
+
+	if info.ReadmeContent != "" {
+		sections = append(sections, contextSection{title: "README", body: info.ReadmeContent, truncatable: true})
+	}
+
+	for _, name := range contextPackManifests {
+		if _, err := os.Stat(filepath.Join(info.Path, name)); err != nil {
+			continue
+		}
+		content, _, _, _, err := GetFileContentWithLineNumbers(repoPath, name, 1, 50, false)
+		if err != nil {
+			continue
+		}
+		sections = append(sections, contextSection{title: name, body: content, truncatable: true})
+	}
+
+	if commits, err := ListCommits(repoPath, 10); err == nil && len(commits) > 0 {
+		var body strings.Builder
+		for _, commit := range commits {
+			hash := commit.Hash
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			body.WriteString(fmt.Sprintf("%s %s (%s)\n", hash, commit.Message, commit.Author))
+		}
+		sections = append(sections, contextSection{title: "Recent commits", body: body.String(), truncatable: true})
+	}
+
+	if files, err := ListFiles(repoPath, ".", true, nil, nil, 200); err == nil && len(files) > 0 {
+		var body strings.Builder
+		for _, file := range files {
+			body.WriteString(file.Path + "\n")
+		}
+		sections = append(sections, contextSection{title: "File tree", body: body.String(), truncatable: true})
+	}
+
+	return fillContextPack(sections, maxBytes), nil
+}
+
+func contextPackSummary(info *RepositoryInfo) string {
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Repository: %s\n", filepath.Base(info.Path)))
+	summary.WriteString(fmt.Sprintf("Branch: %s\n", info.CurrentBranch))
+	if !info.LastUpdate.IsZero() {
+		summary.WriteString(fmt.Sprintf("Updated: %s\n", info.LastUpdate.Format("2006-01-02 15:04:05")))
+	}
+	if info.RemoteURL != "" {
+		summary.WriteString(fmt.Sprintf("Remote: %s\n", info.RemoteURL))
+	}
+	if info.License != "" {
+		summary.WriteString(fmt.Sprintf("License: %s\n", formatLicense(info)))
+	}
+	return summary.String()
+}
+
+// fillContextPack adds sections in order until the budget runs out. Room for
+// the trailing omission note is reserved up front so the result never exceeds
+// maxBytes.
+func fillContextPack(sections []contextSection, maxBytes int) *ContextPack {
+	var titles []string
+	for _, section := range sections {
+		titles = append(titles, section.title)
+	}
+	noteFor := func(omitted, truncated []string) string {
+		var note strings.Builder
+		if len(truncated) > 0 {
+			note.WriteString(fmt.Sprintf("[truncated: %s]\n", strings.Join(truncated, ", ")))
+		}
+		if len(omitted) > 0 {
+			note.WriteString(fmt.Sprintf("[omitted (budget %d bytes): %s]\n", maxBytes, strings.Join(omitted, ", ")))
+		}
+		return note.String()
+	}
+	budget := maxBytes - len(noteFor(titles, titles))
+
+	pack := &ContextPack{}
+	var content strings.Builder
+
+	for _, section := range sections {
+		header := fmt.Sprintf("## %s\n", section.title)
+		body := strings.TrimRight(section.body, "\n") + "\n"
+		remaining := budget - content.Len()
+
+		if len(header)+len(body)+1 <= remaining {
+			content.WriteString(header + body + "\n")
+			pack.Included = append(pack.Included, section.title)
+			continue
+		}
+
+		if section.truncatable {
+			const marker = "...\n"
+			room := remaining - len(header) - len(marker) - 1
+			if cut := truncateAtLine(body, room); cut != "" {
+				content.WriteString(header + cut + marker + "\n")
+				pack.Included = append(pack.Included, section.title)
+				pack.Truncated = append(pack.Truncated, section.title)
+				continue
+			}
+		}
+
+		pack.Omitted = append(pack.Omitted, section.title)
+	}
+
+	content.WriteString(noteFor(pack.Omitted, pack.Truncated))
+	pack.Content = content.String()
+	return pack
+}
+
+// truncateAtLine returns the longest prefix of text made of whole lines that
+// fits in limit bytes
+func truncateAtLine(text string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if len(text) <= limit {
+		return text
+	}
+	cut := strings.LastIndex(text[:limit], "\n")
+	if cut < 0 {
+		return ""
+	}
+	return text[:cut+1]
+}
+
+// Defaults and output budget for ExportRepositoryOverview
+const (
+	DefaultOverviewTreeFiles   = 200
+	DefaultOverviewReadmeLines = 100
+	maxOverviewBytes           = 64 * 1024
+	overviewCommits            = 10
+)
+
+// ExportRepositoryOverview renders a self-contained Markdown document with a
+// title, metadata, a fenced directory tree, the start of the README and the
+// latest commits. Sections are added in that order until maxOverviewBytes is
+// used up. Non-positive limits fall back to the defaults.
+func ExportRepositoryOverview(repoPath string, maxFilesInTree, readmeLines int) (string, error) {
+	if maxFilesInTree <= 0 {
+		maxFilesInTree = DefaultOverviewTreeFiles
+	}
+	if readmeLines <= 0 {
+		readmeLines = DefaultOverviewReadmeLines
+	}
+
+	info, err := GetRepositoryInfo(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	sections := []contextSection{{title: "Metadata", body: overviewMetadata(info)}}
+
+	// Fetch one extra entry to tell whether the tree was cut off
+	if files, err := ListFiles(repoPath, ".", true, nil, nil, maxFilesInTree+1); err == nil && len(files) > 0 {
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		more := len(paths) > maxFilesInTree
+		if more {
+			paths = paths[:maxFilesInTree]
+		}
+		body := "```\n" + renderPathTree(paths) + "```\n"
+		if more {
+			body += fmt.Sprintf("\n_Only the first %d files are shown._\n", maxFilesInTree)
+		}
+		sections = append(sections, contextSection{title: "Directory tree", body: body})
+	}
+
+	if info.ReadmeContent != "" {
+		lines := strings.Split(strings.TrimRight(info.ReadmeContent, "\n"), "\n")
+		body := strings.Join(lines[:min(len(lines), readmeLines)], "\n") + "\n"
+		if len(lines) > readmeLines {
+			body += fmt.Sprintf("\n_README truncated after %d of %d lines._\n", readmeLines, len(lines))
+		}
+		sections = append(sections, contextSection{title: "README", body: body, truncatable: true})
+	}
+
+	if commits, err := ListCommits(repoPath, overviewCommits); err == nil && len(commits) > 0 {
+		var body strings.Builder
+		for _, commit := range commits {
+			hash := commit.Hash
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			// Dates come from --date=iso; keep only the day
+			date := commit.Date
+			if len(date) > 10 {
+				date = date[:10]
+			}
+			body.WriteString(fmt.Sprintf("- `%s` %s (%s, %s)\n", hash, commit.Message, commit.Author, date))
+		}
+		sections = append(sections, contextSection{title: "Recent commits", body: body.String(), truncatable: true})
+	}
+
+	title := fmt.Sprintf("# %s\n\n", filepath.Base(info.Path))
+	pack := fillContextPack(sections, maxOverviewBytes-len(title))
+	return title + pack.Content, nil
+}
+
+func overviewMetadata(info *RepositoryInfo) string {
+	var metadata strings.Builder
+	metadata.WriteString(fmt.Sprintf("- **Branch:** %s\n", info.CurrentBranch))
+	if count, err := getCommitCount(info.Path); err == nil {
+		metadata.WriteString(fmt.Sprintf("- **Commits:** %d\n", count))
+	}
+	if !info.LastUpdate.IsZero() {
+		metadata.WriteString(fmt.Sprintf("- **Last commit:** %s\n", info.LastUpdate.Format("2006-01-02 15:04:05")))
+	}
+	if info.RemoteURL != "" {
+		metadata.WriteString(fmt.Sprintf("- **Remote:** %s\n", info.RemoteURL))
+	}
+	if info.License != "" {
+		metadata.WriteString(fmt.Sprintf("- **License:** %s\n", formatLicense(info)))
+	}
+	return metadata.String()
+}
+
+// renderPathTree indents paths sorted by lessPathDirsFirst into a tree,
+// printing each directory once before its contents
+func renderPathTree(paths []string) string {
+	var tree strings.Builder
+	var openDirs []string
+
+	for _, p := range paths {
+		parts := strings.Split(filepath.ToSlash(p), "/")
+		dirs := parts[:len(parts)-1]
+
+		common := 0
+		for common < len(openDirs) && common < len(dirs) && openDirs[common] == dirs[common] {
+			common++
+		}
+		for depth := common; depth < len(dirs); depth++ {
+			tree.WriteString(strings.Repeat("  ", depth) + dirs[depth] + "/\n")
+		}
+		openDirs = dirs
+
+		tree.WriteString(strings.Repeat("  ", len(dirs)) + parts[len(parts)-1] + "\n")
+	}
+
+	return tree.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildContextPack(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("go.mod", "module example.com/test\n\ngo 1.23\n")
+	repo.AddCommit("Add go.mod")
+
+	t.Run("large budget includes every section in priority order", func(t *testing.T) {
+		pack, err := BuildContextPack(repo.Path, 100000)
+		if err != nil {
+			t.Fatalf("BuildContextPack failed: %v", err)
+		}
+
+		expected := []string{"Summary", "README", "go.mod", "Recent commits", "File tree"}
+		if strings.Join(pack.Included, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected sections %v, got %v", expected, pack.Included)
+		}
+		if len(pack.Omitted) != 0 || strings.Contains(pack.Content, "[omitted") {
+			t.Errorf("Expected nothing omitted, got %v", pack.Omitted)
+		}
+
+		last := -1
+		for _, title := range expected {
+			idx := strings.Index(pack.Content, "## "+title+"\n")
+			if idx <= last {
+				t.Errorf("Section %s out of order in:\n%s", title, pack.Content)
+			}
+			last = idx
+		}
+	})
+
+	t.Run("small budgets are respected", func(t *testing.T) {
+		for _, budget := range []int{256, 400, 700, 1200} {
+			pack, err := BuildContextPack(repo.Path, budget)
+			if err != nil {
+				t.Fatalf("BuildContextPack(%d) failed: %v", budget, err)
+			}
+			if len(pack.Content) > budget {
+				t.Errorf("Budget %d exceeded: %d bytes", budget, len(pack.Content))
+			}
+			if len(pack.Included) == 0 || pack.Included[0] != "Summary" {
+				t.Errorf("Budget %d: expected Summary first, got %v", budget, pack.Included)
+			}
+			if len(pack.Omitted) > 0 && !strings.Contains(pack.Content, "[omitted") {
+				t.Errorf("Budget %d: expected omission note in:\n%s", budget, pack.Content)
+			}
+		}
+	})
+
+	t.Run("budget too small", func(t *testing.T) {
+		if _, err := BuildContextPack(repo.Path, 10); err == nil {
+			t.Error("Expected error for tiny budget")
+		}
+	})
+}
+
+func TestFillContextPack(t *testing.T) {
+	sections := []contextSection{
+		{title: "First", body: strings.Repeat("a", 50)},
+		{title: "Second", body: strings.Repeat("line\n", 40), truncatable: true},
+		{title: "Third", body: strings.Repeat("c", 200)},
+	}
+
+	pack := fillContextPack(sections, 300)
+	if len(pack.Content) > 300 {
+		t.Errorf("Budget exceeded: %d bytes", len(pack.Content))
+	}
+	if strings.Join(pack.Included, ",") != "First,Second" {
+		t.Errorf("Expected First and Second included, got %v", pack.Included)
+	}
+	if strings.Join(pack.Truncated, ",") != "Second" || strings.Join(pack.Omitted, ",") != "Third" {
+		t.Errorf("Expected Second truncated and Third omitted, got %v / %v", pack.Truncated, pack.Omitted)
+	}
+	if !strings.Contains(pack.Content, "[omitted (budget 300 bytes): Third]") {
+		t.Errorf("Expected omission note, got:\n%s", pack.Content)
+	}
+}
+
+func TestExportRepositoryOverview(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	markdown, err := ExportRepositoryOverview(repo.Path, 0, 0)
+	if err != nil {
+		t.Fatalf("ExportRepositoryOverview failed: %v", err)
+	}
+
+	if !strings.HasPrefix(markdown, "# test-repo\n") {
+		t.Errorf("Expected repository title, got:\n%s", markdown)
+	}
+	last := -1
+	for _, heading := range []string{"## Metadata\n", "## Directory tree\n", "## README\n", "## Recent commits\n"} {
+		idx := strings.Index(markdown, heading)
+		if idx <= last {
+			t.Errorf("Section %q missing or out of order in:\n%s", heading, markdown)
+		}
+		last = idx
+	}
+	for _, want := range []string{"```\ndocs/\n  api.md\nsrc/\n  utils.go\n", "This is a test repository", "Add configuration"} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected %q in:\n%s", want, markdown)
+		}
+	}
+
+	t.Run("limits", func(t *testing.T) {
+		markdown, err := ExportRepositoryOverview(repo.Path, 2, 1)
+		if err != nil {
+			t.Fatalf("ExportRepositoryOverview failed: %v", err)
+		}
+		if !strings.Contains(markdown, "_Only the first 2 files are shown._") {
+			t.Errorf("Expected tree limit note in:\n%s", markdown)
+		}
+		if !strings.Contains(markdown, "_README truncated after 1 of 3 lines._") {
+			t.Errorf("Expected README limit note in:\n%s", markdown)
+		}
+	})
+}
+
+func TestRenderPathTree(t *testing.T) {
+	got := renderPathTree([]string{"a/b/c.go", "a/b/d.go", "a/e.go", "f/g.go", "top.go"})
+	want := "a/\n  b/\n    c.go\n    d.go\n  e.go\nf/\n  g.go\ntop.go\n"
+	if got != want {
+		t.Errorf("Unexpected tree:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -237,6 +237,34 @@ func TestEdgeCasesAndErrorHandling(t *testing.T) {
 		}
 	})
 
+	t.Run("file over the size cap", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+		repo.CreateLargeFile("huge.txt", 20*1024)
+
+		_, err := GetFileContent(repo.Path, "huge.txt", 0)
+		if err == nil {
+			t.Fatal("Expected full read of a 20MB file to be refused")
+		}
+		if !strings.Contains(err.Error(), "max_lines") {
+			t.Errorf("Expected error to suggest max_lines, got: %v", err)
+		}
+
+		content, err := GetFileContent(repo.Path, "huge.txt", 10)
+		if err != nil {
+			t.Fatalf("Expected limited read to succeed, got: %v", err)
+		}
+		if !strings.HasPrefix(content, "ABCDEF") {
+			t.Errorf("Unexpected content start: %q", content[:min(len(content), 20)])
+		}
+
+		// Raising the cap through the session allows the full read
+		SetSessionConfigValues(&SessionConfig{DefaultMaxFileBytes: 32 * 1024 * 1024})
+		defer ClearSessionConfig()
+		if _, err := GetFileContent(repo.Path, "huge.txt", 0); err != nil {
+			t.Errorf("Expected full read under a raised cap, got: %v", err)
+		}
+	})
+
 	t.Run("symbolic links", func(t *testing.T) {
 		repo := CreateTestRepositoryWithContent(t)
 
@@ -278,6 +306,79 @@ func TestEdgeCasesAndErrorHandling(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("symbolic link escaping the workspace", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+
+		outsideFile := filepath.Join(t.TempDir(), "secret.txt")
+		if err := os.WriteFile(outsideFile, []byte("outside secret\n"), 0644); err != nil {
+			t.Fatalf("Failed to write outside file: %v", err)
+		}
+		if err := os.Symlink(outsideFile, filepath.Join(repo.Path, "escape.txt")); err != nil {
+			t.Skip("Symbolic links not supported on this system")
+		}
+
+		if content, err := GetFileContent(repo.Path, "escape.txt", 0); err == nil {
+			t.Errorf("Expected symlink outside the workspace to be refused, got %q", content)
+		} else if !strings.Contains(err.Error(), "outside the workspace") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if _, _, _, _, err := GetFileContentWithLineNumbers(repo.Path, "escape.txt", 1, 10, true); err == nil {
+			t.Errorf("Expected line-numbered read through the symlink to be refused")
+		}
+		if _, err := GetFileStats(repo.Path, "escape.txt"); err == nil {
+			t.Errorf("Expected file stats through the symlink to be refused")
+		}
+
+		// The link is still listed, but its target is not read
+		files, err := ListFiles(repo.Path, ".", false, nil, nil, 50)
+		if err != nil {
+			t.Fatalf("ListFiles failed: %v", err)
+		}
+		for _, file := range files {
+			if file.Name == "escape.txt" && file.LineCount != 0 {
+				t.Errorf("Expected no line count for an escaping symlink, got %d", file.LineCount)
+			}
+		}
+
+		// Directories outside the repository are not listed, by path or through a symlink
+		if err := os.Symlink(filepath.Dir(outsideFile), filepath.Join(repo.Path, "ext")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		for _, dir := range []string{"ext", "../.."} {
+			if files, err := ListFiles(repo.Path, dir, false, nil, nil, 50); err == nil {
+				t.Errorf("Expected listing %q to be refused, got %v", dir, files)
+			}
+		}
+
+		// The binary check reveals nothing about files outside the workspace
+		outsideBinary := filepath.Join(filepath.Dir(outsideFile), "blob.bin")
+		if err := os.WriteFile(outsideBinary, []byte("\x00\x01\x02"), 0644); err != nil {
+			t.Fatalf("Failed to write outside file: %v", err)
+		}
+		rel, err := filepath.Rel(repo.Path, outsideBinary)
+		if err != nil {
+			t.Fatalf("Failed to make relative path: %v", err)
+		}
+		result, _, err := handleGetFileContent(context.Background(), nil, GetFileContentParams{Repository: repo.Path, FilePath: rel})
+		if err != nil {
+			t.Fatalf("handleGetFileContent failed: %v", err)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !result.IsError || strings.Contains(text, "binary") {
+			t.Errorf("Expected the outside file to be refused without describing it, got:\n%s", text)
+		}
+		if _, err := GetFileRanges(repo.Path, rel, []LineRange{{Start: 1, End: 1}}); err == nil || strings.Contains(err.Error(), "binary") {
+			t.Errorf("Expected get_file_ranges to refuse the outside file, got %v", err)
+		}
+
+		// Symlinks that stay inside the repository still work
+		if err := os.Symlink("README.md", filepath.Join(repo.Path, "readme-link.md")); err != nil {
+			t.Fatalf("Failed to create symlink: %v", err)
+		}
+		if _, err := GetFileContent(repo.Path, "readme-link.md", 0); err != nil {
+			t.Errorf("Expected in-repository symlink to be readable, got: %v", err)
+		}
+	})
 }
 
 // TestMCPToolEdgeCases tests edge cases in MCP tool handlers
@@ -445,7 +546,7 @@ func TestErrorRecovery(t *testing.T) {
 		repo := CreateTestRepositoryWithContent(t)
 
 		// Cause an error and ensure state is not corrupted
-		_, err := SwitchBranch(repo.Path, "nonexistent")
+		_, err := SwitchBranch(repo.Path, "nonexistent", false)
 		if err == nil {
 			t.Errorf("Expected error for nonexistent branch")
 		}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Text encodings recognized by detectEncoding and accepted as overrides
+const (
+	encodingUTF8     = "utf-8"
+	encodingUTF16LE  = "utf-16le"
+	encodingUTF16BE  = "utf-16be"
+	encodingShiftJIS = "shift_jis"
+	encodingLatin1   = "iso-8859-1"
+)
+
+// normalizeEncoding maps an encoding name or common alias to one of the
+// supported encodings. An empty name stays empty, meaning "detect".
+func normalizeEncoding(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "":
+		return "", nil
+	case "utf-8", "utf8":
+		return encodingUTF8, nil
+	case "utf-16le", "utf16le", "utf-16", "utf16":
+		return encodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return encodingUTF16BE, nil
+	case "shift_jis", "shift-jis", "sjis", "cp932", "windows-31j":
+		return encodingShiftJIS, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		return encodingLatin1, nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q (expected utf-8, utf-16le, utf-16be, shift_jis or iso-8859-1)", name)
+}
+
+// detectEncoding guesses the encoding of a file from its first bytes: a byte
+// order mark wins, then UTF-16 is recognized by NULs in every other byte, then
+// valid UTF-8 and valid Shift_JIS are tried, and anything else is taken to be
+// Latin-1. Other content with NUL bytes is binary and reported as UTF-8 so it
+// passes through untouched.
+func detectEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte(utf8BOM)):
+		return encodingUTF8
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return encodingUTF16LE
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return encodingUTF16BE
+	}
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		var evenNUL, oddNUL int
+		for i, b := range head {
+			if b != 0 {
+				continue
+			}
+			if i%2 == 0 {
+				evenNUL++
+			} else {
+				oddNUL++
+			}
+		}
+		// ASCII-heavy UTF-16 has a NUL in nearly every other byte and none opposite
+		pairs := len(head) / 2
+		switch {
+		case oddNUL*10 >= pairs*3 && evenNUL*20 < pairs:
+			return encodingUTF16LE
+		case evenNUL*10 >= pairs*3 && oddNUL*20 < pairs:
+			return encodingUTF16BE
+		}
+		return encodingUTF8
+	}
+
+	if validUTF8Head(head) {
+		return encodingUTF8
+	}
+	if validShiftJIS(head) {
+		return encodingShiftJIS
+	}
+	return encodingLatin1
+}
+
+// validUTF8Head reports whether head is valid UTF-8, allowing it to end part
+// way through a character since it may be cut from a longer file
+func validUTF8Head(head []byte) bool {
+	if utf8.Valid(head) {
+		return true
+	}
+	for cut := 1; cut < utf8.UTFMax && cut <= len(head); cut++ {
+		if utf8.RuneStart(head[len(head)-cut]) {
+			return !utf8.FullRune(head[len(head)-cut:]) && utf8.Valid(head[:len(head)-cut])
+		}
+	}
+	return false
+}
+
+// validShiftJIS reports whether head is well-formed Shift_JIS with at least one
+// mapped double-byte character, allowing a lead byte cut off at the end
+func validShiftJIS(head []byte) bool {
+	doubleByte := 0
+	for i := 0; i < len(head); i++ {
+		b := head[i]
+		if b < 0x80 || (b >= 0xA1 && b <= 0xDF) {
+			continue
+		}
+		if i+1 == len(head) {
+			break
+		}
+		if decodeShiftJISPair(b, head[i+1]) == utf8.RuneError {
+			return false
+		}
+		doubleByte++
+		i++
+	}
+	return doubleByte > 0
+}
+
+// shiftJISRunes holds shiftJISTable split into runes for indexing
+var shiftJISRunes = sync.OnceValue(func() [][]rune {
+	rows := make([][]rune, len(shiftJISTable))
+	for i, row := range shiftJISTable {
+		rows[i] = []rune(row)
+	}
+	return rows
+})
+
+// decodeShiftJISPair returns the character for a Shift_JIS lead and trail
+// byte, or utf8.RuneError when the pair is invalid or unmapped
+func decodeShiftJISPair(lead, trail byte) rune {
+	var row int
+	switch {
+	case lead >= 0x81 && lead <= 0x9F:
+		row = int(lead - 0x81)
+	case lead >= 0xE0 && lead <= 0xFC:
+		row = int(lead-0xE0) + 0x1F
+	default:
+		return utf8.RuneError
+	}
+
+	var col int
+	switch {
+	case trail >= 0x40 && trail <= 0x7E:
+		col = int(trail - 0x40)
+	case trail >= 0x80 && trail <= 0xFC:
+		col = int(trail-0x80) + 0x3F
+	default:
+		return utf8.RuneError
+	}
+	return shiftJISRunes()[row][col]
+}
+
+// newDecodingReader returns a reader that transcodes r from encoding to UTF-8.
+// UTF-8 input is returned unchanged; invalid input decodes to U+FFFD.
+func newDecodingReader(r io.Reader, encoding string) io.Reader {
+	var decode func(*bufio.Reader) (rune, error)
+	switch encoding {
+	case encodingUTF16LE:
+		decode = func(src *bufio.Reader) (rune, error) { return decodeUTF16(src, false) }
+	case encodingUTF16BE:
+		decode = func(src *bufio.Reader) (rune, error) { return decodeUTF16(src, true) }
+	case encodingShiftJIS:
+		decode = decodeShiftJIS
+	case encodingLatin1:
+		decode = func(src *bufio.Reader) (rune, error) {
+			b, err := src.ReadByte()
+			return rune(b), err
+		}
+	default:
+		return r
+	}
+	return &decodingReader{src: bufio.NewReader(r), decode: decode}
+}
+
+// decodingReader produces UTF-8 from a source read one character at a time
+type decodingReader struct {
+	src    *bufio.Reader
+	decode func(*bufio.Reader) (rune, error)
+	buf    []byte // encoded output not yet returned
+	err    error
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for len(d.buf) < len(p) && d.err == nil {
+		r, err := d.decode(d.src)
+		if err != nil {
+			d.err = err
+			break
+		}
+		d.buf = utf8.AppendRune(d.buf, r)
+	}
+	if len(d.buf) == 0 {
+		return 0, d.err
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+// decodeUTF16 reads one character of UTF-16, joining surrogate pairs. A lone
+// trailing byte or unpaired surrogate decodes to U+FFFD.
+func decodeUTF16(src *bufio.Reader, bigEndian bool) (rune, error) {
+	unit, err := readUTF16Unit(src, bigEndian)
+	if err != nil {
+		return 0, err
+	}
+	if !utf16.IsSurrogate(rune(unit)) {
+		return rune(unit), nil
+	}
+	if next, err := src.Peek(2); err == nil {
+		second := uint16(next[0]) | uint16(next[1])<<8
+		if bigEndian {
+			second = uint16(next[0])<<8 | uint16(next[1])
+		}
+		if r := utf16.DecodeRune(rune(unit), rune(second)); r != utf8.RuneError {
+			src.Discard(2)
+			return r, nil
+		}
+	}
+	return utf8.RuneError, nil
+}
+
+// readUTF16Unit reads one 16-bit code unit
+func readUTF16Unit(src *bufio.Reader, bigEndian bool) (uint16, error) {
+	lo, err := src.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := src.ReadByte()
+	if err == io.EOF {
+		return utf8.RuneError, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if bigEndian {
+		lo, hi = hi, lo
+	}
+	return uint16(lo) | uint16(hi)<<8, nil
+}
+
+// decodeShiftJIS reads one Shift_JIS character: ASCII, half-width katakana or
+// a double-byte pair
+func decodeShiftJIS(src *bufio.Reader) (rune, error) {
+	b, err := src.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case b < 0x80:
+		return rune(b), nil
+	case b >= 0xA1 && b <= 0xDF:
+		return 0xFF61 + rune(b-0xA1), nil
+	}
+
+	trail, err := src.ReadByte()
+	if err == io.EOF {
+		return utf8.RuneError, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	r := decodeShiftJISPair(b, trail)
+	if r == utf8.RuneError && trail < 0x80 {
+		// Keep an ASCII byte that follows an invalid lead byte
+		src.UnreadByte()
+	}
+	return r, nil
+}
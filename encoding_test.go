@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Shift_JIS encodings of "こんにちは\n日本語のテキスト\nｶﾀｶﾅ\n"
+const shiftJISSample = "\x82\xb1\x82\xf1\x82\xc9\x82\xbf\x82\xcd\n" +
+	"\x93\xfa\x96\x7b\x8c\xea\x82\xcc\x83\x65\x83\x4c\x83\x58\x83\x67\n" +
+	"\xb6\xc0\xb6\xc5\n"
+
+// encodeUTF16 encodes s as UTF-16 with an optional byte order mark
+func encodeUTF16(s string, bigEndian, bom bool) string {
+	var b strings.Builder
+	put := func(u uint16) {
+		if bigEndian {
+			b.WriteByte(byte(u >> 8))
+			b.WriteByte(byte(u))
+		} else {
+			b.WriteByte(byte(u))
+			b.WriteByte(byte(u >> 8))
+		}
+	}
+	if bom {
+		put(0xFEFF)
+	}
+	for _, r := range s {
+		if r >= 0x10000 {
+			r -= 0x10000
+			put(uint16(0xD800 + r>>10))
+			put(uint16(0xDC00 + r&0x3FF))
+			continue
+		}
+		put(uint16(r))
+	}
+	return b.String()
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"ascii", "hello world\n", encodingUTF8},
+		{"utf-8", "héllo wörld\n", encodingUTF8},
+		{"utf-8 cut mid-character", "héllo 日"[:9], encodingUTF8},
+		{"utf-8 bom", "\ufeffhello\n", encodingUTF8},
+		{"utf-16le bom", encodeUTF16("hello\n", false, true), encodingUTF16LE},
+		{"utf-16be bom", encodeUTF16("hello\n", true, true), encodingUTF16BE},
+		{"utf-16le without bom", encodeUTF16("hello world\n", false, false), encodingUTF16LE},
+		{"utf-16be without bom", encodeUTF16("hello world\n", true, false), encodingUTF16BE},
+		{"shift_jis", shiftJISSample, encodingShiftJIS},
+		{"latin-1", "caf\xe9 cr\xe8me br\xfbl\xe9e\n", encodingLatin1},
+		{"binary", "\x00\x00\x00\x01\x02\x03\x00\x00\x00\x00", encodingUTF8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEncoding([]byte(tt.content)); got != tt.want {
+				t.Errorf("detectEncoding(%q) = %s, want %s", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	for name, want := range map[string]string{"": "", "UTF8": encodingUTF8, "sjis": encodingShiftJIS, "CP932": encodingShiftJIS, "latin1": encodingLatin1, "utf-16": encodingUTF16LE} {
+		if got, err := normalizeEncoding(name); err != nil || got != want {
+			t.Errorf("normalizeEncoding(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := normalizeEncoding("ebcdic"); err == nil {
+		t.Error("Expected an error for an unsupported encoding")
+	}
+}
+
+func TestHandleGetFileContentEncoding(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("utf16.txt", encodeUTF16("héllo\r\nwörld 😀\r\n", false, true))
+	repo.WriteFile("sjis.txt", shiftJISSample)
+	repo.WriteFile("latin1.txt", "caf\xe9\n")
+
+	read := func(t *testing.T, params GetFileContentParams) (string, bool) {
+		t.Helper()
+		params.Repository = repo.Path
+		result, _, err := handleGetFileContent(context.Background(), nil, params)
+		if err != nil {
+			t.Fatalf("handleGetFileContent failed: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	t.Run("utf-16le", func(t *testing.T) {
+		// NUL bytes in UTF-16 text must not be mistaken for a binary file
+		text, isError := read(t, GetFileContentParams{FilePath: "utf16.txt"})
+		want := "[utf16.txt L1-2/2 utf-16le]\n   1: héllo\n   2: wörld 😀\n"
+		if isError || text != want {
+			t.Errorf("Expected decoded UTF-16:\n%q\ngot:\n%q", want, text)
+		}
+	})
+
+	t.Run("shift_jis", func(t *testing.T) {
+		text, isError := read(t, GetFileContentParams{FilePath: "sjis.txt", OutputFormat: "json"})
+		if isError {
+			t.Fatalf("Unexpected error: %s", text)
+		}
+		var results []FileContentResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to decode JSON: %v", err)
+		}
+		if len(results) != 1 || results[0].Encoding != encodingShiftJIS || results[0].Content != "こんにちは\n日本語のテキスト\nｶﾀｶﾅ\n" {
+			t.Errorf("Expected decoded Shift_JIS, got %+v", results)
+		}
+	})
+
+	t.Run("utf-8 reported", func(t *testing.T) {
+		text, _ := read(t, GetFileContentParams{FilePaths: []string{"README.md", "latin1.txt"}, OutputFormat: "json"})
+		var results []FileContentResult
+		if err := json.Unmarshal([]byte(text), &results); err != nil {
+			t.Fatalf("Failed to decode JSON: %v", err)
+		}
+		if len(results) != 2 || results[0].Encoding != encodingUTF8 || results[1].Encoding != encodingLatin1 || results[1].Content != "café\n" {
+			t.Errorf("Expected utf-8 and iso-8859-1, got %+v", results)
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		text, isError := read(t, GetFileContentParams{FilePath: "sjis.txt", Encoding: "latin1", StartLine: 3})
+		if isError || text != "[sjis.txt L3-3/3 iso-8859-1]\n   3: ¶À¶Å\n" {
+			t.Errorf("Expected the forced encoding to be used, got:\n%q", text)
+		}
+
+		text, isError = read(t, GetFileContentParams{FilePath: "sjis.txt", Encoding: "ebcdic"})
+		if !isError || !strings.Contains(text, "unsupported encoding") {
+			t.Errorf("Expected an unsupported encoding error, got:\n%s", text)
+		}
+	})
+}
@@ -214,7 +214,7 @@ func TestPatternFilteringSearchFiles(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := SearchFiles(repo.Path, tt.keywords, "and", false, 0, tt.includePatterns, tt.excludePatterns, 100)
+			results, err := SearchFiles(repo.Path, tt.keywords, "and", false, 0, tt.includePatterns, tt.excludePatterns, nil, 100)
 			if err != nil {
 				t.Fatalf("SearchFiles failed: %v", err)
 			}
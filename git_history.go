@@ -0,0 +1,637 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlameLine represents a single line of git blame output
+type BlameLine struct {
+	LineNumber int    `json:"line_number"`
+	CommitHash string `json:"commit_hash"` // short hash
+	Author     string `json:"author"`
+	Date       string `json:"date"`
+	Content    string `json:"content"`
+}
+
+// BlobInfo describes a blob stored somewhere in the repository history
+type BlobInfo struct {
+	SHA   string   `json:"sha"`
+	Size  int64    `json:"size"`
+	Paths []string `json:"paths"`
+}
+
+// AuthorContribution is the number of commits an author made to a file
+type AuthorContribution struct {
+	Author  string `json:"author"`
+	Commits int    `json:"commits"`
+}
+
+// Contributor is an author of the current branch's history, as reported by
+// git shortlog (identities are merged according to .mailmap)
+type Contributor struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	CommitCount int    `json:"commit_count"`
+}
+
+// OwnershipShare is an author's share of the current lines under a directory
+type OwnershipShare struct {
+	Author  string  `json:"author"`
+	Lines   int     `json:"lines"`
+	Percent float64 `json:"percent"`
+}
+
+// LineChange is one revision of a single line, as reported by git log -L
+type LineChange struct {
+	Commit  Commit `json:"commit"`
+	Content string `json:"content"` // the line's content after this commit
+	Diff    string `json:"diff"`    // the hunk for this commit
+}
+
+// GetFileHistory lists the commits that touched a file, following renames
+func GetFileHistory(repoPath, filePath string, limit int) ([]Commit, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := checkTrackedFile(repoPath, filePath); err != nil {
+		return nil, err
+	}
+
+	args := []string{"log", "--follow", commitLogFormat, "--date=iso"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	args = append(args, "--", filePath)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file history: %v", err)
+	}
+
+	return parseCommitLog(string(output)), nil
+}
+
+// checkTrackedFile returns an error unless filePath is tracked by git in repoPath
+func checkTrackedFile(repoPath, filePath string) error {
+	if filePath == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	cmd := exec.Command("git", "ls-files", "--error-unmatch", "--", filePath)
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("file is not tracked by git: %s", filePath)
+	}
+	return nil
+}
+
+// BlameFile returns line-level authorship for a file. startLine and endLine are
+// 1-based and inclusive; zero values blame from the first or to the last line.
+func BlameFile(repoPath, filePath string, startLine, endLine int) ([]BlameLine, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := checkTrackedFile(repoPath, filePath); err != nil {
+		return nil, err
+	}
+
+	if startLine < 0 || endLine < 0 {
+		return nil, fmt.Errorf("line numbers must be positive")
+	}
+	if endLine > 0 && startLine > endLine {
+		return nil, fmt.Errorf("start_line (%d) is after end_line (%d)", startLine, endLine)
+	}
+
+	args := []string{"blame", "--line-porcelain"}
+	if startLine > 0 || endLine > 0 {
+		if startLine == 0 {
+			startLine = 1
+		}
+		lineRange := fmt.Sprintf("%d,", startLine)
+		if endLine > 0 {
+			lineRange += strconv.Itoa(endLine)
+		}
+		args = append(args, "-L", lineRange)
+	}
+	args = append(args, "--", filePath)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to blame file: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return parseBlamePorcelain(string(output)), nil
+}
+
+// parseBlamePorcelain parses `git blame --line-porcelain` output
+func parseBlamePorcelain(output string) []BlameLine {
+	var lines []BlameLine
+	var current BlameLine
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Content lines are prefixed with a tab and terminate each entry
+		if strings.HasPrefix(line, "\t") {
+			current.Content = line[1:]
+			lines = append(lines, current)
+			current = BlameLine{}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch {
+		case current.CommitHash == "" && len(key) == 40:
+			// Header: <hash> <orig-line> <final-line> [<group-size>]
+			current.CommitHash = key[:7]
+			if fields := strings.Fields(value); len(fields) >= 2 {
+				current.LineNumber, _ = strconv.Atoi(fields[1])
+			}
+		case key == "author":
+			current.Author = value
+		case key == "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Date = time.Unix(sec, 0).Format("2006-01-02")
+			}
+		}
+	}
+
+	return lines
+}
+
+// FileAuthors returns everyone who committed to a file, following renames,
+// ordered by commit count (most first)
+func FileAuthors(repoPath, filePath string) ([]AuthorContribution, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := checkTrackedFile(repoPath, filePath); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "log", "--follow", "--format=%aN", "--", filePath)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file authors: %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, author := range strings.Split(string(output), "\n") {
+		if author = strings.TrimSpace(author); author != "" {
+			counts[author]++
+		}
+	}
+
+	authors := make([]AuthorContribution, 0, len(counts))
+	for author, commits := range counts {
+		authors = append(authors, AuthorContribution{Author: author, Commits: commits})
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		return authors[i].Author < authors[j].Author
+	})
+
+	return authors, nil
+}
+
+// GetContributors returns the authors of the commits reachable from HEAD,
+// ordered by commit count (most first). limit <= 0 returns every contributor.
+// A repository without commits has no contributors.
+func GetContributors(repoPath string, limit int) ([]Contributor, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		return []Contributor{}, nil
+	}
+
+	cmd := exec.Command("git", "shortlog", "-sne", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributors: %v", err)
+	}
+
+	contributors := parseShortlog(string(output))
+	sort.SliceStable(contributors, func(i, j int) bool {
+		if contributors[i].CommitCount != contributors[j].CommitCount {
+			return contributors[i].CommitCount > contributors[j].CommitCount
+		}
+		return contributors[i].Name < contributors[j].Name
+	})
+	if limit > 0 && len(contributors) > limit {
+		contributors = contributors[:limit]
+	}
+
+	return contributors, nil
+}
+
+// parseShortlog parses `git shortlog -sne` lines of the form
+// "    12\tName <email>"
+func parseShortlog(output string) []Contributor {
+	contributors := []Contributor{}
+	for _, line := range strings.Split(output, "\n") {
+		count, author, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		commits, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil {
+			continue
+		}
+
+		contributor := Contributor{Name: strings.TrimSpace(author), CommitCount: commits}
+		if start := strings.LastIndex(author, " <"); start >= 0 && strings.HasSuffix(author, ">") {
+			contributor.Name = strings.TrimSpace(author[:start])
+			contributor.Email = author[start+2 : len(author)-1]
+		}
+		contributors = append(contributors, contributor)
+	}
+	return contributors
+}
+
+// maxConcurrentBlames bounds the git blame processes run at once by DirectoryOwnership
+const maxConcurrentBlames = 8
+
+// DirectoryOwnership blames every tracked text file under dir and returns each
+// author's share of the current lines, largest first. Binary files and files
+// over maxScanFileSize are skipped.
+func DirectoryOwnership(repoPath, dir string) ([]OwnershipShare, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+	fullDir := filepath.Join(repoPath, dir)
+	if rel, err := filepath.Rel(repoPath, fullDir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path is outside the repository: %s", dir)
+	}
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "ls-files", "--", dir)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if file == "" {
+			continue
+		}
+		fullPath := filepath.Join(repoPath, file)
+		info, err := os.Lstat(fullPath)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxScanFileSize {
+			continue
+		}
+		if head, err := readFileHead(fullPath, binaryCheckSize); err != nil || bytes.IndexByte(head, 0) >= 0 {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no tracked text files under %s", dir)
+	}
+
+	// Blame files in parallel; each worker returns per-author line counts
+	perFile := make([]map[string]int, len(files))
+	sem := make(chan struct{}, maxConcurrentBlames)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func(i int, file string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			perFile[i] = blameAuthorLines(repoPath, file)
+		}(i, file)
+	}
+	wg.Wait()
+
+	counts := make(map[string]int)
+	total := 0
+	for _, fileCounts := range perFile {
+		for author, lines := range fileCounts {
+			counts[author] += lines
+			total += lines
+		}
+	}
+
+	shares := make([]OwnershipShare, 0, len(counts))
+	for author, lines := range counts {
+		shares = append(shares, OwnershipShare{
+			Author:  author,
+			Lines:   lines,
+			Percent: float64(lines) * 100 / float64(total),
+		})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Lines != shares[j].Lines {
+			return shares[i].Lines > shares[j].Lines
+		}
+		return shares[i].Author < shares[j].Author
+	})
+
+	return shares, nil
+}
+
+// blameAuthorLines counts the current lines of a file attributed to each author.
+// Files that cannot be blamed count as empty.
+func blameAuthorLines(repoPath, filePath string) map[string]int {
+	cmd := exec.Command("git", "blame", "--line-porcelain", "--", filePath)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, line := range parseBlamePorcelain(string(output)) {
+		counts[line.Author]++
+	}
+	return counts
+}
+
+// FindLargeBlobsInHistory returns the topN largest blobs reachable from any ref,
+// including blobs for files that have since been deleted
+func FindLargeBlobsInHistory(repoPath string, topN int) ([]BlobInfo, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	cmd := exec.Command("git", "rev-list", "--objects", "--all")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %v", err)
+	}
+
+	// Objects listed with a path are blobs or trees; remember every path seen
+	paths := make(map[string][]string)
+	var objects strings.Builder
+	for _, line := range strings.Split(string(output), "\n") {
+		sha, path, found := strings.Cut(line, " ")
+		if !found || path == "" {
+			continue
+		}
+		if _, seen := paths[sha]; !seen {
+			objects.WriteString(sha + "\n")
+		}
+		paths[sha] = appendUnique(paths[sha], path)
+	}
+
+	cmd = exec.Command("git", "cat-file", "--batch-check=%(objecttype) %(objectname) %(objectsize)")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(objects.String())
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object sizes: %v", err)
+	}
+
+	var blobs []BlobInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "blob" {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		blobs = append(blobs, BlobInfo{SHA: fields[1], Size: size, Paths: paths[fields[1]]})
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		if blobs[i].Size != blobs[j].Size {
+			return blobs[i].Size > blobs[j].Size
+		}
+		return blobs[i].SHA < blobs[j].SHA
+	})
+	if topN > 0 && len(blobs) > topN {
+		blobs = blobs[:topN]
+	}
+
+	return blobs, nil
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// LineHistory returns the commits that changed a single line of a file,
+// newest first, using git log -L
+func LineHistory(repoPath, filePath string, line int, limit int) ([]LineChange, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if line < 1 {
+		return nil, fmt.Errorf("line must be at least 1")
+	}
+	if err := checkTrackedFile(repoPath, filePath); err != nil {
+		return nil, err
+	}
+
+	// Each commit header starts with a NUL so it cannot be confused with diff lines
+	args := []string{"log", fmt.Sprintf("-L%d,%d:%s", line, line, filePath), "--format=%x00%H%x00%an%x00%ad%x00%s", "--date=iso"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get line history: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return parseLineLog(string(output)), nil
+}
+
+// parseLineLog parses git log -L output produced with a NUL-prefixed header
+func parseLineLog(output string) []LineChange {
+	var changes []LineChange
+	var current *LineChange
+	var diff strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.Diff = strings.TrimSpace(diff.String())
+			changes = append(changes, *current)
+		}
+		diff.Reset()
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\x00") {
+			flush()
+			fields := strings.SplitN(line[1:], "\x00", 4)
+			if len(fields) < 4 {
+				current = nil
+				continue
+			}
+			current = &LineChange{Commit: Commit{Hash: fields[0], Author: fields[1], Date: fields[2], Message: fields[3]}}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		diff.WriteString(line + "\n")
+		// The added (or unchanged context) line is the content at this revision
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			current.Content = line[1:]
+		} else if strings.HasPrefix(line, " ") && current.Content == "" {
+			current.Content = line[1:]
+		}
+	}
+	flush()
+
+	return changes
+}
+
+// ActivitySummary describes the commits reachable from HEAD over a recent window
+type ActivitySummary struct {
+	Days     int               `json:"days"`
+	Commits  int               `json:"commits"`
+	Authors  int               `json:"authors"` // distinct authors, merged according to .mailmap
+	TopFiles []FileChangeCount `json:"top_files"`
+}
+
+// FileChangeCount is the number of commits that touched a file
+type FileChangeCount struct {
+	Path    string `json:"path"`
+	Commits int    `json:"commits"`
+}
+
+// GetActivitySummary counts the commits and authors of the last days days and
+// tallies the files they changed, keeping the topFiles most changed (most first;
+// topFiles <= 0 keeps all). Merge commits count but list no files.
+func GetActivitySummary(repoPath string, days, topFiles int) (*ActivitySummary, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("days must be positive, got %d", days)
+	}
+
+	summary := &ActivitySummary{Days: days, TopFiles: []FileChangeCount{}}
+	if err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		return summary, nil
+	}
+
+	since := time.Now().AddDate(0, 0, -days).Format(time.RFC3339)
+	// Each commit starts with a NUL-prefixed author line, followed by the paths it changed
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "log", "--since="+since, "--format=%x00%aN <%aE>", "--name-only", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %v", err)
+	}
+
+	authors := make(map[string]bool)
+	changes := make(map[string]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		if author, ok := strings.CutPrefix(line, "\x00"); ok {
+			summary.Commits++
+			authors[author] = true
+		} else if line != "" {
+			changes[line]++
+		}
+	}
+	summary.Authors = len(authors)
+
+	for path, commits := range changes {
+		summary.TopFiles = append(summary.TopFiles, FileChangeCount{Path: path, Commits: commits})
+	}
+	sort.Slice(summary.TopFiles, func(i, j int) bool {
+		if summary.TopFiles[i].Commits != summary.TopFiles[j].Commits {
+			return summary.TopFiles[i].Commits > summary.TopFiles[j].Commits
+		}
+		return summary.TopFiles[i].Path < summary.TopFiles[j].Path
+	})
+	if topFiles > 0 && len(summary.TopFiles) > topFiles {
+		summary.TopFiles = summary.TopFiles[:topFiles]
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGetFileHistory(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	// Rename test.txt and modify it after the rename
+	run("mv", "test.txt", "renamed.txt")
+	run("commit", "-m", "Rename test file")
+	if err := os.WriteFile(filepath.Join(repoPath, "renamed.txt"), []byte("after rename\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	run("commit", "-am", "Edit renamed file")
+
+	t.Run("follows renames", func(t *testing.T) {
+		commits, err := GetFileHistory(repoName, "renamed.txt", 20)
+		if err != nil {
+			t.Fatalf("GetFileHistory failed: %v", err)
+		}
+
+		var messages []string
+		for _, c := range commits {
+			messages = append(messages, c.Message)
+		}
+		expected := []string{"Edit renamed file", "Rename test file", "Second commit", "Initial commit"}
+		if strings.Join(messages, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected %v, got %v", expected, messages)
+		}
+	})
+
+	t.Run("respects limit", func(t *testing.T) {
+		commits, err := GetFileHistory(repoName, "renamed.txt", 2)
+		if err != nil {
+			t.Fatalf("GetFileHistory failed: %v", err)
+		}
+		if len(commits) != 2 {
+			t.Errorf("Expected 2 commits, got %d", len(commits))
+		}
+	})
+
+	t.Run("untracked file", func(t *testing.T) {
+		if err := os.WriteFile(filepath.Join(repoPath, "scratch.txt"), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		for _, path := range []string{"scratch.txt", "missing.txt"} {
+			_, err := GetFileHistory(repoName, path, 20)
+			if err == nil || !strings.Contains(err.Error(), "not tracked") {
+				t.Errorf("Expected 'not tracked' error for %s, got %v", path, err)
+			}
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleFileHistory(context.Background(), nil, FileHistoryParams{
+			Repository: repoName,
+			FilePath:   "renamed.txt",
+		})
+		if err != nil {
+			t.Fatalf("handleFileHistory returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("Unexpected error result: %v", result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "File: renamed.txt") || !strings.Contains(text, "Initial commit") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+
+		result, _, _ = handleFileHistory(context.Background(), nil, FileHistoryParams{Repository: repoName})
+		if !result.IsError {
+			t.Error("Expected error result when file_path is missing")
+		}
+	})
+}
+
+func TestBlameFile(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	filePath := filepath.Join(repoPath, "test.txt")
+	if err := os.WriteFile(filePath, []byte("line one\nline two\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	cmd := exec.Command("git", "commit", "-am", "Two lines")
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Git commit failed: %v", err)
+	}
+	// Leave an uncommitted third line in the working tree
+	if err := os.WriteFile(filePath, []byte("line one\nline two\nline three\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	lines, err := BlameFile(repoName, "test.txt", 0, 0)
+	if err != nil {
+		t.Fatalf("BlameFile failed: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 blamed lines, got %+v", lines)
+	}
+	if lines[0].Author != "Test User" || len(lines[0].CommitHash) != 7 || lines[0].Content != "line one" || lines[0].LineNumber != 1 {
+		t.Errorf("Unexpected first line: %+v", lines[0])
+	}
+	if lines[0].Date == "" {
+		t.Error("Expected blame date to be set")
+	}
+	if lines[2].Author != "Not Committed Yet" {
+		t.Errorf("Expected uncommitted line, got %+v", lines[2])
+	}
+
+	t.Run("line range", func(t *testing.T) {
+		lines, err := BlameFile(repoName, "test.txt", 2, 2)
+		if err != nil {
+			t.Fatalf("BlameFile failed: %v", err)
+		}
+		if len(lines) != 1 || lines[0].LineNumber != 2 || lines[0].Content != "line two" {
+			t.Errorf("Expected only line 2, got %+v", lines)
+		}
+
+		if _, err := BlameFile(repoName, "test.txt", 3, 1); err == nil {
+			t.Error("Expected error when start_line is after end_line")
+		}
+	})
+
+	t.Run("untracked file", func(t *testing.T) {
+		if _, err := BlameFile(repoName, "missing.txt", 0, 0); err == nil || !strings.Contains(err.Error(), "not tracked") {
+			t.Errorf("Expected 'not tracked' error, got %v", err)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleBlameFile(context.Background(), nil, BlameFileParams{
+			Repository: repoName,
+			FilePath:   "test.txt",
+			StartLine:  1,
+			EndLine:    1,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleBlameFile failed: %v %v", err, result.Content)
+		}
+		lines, err := BlameFile(repoName, "test.txt", 1, 1)
+		if err != nil || len(lines) != 1 {
+			t.Fatalf("BlameFile failed: %v %+v", err, lines)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		want := "\n" + lines[0].CommitHash + " (Test User " + lines[0].Date + ") line one\n"
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output: %s", want, text)
+		}
+	})
+}
+
+func TestFileAuthors(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	t.Run("single author", func(t *testing.T) {
+		authors, err := FileAuthors(repoName, "test.txt")
+		if err != nil {
+			t.Fatalf("FileAuthors failed: %v", err)
+		}
+		if len(authors) != 1 || authors[0].Author != "Test User" || authors[0].Commits != 2 {
+			t.Errorf("Expected Test User with 2 commits, got %+v", authors)
+		}
+	})
+
+	t.Run("sorted by commit count", func(t *testing.T) {
+		repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+		if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte("other content\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		cmd := exec.Command("git", "commit", "-am", "Edit by another author", "--author", "Other Dev <other@example.com>")
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Git commit failed: %v\n%s", err, output)
+		}
+
+		authors, err := FileAuthors(repoName, "test.txt")
+		if err != nil {
+			t.Fatalf("FileAuthors failed: %v", err)
+		}
+		if len(authors) != 2 || authors[0].Author != "Test User" || authors[1].Author != "Other Dev" || authors[1].Commits != 1 {
+			t.Errorf("Expected Test User then Other Dev, got %+v", authors)
+		}
+	})
+
+	t.Run("untracked file", func(t *testing.T) {
+		if _, err := FileAuthors(repoName, "missing.txt"); err == nil || !strings.Contains(err.Error(), "not tracked") {
+			t.Errorf("Expected 'not tracked' error, got %v", err)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleFileAuthors(context.Background(), nil, FileAuthorsParams{Repository: repoName, FilePath: "test.txt"})
+		if err != nil || result.IsError {
+			t.Fatalf("handleFileAuthors failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "(2 authors)") || !strings.Contains(text, "2  Test User") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
+
+func TestGetContributors(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	t.Run("single author", func(t *testing.T) {
+		contributors, err := GetContributors(repo.Path, 0)
+		if err != nil {
+			t.Fatalf("GetContributors failed: %v", err)
+		}
+		if len(contributors) != 1 || contributors[0].Name != "Test User" || contributors[0].Email != "test@example.com" || contributors[0].CommitCount != 3 {
+			t.Errorf("Expected Test User with 3 commits, got %+v", contributors)
+		}
+	})
+
+	t.Run("mailmap and sorting", func(t *testing.T) {
+		repo.WriteFile(".mailmap", "Test User <test@example.com> <tuser@old.example>\n")
+		repo.runGitCommand("add", ".mailmap")
+		repo.runGitCommand("commit", "-m", "Add mailmap")
+		repo.WriteFile("version.txt", "1.0.1")
+		repo.runGitCommand("commit", "-am", "Bump version", "--author", "T. User <tuser@old.example>")
+		repo.WriteFile("version.txt", "1.0.2")
+		repo.runGitCommand("commit", "-am", "Bump again", "--author", "Other Dev <other@example.com>")
+
+		contributors, err := GetContributors(repo.Path, 0)
+		if err != nil {
+			t.Fatalf("GetContributors failed: %v", err)
+		}
+		if len(contributors) != 2 || contributors[0].Name != "Test User" || contributors[0].CommitCount != 5 ||
+			contributors[1].Name != "Other Dev" || contributors[1].CommitCount != 1 {
+			t.Errorf("Expected Test User (5, merged via .mailmap) then Other Dev (1), got %+v", contributors)
+		}
+
+		if limited, _ := GetContributors(repo.Path, 1); len(limited) != 1 || limited[0].Name != "Test User" {
+			t.Errorf("Expected only the top contributor with limit 1, got %+v", limited)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetContributors(context.Background(), nil, GetContributorsParams{Repository: repo.Path})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetContributors failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Contributors (2)") || !strings.Contains(text, "5  Test User <test@example.com>") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
+
+func TestGetActivitySummary(t *testing.T) {
+	workspaceRepo := CreateTestRepositoryWithContent(t)
+	repo := &TestRepository{Path: filepath.Join(filepath.Dir(workspaceRepo.Path), "activity-repo"), T: t}
+	if err := os.MkdirAll(repo.Path, 0755); err != nil {
+		t.Fatalf("Failed to create repository: %v", err)
+	}
+	repo.runGitCommand("init")
+	repo.runGitCommand("config", "user.name", "Test User")
+	repo.runGitCommand("config", "user.email", "test@example.com")
+
+	commit := func(author string, daysAgo int, file, content string) {
+		t.Helper()
+		repo.WriteFile(file, content)
+		repo.runGitCommand("add", file)
+		cmd := exec.Command("git", "commit", "-m", "Update "+file, "--author", author)
+		cmd.Dir = repo.Path
+		date := time.Now().AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Failed to commit: %v\n%s", err, output)
+		}
+	}
+
+	// The first commit is two months old, outside the default window
+	commit("Old Dev <old@example.com>", 60, "app.go", "package app // v0\n")
+	commit("Test User <test@example.com>", 10, "app.go", "package app // v1\n")
+	commit("Other Dev <other@example.com>", 5, "notes.txt", "notes\n")
+	commit("Other Dev <other@example.com>", 5, "app.go", "package app // v2\n")
+	commit("Test User <test@example.com>", 1, "app.go", "package app // v3\n")
+	commit("Test User <test@example.com>", 0, "README.md", "# Activity\n")
+
+	summary, err := GetActivitySummary(repo.Path, 30, 2)
+	if err != nil {
+		t.Fatalf("GetActivitySummary failed: %v", err)
+	}
+	if summary.Commits != 5 || summary.Authors != 2 {
+		t.Errorf("Expected 5 commits by 2 authors, got %d by %d", summary.Commits, summary.Authors)
+	}
+	want := []FileChangeCount{{Path: "app.go", Commits: 3}, {Path: "README.md", Commits: 1}}
+	if !slices.Equal(summary.TopFiles, want) {
+		t.Errorf("Expected top files %+v, got %+v", want, summary.TopFiles)
+	}
+
+	summary, err = GetActivitySummary(repo.Path, 90, 0)
+	if err != nil {
+		t.Fatalf("GetActivitySummary failed: %v", err)
+	}
+	if summary.Commits != 6 || summary.Authors != 3 || len(summary.TopFiles) != 3 || summary.TopFiles[0].Commits != 4 {
+		t.Errorf("Expected the old commit within 90 days, got %+v", summary)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetActivitySummary(context.Background(), nil, GetActivitySummaryParams{Repository: repo.Path})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetActivitySummary failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		want := "Activity in the last 30 days:\n" + strings.Repeat("=", 50) + "\nCommits: 5\nAuthors: 2\n\nMost changed files:\n    3  app.go\n    1  README.md\n    1  notes.txt\n"
+		if text != want {
+			t.Errorf("Unexpected output:\n%s", text)
+		}
+
+		result, _, _ = handleGetActivitySummary(context.Background(), nil, GetActivitySummaryParams{Repository: repo.Path, Days: -1})
+		if !result.IsError {
+			t.Error("Expected an error for negative days")
+		}
+	})
+}
+
+func TestDirectoryOwnership(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	write := func(name, content string) {
+		fullPath := filepath.Join(repoPath, name)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	commit := func(message string, extra ...string) {
+		for _, args := range [][]string{{"add", "-A"}, append([]string{"commit", "-m", message}, extra...)} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoPath
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, output)
+			}
+		}
+	}
+
+	write("core/a.go", "package core\n\nfunc A() {}\n")
+	write("core/b.go", "package core\n\nfunc B() {}\n")
+	commit("Add core")
+	write("plugins/p.go", "package plugins\n\nfunc P() {}\nfunc Q() {}\n")
+	write("plugins/logo.png", "\x89PNG\x00\x00binary")
+	commit("Add plugins", "--author", "Other Dev <other@example.com>")
+	write("plugins/extra.go", "package plugins\n")
+	commit("Add extra plugin file")
+
+	t.Run("single author", func(t *testing.T) {
+		shares, err := DirectoryOwnership(repoName, "core")
+		if err != nil {
+			t.Fatalf("DirectoryOwnership failed: %v", err)
+		}
+		if len(shares) != 1 || shares[0].Author != "Test User" || shares[0].Lines != 6 {
+			t.Fatalf("Expected Test User owning 6 lines, got %+v", shares)
+		}
+		if shares[0].Percent < 99.9 {
+			t.Errorf("Expected ~100%% ownership, got %.1f%%", shares[0].Percent)
+		}
+	})
+
+	t.Run("shared directory", func(t *testing.T) {
+		shares, err := DirectoryOwnership(repoName, "plugins")
+		if err != nil {
+			t.Fatalf("DirectoryOwnership failed: %v", err)
+		}
+		// 4 lines by Other Dev, 1 by Test User; the binary logo is skipped
+		if len(shares) != 2 || shares[0].Author != "Other Dev" || shares[0].Lines != 4 || shares[1].Lines != 1 {
+			t.Fatalf("Expected Other Dev then Test User, got %+v", shares)
+		}
+		if shares[0].Percent != 80 || shares[1].Percent != 20 {
+			t.Errorf("Expected 80%%/20%%, got %.1f%%/%.1f%%", shares[0].Percent, shares[1].Percent)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := DirectoryOwnership(repoName, "missing"); err == nil {
+			t.Error("Expected error for a directory without tracked files")
+		}
+		if _, err := DirectoryOwnership(repoName, "../outside"); err == nil {
+			t.Error("Expected error for a directory outside the repository")
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleDirectoryOwnership(context.Background(), nil, DirectoryOwnershipParams{Repository: repoName, Directory: "plugins"})
+		if err != nil || result.IsError {
+			t.Fatalf("handleDirectoryOwnership failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "(2 authors)") || !strings.Contains(text, " 80.0%       4 lines  Other Dev") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
+
+func TestFindLargeBlobsInHistory(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	// Commit a large file, then delete it so it only lives in history
+	large := strings.Repeat("0123456789abcdef", 64*1024) // 1MB
+	if err := os.WriteFile(filepath.Join(repoPath, "assets.bin"), []byte(large), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	run("add", "assets.bin")
+	run("commit", "-m", "Add large asset")
+	run("rm", "-q", "assets.bin")
+	run("commit", "-m", "Remove large asset")
+
+	blobs, err := FindLargeBlobsInHistory(repoName, 5)
+	if err != nil {
+		t.Fatalf("FindLargeBlobsInHistory failed: %v", err)
+	}
+	if len(blobs) == 0 {
+		t.Fatal("Expected at least one blob")
+	}
+	if blobs[0].Size != int64(len(large)) || len(blobs[0].Paths) != 1 || blobs[0].Paths[0] != "assets.bin" {
+		t.Errorf("Expected deleted assets.bin to be the largest blob, got %+v", blobs[0])
+	}
+	for i := 1; i < len(blobs); i++ {
+		if blobs[i].Size > blobs[i-1].Size {
+			t.Errorf("Blobs not sorted by size: %+v", blobs)
+		}
+	}
+
+	blobs, err = FindLargeBlobsInHistory(repoName, 1)
+	if err != nil {
+		t.Fatalf("FindLargeBlobsInHistory failed: %v", err)
+	}
+	if len(blobs) != 1 {
+		t.Errorf("Expected topN to limit results to 1, got %d", len(blobs))
+	}
+
+	result, _, err := handleHistoryBloat(context.Background(), nil, HistoryBloatParams{Repository: repoName})
+	if err != nil || result.IsError {
+		t.Fatalf("handleHistoryBloat failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "1.0 MB") || !strings.Contains(text, "assets.bin") {
+		t.Errorf("Unexpected output: %s", text)
+	}
+}
+
+func TestLineHistory(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// test.txt line 1 reads "initial content" and then "updated content"
+	changes, err := LineHistory(repoName, "test.txt", 1, 20)
+	if err != nil {
+		t.Fatalf("LineHistory failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 revisions, got %+v", changes)
+	}
+	if changes[0].Commit.Message != "Second commit" || changes[0].Content != "updated content" {
+		t.Errorf("Unexpected newest revision: %+v", changes[0])
+	}
+	if changes[1].Commit.Message != "Initial commit" || changes[1].Content != "initial content" {
+		t.Errorf("Unexpected oldest revision: %+v", changes[1])
+	}
+	if changes[0].Commit.Author != "Test User" || !strings.Contains(changes[0].Diff, "-initial content") {
+		t.Errorf("Expected author and hunk on revision, got %+v", changes[0])
+	}
+
+	changes, err = LineHistory(repoName, "test.txt", 1, 1)
+	if err != nil {
+		t.Fatalf("LineHistory failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("Expected limit to return 1 revision, got %d", len(changes))
+	}
+
+	if _, err := LineHistory(repoName, "test.txt", 50, 20); err == nil {
+		t.Error("Expected error for line past end of file")
+	}
+	if _, err := LineHistory(repoName, "missing.txt", 1, 20); err == nil {
+		t.Error("Expected error for untracked file")
+	}
+
+	result, _, err := handleLineHistory(context.Background(), nil, LineHistoryParams{Repository: repoName, FilePath: "test.txt", Line: 1})
+	if err != nil || result.IsError {
+		t.Fatalf("handleLineHistory failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "History of test.txt:1 (2 commits)") || !strings.Contains(text, "    initial content") {
+		t.Errorf("Unexpected output: %s", text)
+	}
+}
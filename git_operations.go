@@ -2,14 +2,25 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // RepositoryInfo contains basic repository information
@@ -17,9 +28,29 @@ type RepositoryInfo struct {
 	Path          string    `json:"path"`
 	LastUpdate    time.Time `json:"last_update"`
 	CurrentBranch string    `json:"current_branch"`
+	DefaultBranch string    `json:"default_branch,omitempty"` // origin/HEAD, or a local main/master
 	License       string    `json:"license,omitempty"`
+	LicenseType   string    `json:"license_type,omitempty"` // SPDX identifier such as "MIT", or "Unknown"
 	ReadmeContent string    `json:"readme_content,omitempty"`
 	RemoteURL     string    `json:"remote_url,omitempty"`
+
+	SubmoduleCount int `json:"submodule_count,omitempty"`
+}
+
+// Remote is one configured git remote
+type Remote struct {
+	Name     string `json:"name"`
+	FetchURL string `json:"fetch_url"`
+	PushURL  string `json:"push_url"`
+}
+
+// Submodule is one submodule declared in .gitmodules
+type Submodule struct {
+	Path        string `json:"path"`
+	URL         string `json:"url"`
+	Commit      string `json:"commit,omitempty"` // checked out commit, or the recorded one if not initialized
+	Initialized bool   `json:"initialized"`
+	Modified    bool   `json:"modified,omitempty"` // checked out commit differs from the recorded one
 }
 
 // Branch represents a git branch
@@ -27,6 +58,12 @@ type Branch struct {
 	Name       string `json:"name"`
 	IsCurrent  bool   `json:"is_current"`
 	LastCommit string `json:"last_commit,omitempty"`
+
+	// Commits on this branch not on HEAD (Ahead) and on HEAD not on this
+	// branch (Behind). Only set when HasTracking is true.
+	Ahead       int  `json:"ahead,omitempty"`
+	Behind      int  `json:"behind,omitempty"`
+	HasTracking bool `json:"has_tracking,omitempty"`
 }
 
 // Commit represents a git commit
@@ -39,16 +76,25 @@ type Commit struct {
 
 // SearchResult represents a file search result
 type SearchResult struct {
-	Path      string      `json:"path"`
-	MatchType string      `json:"match_type,omitempty"` // "content" or "filename"
-	Matches   []MatchLine `json:"matches,omitempty"`    // detailed match information
+	Path           string      `json:"path"`
+	MatchType      string      `json:"match_type,omitempty"`      // "content" or "filename"
+	Matches        []MatchLine `json:"matches,omitempty"`         // detailed match information
+	OmittedMatches int         `json:"omitted_matches,omitempty"` // matches dropped by the per-file cap
 }
 
 // MatchLine represents a single match within a file
 type MatchLine struct {
+	Path       string   `json:"path,omitempty"`    // file path (set when matches span several files)
 	LineNumber int      `json:"line_number"`       // line number (0 for filename matches)
 	Content    string   `json:"content"`           // the matching line content
 	Context    []string `json:"context,omitempty"` // surrounding context lines
+	// ContextStart is the line number of Context[0]. Context covers consecutive
+	// lines from there, skipping the matching line itself.
+	ContextStart int `json:"context_start,omitempty"`
+	// Function is the line declaring the function around the match and
+	// FunctionLine its line number (only with function context, see git grep -p)
+	Function     string `json:"function,omitempty"`
+	FunctionLine int    `json:"function_line,omitempty"`
 }
 
 // FileInfo represents file information
@@ -67,7 +113,18 @@ type RepositoryStatus struct {
 	StatusOutput  string `json:"status_output,omitempty"`
 }
 
-// GetRepositoryInfo retrieves basic repository information
+// WorkingTreeStatus lists the uncommitted changes in a repository. A path can
+// be both staged and modified when it was edited again after git add.
+type WorkingTreeStatus struct {
+	Clean     bool     `json:"clean"`
+	Staged    []string `json:"staged"`
+	Modified  []string `json:"modified"`
+	Untracked []string `json:"untracked"`
+}
+
+// GetRepositoryInfo retrieves basic repository information. Results are cached
+// per repository for repoInfoCacheTTL as long as HEAD does not move; see
+// InvalidateRepositoryInfo.
 func GetRepositoryInfo(repoPath string) (*RepositoryInfo, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
@@ -80,6 +137,40 @@ func GetRepositoryInfo(repoPath string) (*RepositoryInfo, error) {
 		return nil, fmt.Errorf("not a git repository: %s", repoPath)
 	}
 
+	head := headState(repoPath)
+	if info, ok := globalRepoInfoCache.get(repoPath, head); ok {
+		return info, nil
+	}
+
+	info := collectRepositoryInfo(repoPath)
+	// A repository without commits has no HEAD to validate the entry against
+	if head != "" {
+		globalRepoInfoCache.put(repoPath, head, info)
+	}
+
+	return info, nil
+}
+
+// GetRepositoryInfoWithReadmePreview is GetRepositoryInfo with the README cut
+// to its first readmeLines lines, followed by a note giving how many were left
+// out. The cached info keeps the full README.
+func GetRepositoryInfoWithReadmePreview(repoPath string, readmeLines int) (*RepositoryInfo, error) {
+	info, err := GetRepositoryInfo(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := *info
+	lines := strings.Split(strings.TrimRight(info.ReadmeContent, "\n"), "\n")
+	if readmeLines > 0 && len(lines) > readmeLines {
+		preview.ReadmeContent = strings.Join(lines[:readmeLines], "\n") +
+			fmt.Sprintf("\n(truncated, %d more lines)\n", len(lines)-readmeLines)
+	}
+	return &preview, nil
+}
+
+// collectRepositoryInfo runs the git commands and file reads behind GetRepositoryInfo
+func collectRepositoryInfo(repoPath string) *RepositoryInfo {
 	info := &RepositoryInfo{Path: repoPath}
 
 	// Get last update
@@ -92,14 +183,23 @@ func GetRepositoryInfo(repoPath string) (*RepositoryInfo, error) {
 		info.CurrentBranch = branch
 	}
 
+	if branch, err := getDefaultBranch(repoPath); err == nil {
+		info.DefaultBranch = branch
+	}
+
 	// Get remote URL
 	if remoteURL, err := getRemoteURL(repoPath); err == nil {
 		info.RemoteURL = remoteURL
 	}
 
+	if submodules, err := readGitmodules(repoPath); err == nil {
+		info.SubmoduleCount = len(submodules)
+	}
+
 	// Try to find license file
 	if license, err := findLicenseFile(repoPath); err == nil {
 		info.License = license
+		info.LicenseType = detectLicenseType(repoPath, license)
 	}
 
 	// Try to find and read README
@@ -107,7 +207,7 @@ func GetRepositoryInfo(repoPath string) (*RepositoryInfo, error) {
 		info.ReadmeContent = readme
 	}
 
-	return info, nil
+	return info
 }
 
 // GetRepositoryStatus returns the current status of a repository
@@ -145,6 +245,59 @@ func GetRepositoryStatus(repoPath string) (*RepositoryStatus, error) {
 	return status, nil
 }
 
+// GetStatus reports the staged, modified and untracked paths in the working
+// tree (git status --porcelain=v1). Nothing is stashed or otherwise changed.
+func GetStatus(repoPath string) (*WorkingTreeStatus, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	// --untracked-files=all lists files inside new directories instead of
+	// collapsing them to the directory
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "status", "--porcelain=v1", "--untracked-files=all")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %v", err)
+	}
+
+	return parsePorcelainStatus(string(output)), nil
+}
+
+// parsePorcelainStatus parses "XY path" lines, where X is the index status and
+// Y the working tree status. Renames are reported under their new path.
+func parsePorcelainStatus(output string) *WorkingTreeStatus {
+	status := &WorkingTreeStatus{Staged: []string{}, Modified: []string{}, Untracked: []string{}}
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		x, y, path := line[0], line[1], line[3:]
+		if _, newPath, ok := strings.Cut(path, " -> "); ok {
+			path = newPath
+		}
+
+		if x == '?' {
+			status.Untracked = append(status.Untracked, path)
+			continue
+		}
+		if x != ' ' && x != '!' {
+			status.Staged = append(status.Staged, path)
+		}
+		if y != ' ' && y != '!' {
+			status.Modified = append(status.Modified, path)
+		}
+	}
+	status.Clean = len(status.Staged) == 0 && len(status.Modified) == 0 && len(status.Untracked) == 0
+	return status
+}
+
 // PullRepository executes git pull on the specified repository
 func PullRepository(repoPath string) (string, error) {
 	// Validate workspace path
@@ -168,8 +321,140 @@ func PullRepository(repoPath string) (string, error) {
 	return string(output), nil
 }
 
+// FetchRepository executes git fetch --all --prune on the specified repository.
+// Unlike PullRepository it only updates remote-tracking refs and never touches
+// the working tree or local branches.
+func FetchRepository(repoPath string) (string, error) {
+	// Validate workspace path
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return "", err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return "", fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	cmd := exec.Command("git", "fetch", "--all", "--prune")
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(output), fmt.Errorf("git fetch failed: %v", err)
+	}
+
+	return string(output), nil
+}
+
+// PullPreview describes what pulling the current branch would bring in
+type PullPreview struct {
+	Branch   string   `json:"branch"`
+	Upstream string   `json:"upstream"`
+	Commits  []Commit `json:"commits"`
+	Diff     string   `json:"diff"`
+	UpToDate bool     `json:"up_to_date"`
+}
+
+// PreviewPull fetches and reports the commits on the current branch's upstream
+// that are not in HEAD, plus the diff they introduce, without merging anything
+func PreviewPull(repoPath string) (*PullPreview, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	preview := &PullPreview{}
+	preview.Branch, _ = getCurrentBranch(repoPath)
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("no upstream configured for the current branch")
+	}
+	preview.Upstream = strings.TrimSpace(string(output))
+
+	if output, err := FetchRepository(repoPath); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(output))
+	}
+
+	cmd = exec.Command("git", "log", commitLogFormat, "--date=iso", "HEAD..@{u}")
+	cmd.Dir = repoPath
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incoming commits: %v", err)
+	}
+	preview.Commits = parseCommitLog(string(output))
+	if len(preview.Commits) == 0 {
+		preview.UpToDate = true
+		return preview, nil
+	}
+
+	// Diff from the merge base so local commits not yet pushed do not show up
+	// as reverted changes
+	cmd = exec.Command("git", "diff", "HEAD...@{u}")
+	cmd.Dir = repoPath
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff incoming changes: %v", err)
+	}
+	preview.Diff = string(output)
+
+	return preview, nil
+}
+
+// CommitFilter narrows the commits returned by ListCommitsWithFilter
+type CommitFilter struct {
+	Author string `json:"author,omitempty"` // passed to git log --author
+	Since  string `json:"since,omitempty"`  // passed to git log --since
+	Until  string `json:"until,omitempty"`  // passed to git log --until
+	Before string `json:"before,omitempty"` // only the history before this commit (git log <before>^@), for paging
+
+	Grep     []string `json:"grep,omitempty"`      // commit message patterns (git log --grep, case-insensitive)
+	AllMatch bool     `json:"all_match,omitempty"` // require every Grep pattern to match (git log --all-match)
+}
+
+// describe returns a short summary of the active filters ("" if none)
+func (f CommitFilter) describe() string {
+	var parts []string
+	if f.Author != "" {
+		parts = append(parts, fmt.Sprintf("author=%s", f.Author))
+	}
+	if f.Since != "" {
+		parts = append(parts, fmt.Sprintf("since=%s", f.Since))
+	}
+	if f.Until != "" {
+		parts = append(parts, fmt.Sprintf("until=%s", f.Until))
+	}
+	if f.Before != "" {
+		parts = append(parts, fmt.Sprintf("before=%s", f.Before))
+	}
+	if len(f.Grep) > 0 {
+		var quoted []string
+		for _, pattern := range f.Grep {
+			quoted = append(quoted, fmt.Sprintf("%q", pattern))
+		}
+		joiner := " OR "
+		if f.AllMatch {
+			joiner = " AND "
+		}
+		parts = append(parts, fmt.Sprintf("message=%s", strings.Join(quoted, joiner)))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // ListCommits lists commits in the repository
 func ListCommits(repoPath string, limit int) ([]Commit, error) {
+	return ListCommitsWithFilter(repoPath, limit, CommitFilter{})
+}
+
+// ListCommitsWithFilter lists commits in the repository, optionally filtered by author and date range
+func ListCommitsWithFilter(repoPath string, limit int, filter CommitFilter) ([]Commit, error) {
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
 		return nil, err
@@ -180,10 +465,45 @@ func ListCommits(repoPath string, limit int) ([]Commit, error) {
 		return nil, fmt.Errorf("not a git repository: %s", repoPath)
 	}
 
-	args := []string{"log", "--pretty=format:%H|%an|%ad|%s", "--date=iso"}
+	if err := validateGitDate(filter.Since); err != nil {
+		return nil, fmt.Errorf("invalid since value: %v", err)
+	}
+	if err := validateGitDate(filter.Until); err != nil {
+		return nil, fmt.Errorf("invalid until value: %v", err)
+	}
+	if filter.Before != "" {
+		if err := validateRef(repoPath, filter.Before); err != nil {
+			return nil, fmt.Errorf("invalid before value: %v", err)
+		}
+	}
+
+	args := []string{"log", commitLogFormat, "--date=iso"}
 	if limit > 0 {
 		args = append(args, fmt.Sprintf("--max-count=%d", limit))
 	}
+	if filter.Author != "" {
+		args = append(args, "--author="+filter.Author)
+	}
+	if filter.Since != "" {
+		args = append(args, "--since="+filter.Since)
+	}
+	if filter.Until != "" {
+		args = append(args, "--until="+filter.Until)
+	}
+	for _, pattern := range filter.Grep {
+		args = append(args, "--grep="+pattern)
+	}
+	if len(filter.Grep) > 0 {
+		args = append(args, "-i")
+		if filter.AllMatch {
+			args = append(args, "--all-match")
+		}
+	}
+	if filter.Before != "" {
+		// ^@ names all parents of the cursor; for a root commit it names
+		// nothing, which yields an empty page rather than an error
+		args = append(args, filter.Before+"^@", "--")
+	}
 
 	cmd := exec.Command("git", args...)
 	cmd.Dir = repoPath
@@ -192,8 +512,24 @@ func ListCommits(repoPath string, limit int) ([]Commit, error) {
 		return nil, fmt.Errorf("failed to list commits: %v", err)
 	}
 
+	return parseCommitLog(string(output)), nil
+}
+
+// SearchCommits finds commits whose message contains the query (case-insensitive)
+func SearchCommits(repoPath, query string, limit int) ([]Commit, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+	return ListCommitsWithFilter(repoPath, limit, CommitFilter{Grep: []string{query}})
+}
+
+// commitLogFormat is the git log format understood by parseCommitLog
+const commitLogFormat = "--pretty=format:%H|%an|%ad|%s"
+
+// parseCommitLog parses git log output produced with commitLogFormat
+func parseCommitLog(output string) []Commit {
 	var commits []Commit
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner := bufio.NewScanner(strings.NewReader(output))
 	for scanner.Scan() {
 		line := scanner.Text()
 		parts := strings.SplitN(line, "|", 4)
@@ -207,12 +543,342 @@ func ListCommits(repoPath string, limit int) ([]Commit, error) {
 			commits = append(commits, commit)
 		}
 	}
+	return commits
+}
+
+// relativeDatePattern matches relative dates such as "2 weeks ago" or "3 days"
+var relativeDatePattern = regexp.MustCompile(`^((\d+|an?)\s+(second|minute|hour|day|week|month|year)s?\s*)+(ago)?$`)
+
+// absoluteDateLayouts are the absolute date formats accepted by validateGitDate
+var absoluteDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	time.RFC3339,
+}
+
+// validateGitDate checks that a --since/--until value is a date expression git understands.
+// Empty values are accepted. Git itself silently ignores dates it cannot parse, so we
+// restrict input to absolute dates, relative forms like "2 weeks ago", and a few keywords.
+func validateGitDate(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	switch strings.ToLower(value) {
+	case "now", "today", "yesterday", "midnight", "noon":
+		return nil
+	}
+
+	if relativeDatePattern.MatchString(strings.ToLower(value)) {
+		return nil
+	}
+
+	for _, layout := range absoluteDateLayouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return nil
+		}
+	}
 
-	return commits, nil
+	return fmt.Errorf("'%s' is not a recognized date (use YYYY-MM-DD, an ISO timestamp, or a relative form like \"2 weeks ago\")", value)
 }
 
-// GetCommitDiff gets the diff for a specific commit
-func GetCommitDiff(repoPath, commitHash string) (string, error) {
+// FileDiffStat is the number of lines a commit added and removed in one file.
+// Binary files have no line counts.
+type FileDiffStat struct {
+	Path       string `json:"path"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary,omitempty"`
+}
+
+// DiffStat summarizes a commit's changes like `git show --stat`
+type DiffStat struct {
+	FilesChanged int            `json:"files_changed"`
+	Insertions   int            `json:"insertions"`
+	Deletions    int            `json:"deletions"`
+	Files        []FileDiffStat `json:"files"`
+}
+
+// summary formats the totals as git does, e.g.
+// "3 files changed, 42 insertions(+), 10 deletions(-)"
+func (s DiffStat) summary() string {
+	plural := func(n int, word string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, word)
+		}
+		return fmt.Sprintf("%d %ss", n, word)
+	}
+
+	parts := []string{plural(s.FilesChanged, "file") + " changed"}
+	if s.Insertions > 0 || s.Deletions == 0 {
+		parts = append(parts, plural(s.Insertions, "insertion")+"(+)")
+	}
+	if s.Deletions > 0 || s.Insertions == 0 {
+		parts = append(parts, plural(s.Deletions, "deletion")+"(-)")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CommitDiff is the output of git show for a commit along with its diff statistics
+type CommitDiff struct {
+	Hash string   `json:"hash"`
+	Diff string   `json:"diff"`
+	Stat DiffStat `json:"stat"`
+}
+
+// GetCommitDiff gets the diff for a specific commit together with its
+// per-file insertion and deletion counts
+func GetCommitDiff(repoPath, commitHash string) (*CommitDiff, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := validateCommitHash(commitHash); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "show", "--end-of-options", commitHash)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git show failed for commit '%s': %v: %s", commitHash, err, strings.TrimSpace(string(output)))
+	}
+
+	stat, err := commitDiffStat(repoPath, commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CommitDiff{Hash: commitHash, Diff: string(output), Stat: stat}, nil
+}
+
+// commitDiffStat counts the lines a commit added and removed per file using
+// git show --numstat
+func commitDiffStat(repoPath, commitHash string) (DiffStat, error) {
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "show", "--numstat", "--format=", "--end-of-options", commitHash)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return DiffStat{}, fmt.Errorf("git show --numstat failed for commit '%s': %v", commitHash, err)
+	}
+
+	return parseNumstat(string(output)), nil
+}
+
+// parseNumstat parses "<added>\t<deleted>\t<path>" lines; binary files report
+// "-" for both counts
+func parseNumstat(output string) DiffStat {
+	stat := DiffStat{Files: []FileDiffStat{}}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		file := FileDiffStat{Path: fields[2]}
+		if fields[0] == "-" && fields[1] == "-" {
+			file.Binary = true
+		} else {
+			file.Insertions, _ = strconv.Atoi(fields[0])
+			file.Deletions, _ = strconv.Atoi(fields[1])
+		}
+
+		stat.Files = append(stat.Files, file)
+		stat.FilesChanged++
+		stat.Insertions += file.Insertions
+		stat.Deletions += file.Deletions
+	}
+	return stat
+}
+
+// validateCommitHash is the basic check for a commit hash argument. A hash
+// starting with "-" is refused so git never reads it as an option.
+func validateCommitHash(commitHash string) error {
+	if len(commitHash) < 4 || len(commitHash) > 40 || strings.HasPrefix(commitHash, "-") {
+		return fmt.Errorf("invalid commit hash format")
+	}
+	return nil
+}
+
+// GetCommitFilePatches splits a commit's diff into one patch per changed file,
+// keyed by the file's path after the commit
+func GetCommitFilePatches(repoPath, commitHash string) (map[string]string, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := validateCommitHash(commitHash); err != nil {
+		return nil, err
+	}
+
+	diff, err := commitDiffBody(repoPath, commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitFilePatches(diff), nil
+}
+
+// GetCommitDiffHunks parses a commit's diff into hunks and returns the window
+// of limit hunks starting at offset, along with the total number of hunks
+func GetCommitDiffHunks(repoPath, commitHash string, offset, limit int) ([]DiffHunk, int, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, 0, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := validateCommitHash(commitHash); err != nil {
+		return nil, 0, err
+	}
+
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("hunk_offset must not be negative")
+	}
+
+	diff, err := commitDiffBody(repoPath, commitHash)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hunks := splitDiffHunks(diff)
+	total := len(hunks)
+	if offset >= total {
+		return []DiffHunk{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	return hunks[offset:end], total, nil
+}
+
+// commitDiffBody returns the diff of a commit without its header
+func commitDiffBody(repoPath, commitHash string) (string, error) {
+	// Empty --format drops the commit header so only the diff remains
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "show", "--format=", "--end-of-options", commitHash)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git show failed for commit '%s': %v", commitHash, err)
+	}
+
+	return string(output), nil
+}
+
+// splitFilePatches splits unified diff output at each "diff --git" header
+func splitFilePatches(diff string) map[string]string {
+	patches := make(map[string]string)
+
+	var path string
+	var patch strings.Builder
+	flush := func() {
+		if path != "" {
+			patches[path] = patch.String()
+		}
+		patch.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		if strings.HasPrefix(trimmed, "diff --git ") {
+			flush()
+			// Header is "diff --git a/<old> b/<new>"; use the new path
+			path = trimmed
+			if idx := strings.LastIndex(trimmed, " b/"); idx >= 0 {
+				path = trimmed[idx+3:]
+			}
+		} else if strings.HasPrefix(trimmed, "rename to ") {
+			path = strings.TrimPrefix(trimmed, "rename to ")
+		}
+		if path != "" {
+			patch.WriteString(line)
+		}
+	}
+	flush()
+
+	return patches
+}
+
+// DiffHunk is a single "@@" hunk of a unified diff
+type DiffHunk struct {
+	File string // Path of the file the hunk belongs to
+	Body string // Hunk text starting with its "@@" header line
+}
+
+// splitDiffHunks splits unified diff output into its hunks, in diff order
+func splitDiffHunks(diff string) []DiffHunk {
+	var hunks []DiffHunk
+
+	var path string
+	var body strings.Builder
+	inHunk := false
+	flush := func() {
+		if inHunk {
+			hunks = append(hunks, DiffHunk{File: path, Body: body.String()})
+		}
+		body.Reset()
+		inHunk = false
+	}
+
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		trimmed := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(trimmed, "diff --git "):
+			flush()
+			// Header is "diff --git a/<old> b/<new>"; use the new path
+			path = trimmed
+			if idx := strings.LastIndex(trimmed, " b/"); idx >= 0 {
+				path = trimmed[idx+3:]
+			}
+		case !inHunk && strings.HasPrefix(trimmed, "rename to "):
+			path = strings.TrimPrefix(trimmed, "rename to ")
+		case strings.HasPrefix(trimmed, "@@ "):
+			flush()
+			inHunk = true
+			body.WriteString(line)
+		case inHunk:
+			body.WriteString(line)
+		}
+	}
+	flush()
+
+	return hunks
+}
+
+// DiffRefs returns the patch of changes on head since it diverged from base
+// (git diff base...head), optionally limited to pathFilter
+func DiffRefs(repoPath, base, head string, pathFilter []string) (string, error) {
+	return diffRefs(repoPath, base, head, pathFilter, false)
+}
+
+// DiffRefsStat is like DiffRefs but returns the compact `git diff --stat` summary
+func DiffRefsStat(repoPath, base, head string, pathFilter []string) (string, error) {
+	return diffRefs(repoPath, base, head, pathFilter, true)
+}
+
+func diffRefs(repoPath, base, head string, pathFilter []string, statOnly bool) (string, error) {
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
 		return "", err
@@ -223,23 +889,193 @@ func GetCommitDiff(repoPath, commitHash string) (string, error) {
 		return "", fmt.Errorf("not a git repository: %s", repoPath)
 	}
 
-	// Basic validation for commit hash
-	if len(commitHash) < 4 || len(commitHash) > 40 {
-		return "", fmt.Errorf("invalid commit hash format")
+	for _, ref := range []string{base, head} {
+		if err := validateRef(repoPath, ref); err != nil {
+			return "", err
+		}
+	}
+
+	args := []string{"diff"}
+	if statOnly {
+		args = append(args, "--stat")
 	}
+	args = append(args, base+"..."+head, "--")
+	args = append(args, pathFilter...)
 
-	cmd := exec.Command("git", "show", commitHash)
+	cmd := exec.Command("git", args...)
 	cmd.Dir = repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return string(output), fmt.Errorf("git show failed for commit '%s': %v", commitHash, err)
+		return "", fmt.Errorf("git diff failed for %s...%s: %v", base, head, err)
 	}
 
 	return string(output), nil
 }
 
+// BranchComparison lists the commits each of two refs has that the other lacks
+type BranchComparison struct {
+	A         string   `json:"a"`
+	B         string   `json:"b"`
+	MergeBase string   `json:"merge_base"` // empty when the histories are unrelated
+	Ahead     []Commit `json:"ahead"`      // on A but not on B (git log B..A)
+	Behind    []Commit `json:"behind"`     // on B but not on A (git log A..B)
+}
+
+// CompareBranches reports the commits unique to each of a and b and their
+// merge base, without changing the working tree
+func CompareBranches(repoPath, a, b string) (*BranchComparison, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	for _, ref := range []string{a, b} {
+		if err := validateRef(repoPath, ref); err != nil {
+			return nil, err
+		}
+	}
+
+	comparison := &BranchComparison{A: a, B: b}
+
+	// merge-base exits 1 when the histories share no commit
+	cmd := exec.Command("git", "merge-base", a, b)
+	cmd.Dir = repoPath
+	if output, err := cmd.Output(); err == nil {
+		comparison.MergeBase = strings.TrimSpace(string(output))
+	}
+
+	for _, side := range []struct {
+		commits   *[]Commit
+		rangeSpec string
+	}{
+		{&comparison.Ahead, b + ".." + a},
+		{&comparison.Behind, a + ".." + b},
+	} {
+		cmd := exec.Command("git", "log", commitLogFormat, "--date=iso", side.rangeSpec, "--")
+		cmd.Dir = repoPath
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits for %s: %v", side.rangeSpec, err)
+		}
+		*side.commits = nonNil(parseCommitLog(string(output)))
+	}
+
+	return comparison, nil
+}
+
+// DiffWorkingFileAgainst returns the diff of a tracked file's working tree
+// version against ref (git diff <ref> -- <path>). An empty result means the
+// file is unchanged since ref.
+func DiffWorkingFileAgainst(repoPath, filePath, ref string) (string, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return "", err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return "", fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := validateRef(repoPath, ref); err != nil {
+		return "", err
+	}
+	if err := checkTrackedFile(repoPath, filePath); err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("git", "diff", ref, "--", filePath)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git diff failed for %s against %s: %v", filePath, ref, err)
+	}
+
+	return string(output), nil
+}
+
+// ChangedFile is one entry of `git diff --name-status`. Status is the change
+// letter (M, A, D, R, C or T); OldPath is set for renames and copies.
+type ChangedFile struct {
+	Status  string `json:"status"`
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"`
+}
+
+// FilesDifferingFromBranch lists the files whose working tree version differs
+// from branch (git diff --name-status <branch>), without switching branches.
+// Statuses read from the working tree's side: A means the file exists only in
+// the working tree, D only on branch.
+func FilesDifferingFromBranch(repoPath, branch string) ([]ChangedFile, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := validateRef(repoPath, branch); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "diff", "--name-status", branch, "--")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff failed against %s: %v", branch, err)
+	}
+
+	var files []ChangedFile
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		// Renames and copies carry a similarity score (R100) and two paths
+		file := ChangedFile{Status: fields[0][:1], Path: fields[len(fields)-1]}
+		if len(fields) == 3 {
+			file.OldPath = fields[1]
+		}
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// validateRef checks that ref names an existing commit. Refs starting with "-"
+// are rejected so they cannot be interpreted as git options.
+func validateRef(repoPath, ref string) error {
+	if ref == "" {
+		return fmt.Errorf("ref cannot be empty")
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid ref: %s", ref)
+	}
+
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = repoPath
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("unknown ref: %s (expected a branch, tag or commit)", ref)
+	}
+	return nil
+}
+
 // ListBranches lists all branches in the repository
 func ListBranches(repoPath string) ([]Branch, error) {
+	return ListBranchesWithTracking(repoPath, false)
+}
+
+// ListBranchesWithTracking lists all branches and, when includeTracking is set,
+// how far each local branch is ahead of or behind the current branch
+func ListBranchesWithTracking(repoPath string, includeTracking bool) ([]Branch, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
@@ -280,14 +1116,48 @@ func ListBranches(repoPath string) ([]Branch, error) {
 			continue
 		}
 
+		// Remote branches are skipped to keep the number of git calls down
+		if includeTracking && !branch.IsCurrent && !strings.HasPrefix(branch.Name, "remotes/") {
+			if ahead, behind, err := countAheadBehind(repoPath, branch.Name); err == nil {
+				branch.Ahead = ahead
+				branch.Behind = behind
+				branch.HasTracking = true
+			}
+		}
+
 		branches = append(branches, branch)
 	}
 
 	return branches, nil
 }
 
-// SwitchBranch switches to the specified branch
-func SwitchBranch(repoPath, branchName string) (string, error) {
+// countAheadBehind compares branch with HEAD using git rev-list --left-right
+func countAheadBehind(repoPath, branch string) (int, int, error) {
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", branch+"...HEAD", "--")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to compare %s with HEAD: %v", branch, err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", string(output))
+	}
+	ahead, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	behind, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
+
+// SwitchBranch switches to the specified branch. It refuses when the working
+// tree has uncommitted changes unless force is set, which discards them.
+func SwitchBranch(repoPath, branchName string, force bool) (string, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
@@ -299,7 +1169,25 @@ func SwitchBranch(repoPath, branchName string) (string, error) {
 		return "", fmt.Errorf("not a git repository: %s", repoPath)
 	}
 
-	cmd := exec.Command("git", "checkout", branchName)
+	args := []string{"checkout"}
+	if force {
+		args = append(args, "--force")
+	} else {
+		// Workspaces can be shared between clients, so never let a checkout
+		// carry over or clobber someone else's uncommitted changes.
+		statusCmd := exec.Command("git", "status", "--porcelain")
+		statusCmd.Dir = repoPath
+		statusOutput, err := statusCmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to get git status: %v", err)
+		}
+		if changes := strings.TrimSpace(string(statusOutput)); changes != "" {
+			return changes, fmt.Errorf("working tree has uncommitted changes; commit or discard them first, or set force to switch anyway and lose them")
+		}
+	}
+	args = append(args, branchName)
+
+	cmd := exec.Command("git", args...)
 	cmd.Dir = repoPath
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -310,23 +1198,90 @@ func SwitchBranch(repoPath, branchName string) (string, error) {
 }
 
 // SearchFiles searches for files containing the specified keywords
-func SearchFiles(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, includePatterns, excludePatterns []string, maxResults int) ([]SearchResult, error) {
-	return SearchFilesEnhanced(repoPath, keywords, searchMode, includeFilename, contextLines, includePatterns, excludePatterns, maxResults)
+func SearchFiles(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, includePatterns, excludePatterns, excludeKeywords []string, maxResults int) ([]SearchResult, error) {
+	return SearchFilesEnhanced(repoPath, keywords, searchMode, includeFilename, contextLines, includePatterns, excludePatterns, excludeKeywords, maxResults)
 }
 
+// maxListDepth caps how deep a recursive listing descends, including when no
+// depth limit is requested
+const maxListDepth = 64
+
 // ListFiles lists files in the specified directory
 func ListFiles(repoPath, dirPath string, recursive bool, includePatterns, excludePatterns []string, maxResults int) ([]FileInfo, error) {
+	files, _, err := ListFilesWithDepth(repoPath, dirPath, recursive, includePatterns, excludePatterns, maxResults, 0)
+	return files, err
+}
+
+// ListFilesWithDepth is ListFiles with a recursion limit: files more than
+// maxDepth levels below dirPath are left out (1 lists only dirPath itself;
+// 0 means maxListDepth). The bool reports whether any directory was cut off.
+func ListFilesWithDepth(repoPath, dirPath string, recursive bool, includePatterns, excludePatterns []string, maxResults, maxDepth int) ([]FileInfo, bool, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	repoPath = validPath
 
-	fullPath := filepath.Join(repoPath, dirPath)
+	if !isGitRepository(repoPath) {
+		return nil, false, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if err := checkRepositoryDir(repoPath, dirPath); err != nil {
+		return nil, false, err
+	}
+
+	// Collect candidate paths first so the order is stable before the limit
+	// is applied; stat and line counting only happen for the kept entries.
+	relPaths, truncated, err := collectFilePaths(repoPath, dirPath, recursive, includePatterns, excludePatterns, maxDepth)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sort.Slice(relPaths, func(i, j int) bool {
+		return lessPathDirsFirst(relPaths[i], relPaths[j])
+	})
+	if maxResults > 0 && len(relPaths) > maxResults {
+		relPaths = relPaths[:maxResults]
+	}
 
 	var files []FileInfo
-	count := 0
+	for _, relPath := range relPaths {
+		entryFullPath := filepath.Join(repoPath, relPath)
+		info, err := os.Lstat(entryFullPath)
+		if err != nil {
+			continue
+		}
+
+		// Symlinks leading out of the workspace are listed but never read
+		lineCount := 0
+		if resolved, err := ResolveWorkspaceFile(repoPath, relPath); err == nil {
+			_, lineCount = countFileCharacters(resolved)
+		}
+		files = append(files, FileInfo{
+			Name:      filepath.Base(relPath),
+			Path:      relPath,
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+			LineCount: lineCount,
+		})
+	}
+
+	return files, truncated, nil
+}
+
+// collectFilePaths returns the repository-relative paths of files under dirPath
+// that pass the include/exclude patterns, in no particular order. A recursive
+// walk stops maxDepth levels down (see ListFilesWithDepth) and reports whether
+// it skipped any directory because of that.
+func collectFilePaths(repoPath, dirPath string, recursive bool, includePatterns, excludePatterns []string, maxDepth int) ([]string, bool, error) {
+	fullPath := filepath.Join(repoPath, dirPath)
+	if maxDepth <= 0 || maxDepth > maxListDepth {
+		maxDepth = maxListDepth
+	}
+
+	var relPaths []string
+	truncated := false
 
 	if recursive {
 		err := filepath.WalkDir(fullPath, func(path string, d fs.DirEntry, err error) error {
@@ -350,6 +1305,11 @@ func ListFiles(repoPath, dirPath string, recursive bool, includePatterns, exclud
 				if shouldSkipDirectory(relPath, excludePatterns) {
 					return fs.SkipDir // Skip this directory and all its contents
 				}
+				// Files inside a directory at depth maxDepth would be one level too deep
+				if path != fullPath && pathDepth(fullPath, path) >= maxDepth {
+					truncated = true
+					return fs.SkipDir
+				}
 				return nil // Skip directory entries in output
 			}
 
@@ -358,44 +1318,20 @@ func ListFiles(repoPath, dirPath string, recursive bool, includePatterns, exclud
 				return nil
 			}
 
-			info, err := d.Info()
-			if err != nil {
-				return nil
-			}
-
-			_, lineCount := countFileCharacters(path)
-			fileInfo := FileInfo{
-				Name:      d.Name(),
-				Path:      relPath,
-				Size:      info.Size(),
-				ModTime:   info.ModTime(),
-				LineCount: lineCount,
-			}
-
-			files = append(files, fileInfo)
-
-			count++
-			if maxResults > 0 && count >= maxResults {
-				return fmt.Errorf("max results reached")
-			}
-
+			relPaths = append(relPaths, relPath)
 			return nil
 		})
 
-		if err != nil && err.Error() != "max results reached" {
-			return nil, fmt.Errorf("failed to walk directory: %v", err)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to walk directory: %v", err)
 		}
 	} else {
 		entries, err := os.ReadDir(fullPath)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read directory: %v", err)
+			return nil, false, fmt.Errorf("failed to read directory: %v", err)
 		}
 
 		for _, entry := range entries {
-			if maxResults > 0 && count >= maxResults {
-				break
-			}
-
 			// Skip directories in output
 			if entry.IsDir() {
 				continue
@@ -408,32 +1344,152 @@ func ListFiles(repoPath, dirPath string, recursive bool, includePatterns, exclud
 				continue
 			}
 
-			info, err := entry.Info()
-			if err != nil {
+			relPaths = append(relPaths, relPath)
+		}
+	}
+
+	return relPaths, truncated, nil
+}
+
+// pathDepth returns how many levels path lies below root
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(filepath.ToSlash(rel), "/") + 1
+}
+
+// FilterFilesByMetrics lists files under dir whose line count and byte size fall
+// within the given bounds (0 means unbounded). Line counts are only computed for
+// text files up to maxScanFileSize; such files never match a line bound.
+func FilterFilesByMetrics(repoPath, dir string, minLines, maxLines int, minBytes, maxBytes int64, recursive bool) ([]FileInfo, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if minLines < 0 || maxLines < 0 || minBytes < 0 || maxBytes < 0 {
+		return nil, fmt.Errorf("thresholds must not be negative")
+	}
+	if (maxLines > 0 && minLines > maxLines) || (maxBytes > 0 && minBytes > maxBytes) {
+		return nil, fmt.Errorf("minimum threshold is greater than maximum")
+	}
+
+	if err := checkRepositoryDir(repoPath, dir); err != nil {
+		return nil, err
+	}
+
+	relPaths, _, err := collectFilePaths(repoPath, dir, recursive, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(relPaths, func(i, j int) bool {
+		return lessPathDirsFirst(relPaths[i], relPaths[j])
+	})
+
+	lineBounded := minLines > 0 || maxLines > 0
+	var files []FileInfo
+	for _, relPath := range relPaths {
+		fullPath, err := ResolveWorkspaceFile(repoPath, relPath)
+		if err != nil {
+			continue
+		}
+		info, err := os.Lstat(fullPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		size := info.Size()
+		if size < minBytes || (maxBytes > 0 && size > maxBytes) {
+			continue
+		}
+
+		lineCount := 0
+		if size <= maxScanFileSize {
+			if head, err := readFileHead(fullPath, binaryCheckSize); err == nil && bytes.IndexByte(head, 0) < 0 {
+				_, lineCount = countFileCharacters(fullPath)
+			} else if lineBounded {
 				continue
 			}
+		} else if lineBounded {
+			continue
+		}
+		if lineCount < minLines || (maxLines > 0 && lineCount > maxLines) {
+			continue
+		}
+
+		files = append(files, FileInfo{
+			Name:      filepath.Base(relPath),
+			Path:      relPath,
+			Size:      size,
+			ModTime:   info.ModTime(),
+			LineCount: lineCount,
+		})
+	}
+
+	return files, nil
+}
 
-			entryFullPath := filepath.Join(repoPath, relPath)
-			_, lineCount := countFileCharacters(entryFullPath)
-			fileInfo := FileInfo{
-				Name:      entry.Name(),
-				Path:      relPath,
-				Size:      info.Size(),
-				ModTime:   info.ModTime(),
-				LineCount: lineCount,
-			}
+// lessPathDirsFirst orders paths like a tree listing: at each level, entries
+// inside subdirectories come before files, and names sort alphabetically
+func lessPathDirsFirst(a, b string) bool {
+	aParts := strings.Split(filepath.ToSlash(a), "/")
+	bParts := strings.Split(filepath.ToSlash(b), "/")
 
-			files = append(files, fileInfo)
-			count++
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		if aParts[i] == bParts[i] {
+			continue
 		}
+		aIsDir := i < len(aParts)-1
+		bIsDir := i < len(bParts)-1
+		if aIsDir != bIsDir {
+			return aIsDir
+		}
+		return aParts[i] < bParts[i]
 	}
+	return len(aParts) < len(bParts)
+}
 
-	return files, nil
+// CloneOptions controls how CloneRepositoryWithOptions runs git clone
+type CloneOptions struct {
+	Depth  int    // Create a shallow clone with this many commits (0 = full history)
+	Branch string // Clone only this branch (--branch <branch> --single-branch)
+
+	// Token authenticates an https clone; Username defaults to
+	// defaultCloneUsername. The token is only used for the clone itself: it is
+	// not kept in the remote URL and is redacted from output and errors.
+	Username string
+	Token    string
+
+	// SSHKeyPath selects the private key used for ssh (git@host:...) clones
+	SSHKeyPath string
 }
 
+// defaultCloneUsername is sent with a token when no username is given. Hosts
+// such as GitHub ignore the username for token authentication.
+const defaultCloneUsername = "x-access-token"
+
+// cloneWaitDelay bounds how long a killed clone may hold its output open
+// through child processes (such as git-remote-https) that outlive git itself
+const cloneWaitDelay = 5 * time.Second
+
 // CloneRepository clones a Git repository into the workspace
 // If repoName is empty, it will be extracted from the URL
 func CloneRepository(repoURL, repoName string) (string, string, error) {
+	return CloneRepositoryWithOptions(context.Background(), repoURL, repoName, CloneOptions{})
+}
+
+// CloneRepositoryWithOptions clones a Git repository into the workspace,
+// optionally shallow and/or limited to a single branch. The clone is killed
+// when ctx is done or after the session's clone timeout. If the clone fails,
+// a target directory it created is removed so that a retry can start clean.
+func CloneRepositoryWithOptions(ctx context.Context, repoURL, repoName string, opts CloneOptions) (string, string, error) {
 	wm := GetWorkspaceManager()
 	if wm == nil {
 		return "", "", fmt.Errorf("workspace not initialized")
@@ -456,17 +1512,134 @@ func CloneRepository(repoURL, repoName string) (string, string, error) {
 	// Get target path for clone
 	targetPath := wm.GetRepositoryPath(repoName)
 
+	cloneURL := repoURL
+	if opts.Token != "" {
+		var err error
+		cloneURL, err = authenticatedCloneURL(repoURL, opts.Username, opts.Token)
+		if err != nil {
+			return "", repoName, err
+		}
+	}
+
+	args, err := buildCloneArgs(cloneURL, targetPath, opts)
+	if err != nil {
+		return "", repoName, err
+	}
+
+	timeout := GetSessionConfig().GetCloneTimeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
 	// Execute git clone
-	cmd := exec.Command("git", "clone", repoURL, targetPath)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.WaitDelay = cloneWaitDelay
+	if opts.SSHKeyPath != "" {
+		sshCommand, err := sshCommandEnv(opts.SSHKeyPath)
+		if err != nil {
+			return "", repoName, err
+		}
+		cmd.Env = append(os.Environ(), sshCommand)
+	}
+
+	// A failed or killed clone can leave a partial checkout that the next clone
+	// would report as already existing; remove it, but only if the clone created it
+	_, statErr := os.Lstat(targetPath)
+	createdTarget := os.IsNotExist(statErr)
+
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return string(output), repoName, fmt.Errorf("git clone failed: %v", err)
+		if createdTarget {
+			os.RemoveAll(targetPath)
+		}
+		switch ctx.Err() {
+		case context.DeadlineExceeded:
+			err = fmt.Errorf("git clone timed out after %s", timeout)
+		case context.Canceled:
+			err = fmt.Errorf("git clone canceled")
+		default:
+			err = fmt.Errorf("git clone failed: %s", redactSecret(err.Error(), opts.Token))
+		}
+		return redactSecret(string(output), opts.Token), repoName, err
+	}
+
+	// Do not leave the token in .git/config, where repository info would show it
+	if opts.Token != "" {
+		cmd = exec.Command("git", "remote", "set-url", "origin", repoURL)
+		cmd.Dir = targetPath
+		if err := cmd.Run(); err != nil {
+			return redactSecret(string(output), opts.Token), repoName, fmt.Errorf("failed to remove credentials from remote URL: %v", err)
+		}
+	}
+
+	return redactSecret(string(output), opts.Token), repoName, nil
+}
+
+// sshCommandEnv returns the GIT_SSH_COMMAND environment entry that makes ssh
+// use only the given private key. Like ssh itself, it refuses key files that
+// are readable by group or others.
+func sshCommandEnv(keyPath string) (string, error) {
+	info, err := os.Stat(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("ssh key not found: %s", keyPath)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("ssh key is not a regular file: %s", keyPath)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return "", fmt.Errorf("ssh key permissions %04o are too open (use chmod 600): %s", perm, keyPath)
+	}
+
+	// GIT_SSH_COMMAND is run by a shell, so single-quote the path
+	quoted := "'" + strings.ReplaceAll(keyPath, "'", `'\''`) + "'"
+	return fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes", quoted), nil
+}
+
+// authenticatedCloneURL returns repoURL with username and token embedded as
+// userinfo. Only https URLs are supported.
+func authenticatedCloneURL(repoURL, username, token string) (string, error) {
+	parsed, err := url.Parse(repoURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return "", fmt.Errorf("token authentication requires an https URL")
+	}
+	if username == "" {
+		username = defaultCloneUsername
 	}
+	parsed.User = url.UserPassword(username, token)
+	return parsed.String(), nil
+}
+
+// redactSecret replaces every occurrence of secret in text, including the
+// escaped form it takes inside a URL
+func redactSecret(text, secret string) string {
+	if secret == "" {
+		return text
+	}
+	text = strings.ReplaceAll(text, secret, "***")
+	escaped := strings.TrimPrefix(url.UserPassword("", secret).String(), ":")
+	return strings.ReplaceAll(text, escaped, "***")
+}
 
-	return string(output), repoName, nil
+// buildCloneArgs assembles the git clone arguments for opts
+func buildCloneArgs(repoURL, targetPath string, opts CloneOptions) ([]string, error) {
+	if opts.Depth < 0 {
+		return nil, fmt.Errorf("depth must be non-negative, got %d", opts.Depth)
+	}
+	if strings.HasPrefix(opts.Branch, "-") {
+		return nil, fmt.Errorf("invalid branch name: %s", opts.Branch)
+	}
+
+	args := []string{"clone"}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch, "--single-branch")
+	}
+	return append(args, "--", repoURL, targetPath), nil
 }
 
-// extractRepoNameFromURL extracts the repository name from a Git URL
+// extractRepoNameFromURL extracts the repository name from a Git URL. Any
+// query string or fragment is dropped before the .git suffix is stripped.
 func extractRepoNameFromURL(repoURL string) (string, error) {
 	if repoURL == "" {
 		return "", fmt.Errorf("repository URL cannot be empty")
@@ -478,24 +1651,29 @@ func extractRepoNameFromURL(repoURL string) (string, error) {
 	// https://github.com/user/repo
 	// etc.
 
-	// Remove .git suffix if present
-	url := strings.TrimSuffix(repoURL, ".git")
-
-	// Split by / and get the last part
-	parts := strings.Split(url, "/")
-	if len(parts) == 0 {
-		return "", fmt.Errorf("invalid repository URL format")
+	repoPath := repoURL
+	if strings.Contains(repoURL, "://") {
+		parsed, err := url.Parse(repoURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid repository URL: %v", err)
+		}
+		repoPath = parsed.Path
+	} else if cut := strings.IndexAny(repoPath, "?#"); cut >= 0 {
+		// scp-like SSH form (git@host:user/repo) or a local path
+		repoPath = repoPath[:cut]
 	}
 
+	// Remove trailing slashes (common when copied from a browser), then the .git suffix
+	repoPath = strings.TrimSuffix(strings.TrimRight(repoPath, "/"), ".git")
+
+	// Split by / and get the last part
+	parts := strings.Split(repoPath, "/")
 	repoName := parts[len(parts)-1]
 
 	// Handle SSH URLs like git@github.com:user/repo
 	if strings.Contains(repoName, ":") {
 		colonParts := strings.Split(repoName, ":")
-		if len(colonParts) > 1 {
-			pathParts := strings.Split(colonParts[len(colonParts)-1], "/")
-			repoName = pathParts[len(pathParts)-1]
-		}
+		repoName = colonParts[len(colonParts)-1]
 	}
 
 	// Validate repository name
@@ -518,6 +1696,7 @@ type FileContentResult struct {
 	TotalLines int    `json:"total_lines,omitempty"`
 	StartLine  int    `json:"start_line,omitempty"`
 	EndLine    int    `json:"end_line,omitempty"`
+	Encoding   string `json:"encoding,omitempty"` // encoding the file was decoded from, e.g. "utf-8" or "shift_jis"
 }
 
 // ReadmeFileInfo represents information about a README file
@@ -526,6 +1705,21 @@ type ReadmeFileInfo struct {
 	Size      int64     `json:"size"`
 	ModTime   time.Time `json:"mod_time"`
 	LineCount int       `json:"line_count,omitempty"`
+	Content   string    `json:"content,omitempty"` // first lines, filled in by AddReadmeContents
+}
+
+// FileMetadata describes a file without its content
+type FileMetadata struct {
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	Mode         string    `json:"mode"`
+	ModTime      time.Time `json:"mod_time"`
+	IsDir        bool      `json:"is_dir,omitempty"`
+	IsExecutable bool      `json:"is_executable,omitempty"`
+	IsSymlink    bool      `json:"is_symlink,omitempty"`
+	LinkTarget   string    `json:"link_target,omitempty"`
+	IsBinary     bool      `json:"is_binary,omitempty"`
+	MIMEType     string    `json:"mime_type,omitempty"`
 }
 
 // FileStatistics contains file statistics for a repository
@@ -602,7 +1796,8 @@ func GetFileStatistics(repoPath string, excludePatterns []string) (*FileStatisti
 	return stats, nil
 }
 
-// GetFileContent reads the content of a file
+// GetFileContent reads the content of a file, transcoding it to UTF-8 from
+// its detected encoding
 func GetFileContent(repoPath, filePath string, maxLines int) (string, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
@@ -611,26 +1806,49 @@ func GetFileContent(repoPath, filePath string, maxLines int) (string, error) {
 	}
 	repoPath = validPath
 
-	fullPath := filepath.Join(repoPath, filePath)
+	fullPath, err := ResolveWorkspaceFile(repoPath, filePath)
+	if err != nil {
+		return "", err
+	}
 
-	file, err := os.Open(fullPath)
+	// A full read buffers the whole file, so refuse files over the size cap
+	// unless the caller limits the number of lines
+	if maxLines == 0 {
+		if info, err := os.Stat(fullPath); err == nil {
+			if maxBytes := GetSessionConfig().GetMaxFileBytes(); info.Size() > maxBytes {
+				return "", fmt.Errorf("file is too large to read in full (%d bytes, limit %d); set max_lines or start_line to read part of it", info.Size(), maxBytes)
+			}
+		}
+	}
+
+	file, _, err := openTextFile(fullPath, "")
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %v", err)
 	}
 	defer file.Close()
 
+	// bufio.Reader rather than Scanner: lines longer than the scanner's 64KB
+	// token limit must not fail a limited read
 	var content strings.Builder
-	scanner := bufio.NewScanner(file)
+	reader := bufio.NewReader(file)
 	lineCount := 0
 
-	for scanner.Scan() && (maxLines == 0 || lineCount < maxLines) {
-		content.WriteString(scanner.Text())
-		content.WriteString("\n")
-		lineCount++
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+	for maxLines == 0 || lineCount < maxLines {
+		line, err := reader.ReadString('\n')
+		if lineCount == 0 {
+			line = strings.TrimPrefix(line, utf8BOM)
+		}
+		if line != "" {
+			content.WriteString(strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"))
+			content.WriteString("\n")
+			lineCount++
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read file: %v", err)
+		}
 	}
 
 	return content.String(), nil
@@ -665,23 +1883,77 @@ func GetMultipleFileContents(repoPath string, filePaths []string, maxLines int)
 	return results, nil
 }
 
-// GetFileContentWithLineNumbers reads the content of a file with optional line numbers
+// utf8BOM is the byte order mark some editors put at the start of UTF-8 files.
+// File readers drop it from the first line, where it would only garble output.
+const utf8BOM = "\ufeff"
+
+// GetFileContentWithLineNumbers reads the content of a file with optional line numbers,
+// transcoded to UTF-8 from its detected encoding. A leading byte order mark is dropped.
 // Returns: content, totalLines, actualStartLine, actualEndLine, error
 func GetFileContentWithLineNumbers(repoPath, filePath string, startLine, maxLines int, showLineNumbers bool) (string, int, int, int, error) {
+	result, err := readFileLines(repoPath, filePath, startLine, maxLines, showLineNumbers, false, "")
+	if err != nil {
+		return "", 0, 0, 0, err
+	}
+	return result.Content, result.TotalLines, result.StartLine, result.EndLine, nil
+}
+
+// GetFileTailWithLineNumbers reads the last maxLines lines of a file, numbered
+// by their absolute position in the file
+// Returns: content, totalLines, actualStartLine, actualEndLine, error
+func GetFileTailWithLineNumbers(repoPath, filePath string, maxLines int, showLineNumbers bool) (string, int, int, int, error) {
+	result, err := readFileLines(repoPath, filePath, 1, maxLines, showLineNumbers, true, "")
+	if err != nil {
+		return "", 0, 0, 0, err
+	}
+	return result.Content, result.TotalLines, result.StartLine, result.EndLine, nil
+}
+
+// readFileLines reads up to maxLines lines starting at startLine. When fromEnd
+// is set, startLine is ignored and the final maxLines lines are read instead.
+// The file is transcoded to UTF-8 from encoding, or from its detected encoding
+// when encoding is empty; the encoding used is reported in the result. A Git
+// LFS pointer is replaced by its object's content when git-lfs can fetch it,
+// and otherwise reported as an error naming the object.
+func readFileLines(repoPath, filePath string, startLine, maxLines int, showLineNumbers, fromEnd bool, encoding string) (FileContentResult, error) {
+	result := FileContentResult{FilePath: filePath}
+
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
-		return "", 0, 0, 0, err
+		return result, err
 	}
 	repoPath = validPath
 
-	fullPath := filepath.Join(repoPath, filePath)
+	fullPath, err := ResolveWorkspaceFile(repoPath, filePath)
+	if err != nil {
+		return result, err
+	}
+
+	open := func(encoding string) (io.ReadCloser, string, error) {
+		return openTextFile(fullPath, encoding)
+	}
+	// Read a Git LFS pointer as the object it stands for
+	if pointer, data := readLFSPointer(fullPath); pointer != nil {
+		content, err := smudgeLFSPointer(repoPath, filePath, pointer, data)
+		if err != nil {
+			return result, err
+		}
+		head := content[:min(len(content), binaryCheckSize)]
+		if enc := detectEncoding(head); bytes.IndexByte(head, 0) >= 0 && enc != encodingUTF16LE && enc != encodingUTF16BE {
+			return result, fmt.Errorf("binary file stored in Git LFS (%d bytes)", len(content))
+		}
+		open = func(encoding string) (io.ReadCloser, string, error) {
+			return openTextContent(content, encoding)
+		}
+	}
 
 	// First pass: count total lines
-	file, err := os.Open(fullPath)
+	file, encoding, err := open(encoding)
 	if err != nil {
-		return "", 0, 0, 0, fmt.Errorf("failed to open file: %v", err)
+		return result, fmt.Errorf("failed to open file: %v", err)
 	}
+	result.Encoding = encoding
 
 	totalLines := 0
 	scanner := bufio.NewScanner(file)
@@ -691,21 +1963,26 @@ func GetFileContentWithLineNumbers(repoPath, filePath string, startLine, maxLine
 	file.Close()
 
 	if err := scanner.Err(); err != nil {
-		return "", 0, 0, 0, fmt.Errorf("failed to count lines: %v", err)
+		return result, fmt.Errorf("failed to count lines: %v", err)
 	}
+	result.TotalLines = totalLines
 
 	// Normalize startLine
+	if fromEnd && maxLines > 0 {
+		startLine = totalLines - maxLines + 1
+	}
 	if startLine < 1 {
 		startLine = 1
 	}
 	if startLine > totalLines {
-		return "", totalLines, startLine, startLine, nil
+		result.StartLine, result.EndLine = startLine, startLine
+		return result, nil
 	}
 
 	// Second pass: read content from startLine
-	file, err = os.Open(fullPath)
+	file, _, err = open(encoding)
 	if err != nil {
-		return "", 0, 0, 0, fmt.Errorf("failed to open file: %v", err)
+		return result, fmt.Errorf("failed to open file: %v", err)
 	}
 	defer file.Close()
 
@@ -723,16 +2000,20 @@ func GetFileContentWithLineNumbers(repoPath, filePath string, startLine, maxLine
 			break
 		}
 		linesRead++
+		text := scanner.Text()
+		if currentLine == 1 {
+			text = strings.TrimPrefix(text, utf8BOM)
+		}
 		if showLineNumbers {
-			content.WriteString(fmt.Sprintf("%4d: %s\n", currentLine, scanner.Text()))
+			content.WriteString(fmt.Sprintf("%4d: %s\n", currentLine, text))
 		} else {
-			content.WriteString(scanner.Text())
+			content.WriteString(text)
 			content.WriteString("\n")
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return "", 0, 0, 0, fmt.Errorf("failed to read file: %v", err)
+		return result, fmt.Errorf("failed to read file: %v", err)
 	}
 
 	endLine := startLine + linesRead - 1
@@ -740,11 +2021,103 @@ func GetFileContentWithLineNumbers(repoPath, filePath string, startLine, maxLine
 		endLine = startLine
 	}
 
-	return content.String(), totalLines, startLine, endLine, nil
+	result.Content = content.String()
+	result.StartLine = startLine
+	result.EndLine = endLine
+	return result, nil
+}
+
+// openTextFile opens a file as UTF-8 text, transcoding from encoding, or from
+// the encoding detected in its first bytes when encoding is empty. It returns
+// the encoding used.
+func openTextFile(fullPath, encoding string) (io.ReadCloser, string, error) {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if encoding == "" {
+		head := make([]byte, binaryCheckSize)
+		n, err := io.ReadFull(file, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			file.Close()
+			return nil, "", err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			file.Close()
+			return nil, "", err
+		}
+		encoding = detectEncoding(head[:n])
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{newDecodingReader(file, encoding), file}, encoding, nil
+}
+
+// FilePage represents one fixed-size page of a file's content
+type FilePage struct {
+	FilePath   string `json:"file_path"`
+	Content    string `json:"content"`
+	Page       int    `json:"page"`
+	TotalPages int    `json:"total_pages"`
+	PageSize   int    `json:"page_size"`
+	TotalLines int    `json:"total_lines"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+}
+
+// GetFilePage returns the given 1-based page of a file split into pageSize-line
+// chunks, with line numbers
+func GetFilePage(repoPath, filePath string, page, pageSize int) (*FilePage, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("page must be at least 1")
+	}
+	if pageSize < 1 {
+		return nil, fmt.Errorf("page size must be at least 1")
+	}
+
+	startLine := (page-1)*pageSize + 1
+	content, totalLines, actualStart, actualEnd, err := GetFileContentWithLineNumbers(repoPath, filePath, startLine, pageSize, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty file still has a single (empty) page
+	totalPages := (totalLines + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	if page > totalPages {
+		return nil, fmt.Errorf("page %d out of range (file has %d pages of %d lines)", page, totalPages, pageSize)
+	}
+
+	return &FilePage{
+		FilePath:   filePath,
+		Content:    content,
+		Page:       page,
+		TotalPages: totalPages,
+		PageSize:   pageSize,
+		TotalLines: totalLines,
+		StartLine:  actualStart,
+		EndLine:    actualEnd,
+	}, nil
 }
 
-// GetMultipleFileContentsWithLineNumbers reads the content of multiple files with optional line numbers
+// GetMultipleFileContentsWithLineNumbers reads the content of multiple files with optional line numbers.
+// Binary files are reported as errors rather than read.
 func GetMultipleFileContentsWithLineNumbers(repoPath string, filePaths []string, startLine, maxLines int, showLineNumbers bool) ([]FileContentResult, error) {
+	return getMultipleFileContents(repoPath, filePaths, startLine, maxLines, showLineNumbers, false, false, "")
+}
+
+// maxConcurrentFileReads bounds the files getMultipleFileContents reads at
+// once. It is a variable so benchmarks can compare against sequential reads.
+var maxConcurrentFileReads = min(runtime.NumCPU(), 8)
+
+// getMultipleFileContents reads the files in parallel; results keep the order
+// of filePaths and a failure is reported in that file's result only
+func getMultipleFileContents(repoPath string, filePaths []string, startLine, maxLines int, showLineNumbers, forceBinary, fromEnd bool, encoding string) ([]FileContentResult, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
@@ -752,31 +2125,268 @@ func GetMultipleFileContentsWithLineNumbers(repoPath string, filePaths []string,
 	}
 	repoPath = validPath
 
-	var results []FileContentResult
+	results := make([]FileContentResult, len(filePaths))
+	sem := make(chan struct{}, max(maxConcurrentFileReads, 1))
+	var wg sync.WaitGroup
+	for i, filePath := range filePaths {
+		wg.Add(1)
+		go func(i int, filePath string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-	for _, filePath := range filePaths {
-		result := FileContentResult{
-			FilePath: filePath,
-		}
+			results[i] = readFileContentResult(repoPath, filePath, startLine, maxLines, showLineNumbers, forceBinary, fromEnd, encoding)
+		}(i, filePath)
+	}
+	wg.Wait()
+
+	return results, nil
+}
 
-		content, totalLines, actualStart, actualEnd, err := GetFileContentWithLineNumbers(repoPath, filePath, startLine, maxLines, showLineNumbers)
+// readFileContentResult reads one file for getMultipleFileContents, recording
+// any error in the result
+func readFileContentResult(repoPath, filePath string, startLine, maxLines int, showLineNumbers, forceBinary, fromEnd bool, encoding string) FileContentResult {
+	result := FileContentResult{
+		FilePath: filePath,
+	}
+
+	if !forceBinary {
+		fullPath, err := ResolveWorkspaceFile(repoPath, filePath)
+		if err == nil {
+			err = checkNotBinary(fullPath)
+		}
 		if err != nil {
 			result.Error = err.Error()
-		} else {
-			result.Content = content
-			result.TotalLines = totalLines
-			result.StartLine = actualStart
-			result.EndLine = actualEnd
+			return result
+		}
+	}
+
+	read, err := readFileLines(repoPath, filePath, startLine, maxLines, showLineNumbers, fromEnd, encoding)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	return read
+}
+
+// CitationRequest names an exact, inclusive line range of a file to quote
+type CitationRequest struct {
+	FilePath  string `json:"file_path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// FetchCitations reads each requested line range with line numbers. Every
+// citation succeeds or fails on its own; a range that does not lie entirely
+// within the file is reported as an error for that citation only.
+func FetchCitations(repoPath string, citations []CitationRequest) ([]FileContentResult, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	results := make([]FileContentResult, 0, len(citations))
+	for _, citation := range citations {
+		result := FileContentResult{FilePath: citation.FilePath}
+		if err := readCitation(repoPath, citation, &result); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// readCitation fills result with the cited lines or returns why it cannot
+func readCitation(repoPath string, citation CitationRequest, result *FileContentResult) error {
+	if citation.FilePath == "" {
+		return fmt.Errorf("file_path is required")
+	}
+	if err := checkWithinRepository(repoPath, citation.FilePath); err != nil {
+		return err
+	}
+	if citation.StartLine < 1 {
+		return fmt.Errorf("start_line must be at least 1")
+	}
+	if citation.EndLine < citation.StartLine {
+		return fmt.Errorf("end_line (%d) must be >= start_line (%d)", citation.EndLine, citation.StartLine)
+	}
+	fullPath, err := ResolveWorkspaceFile(repoPath, citation.FilePath)
+	if err != nil {
+		return err
+	}
+	if err := checkNotBinary(fullPath); err != nil {
+		return err
+	}
+
+	maxLines := citation.EndLine - citation.StartLine + 1
+	read, err := readFileLines(repoPath, citation.FilePath, citation.StartLine, maxLines, true, false, "")
+	if err != nil {
+		return err
+	}
+	if citation.EndLine > read.TotalLines {
+		return fmt.Errorf("lines %d-%d are beyond the end of the file (%d lines)", citation.StartLine, citation.EndLine, read.TotalLines)
+	}
+
+	*result = read
+	return nil
+}
+
+// checkWithinRepository rejects file paths that lead out of the repository
+func checkWithinRepository(repoPath, filePath string) error {
+	fullPath := filepath.Join(repoPath, filePath)
+	if rel, err := filepath.Rel(repoPath, fullPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path is outside the repository")
+	}
+	return nil
+}
+
+// checkRepositoryDir rejects directories that lead out of the repository,
+// either by their path or through a symlink. A directory that does not exist
+// passes, for the caller to report.
+func checkRepositoryDir(repoPath, dirPath string) error {
+	if err := checkWithinRepository(repoPath, dirPath); err != nil {
+		return err
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Join(repoPath, dirPath))
+	if err != nil {
+		return nil
+	}
+	realRepo, err := filepath.EvalSymlinks(repoPath)
+	if err != nil {
+		realRepo = repoPath
+	}
+	if rel, err := filepath.Rel(realRepo, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("path resolves outside the repository: %s", dirPath)
+	}
+	return nil
+}
+
+// LineRange is an inclusive, 1-based range of lines
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// mergeLineRanges sorts ranges and merges those that overlap or touch, so no
+// line is returned twice. An inverted or non-positive range is an error.
+func mergeLineRanges(ranges []LineRange) ([]LineRange, error) {
+	sorted := make([]LineRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start < 1 {
+			return nil, fmt.Errorf("range %d-%d: start must be at least 1", r.Start, r.End)
+		}
+		if r.End < r.Start {
+			return nil, fmt.Errorf("range %d-%d is inverted: end must be >= start", r.Start, r.End)
+		}
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var merged []LineRange
+	for _, r := range sorted {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End+1 {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, nil
+}
+
+// GetFileRanges reads several line ranges of one file with line numbers, one
+// result per range after merging overlapping ranges (see mergeLineRanges).
+// Ranges running past the end of the file are clamped to its last line; a
+// range starting beyond it is an error.
+func GetFileRanges(repoPath, filePath string, ranges []LineRange) ([]FileContentResult, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	if filePath == "" {
+		return nil, fmt.Errorf("file_path is required")
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("at least one range is required")
+	}
+	if err := checkWithinRepository(repoPath, filePath); err != nil {
+		return nil, err
+	}
+	merged, err := mergeLineRanges(ranges)
+	if err != nil {
+		return nil, err
+	}
+	fullPath, err := ResolveWorkspaceFile(repoPath, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotBinary(fullPath); err != nil {
+		return nil, err
+	}
+
+	results := make([]FileContentResult, 0, len(merged))
+	for _, r := range merged {
+		read, err := readFileLines(repoPath, filePath, r.Start, r.End-r.Start+1, true, false, "")
+		if err != nil {
+			return nil, err
+		}
+		if r.Start > read.TotalLines {
+			return nil, fmt.Errorf("range %d-%d starts beyond the end of the file (%d lines)", r.Start, r.End, read.TotalLines)
 		}
+		results = append(results, read)
+	}
+
+	return results, nil
+}
+
+// checkNotBinary returns an error describing the file if it looks binary
+// (a NUL byte within the first 8KB that is not part of UTF-16 text).
+// fullPath must already be resolved with ResolveWorkspaceFile. Unreadable
+// files are left for the caller to report.
+func checkNotBinary(fullPath string) error {
+	head, err := readFileHead(fullPath, binaryCheckSize)
+	if err != nil || bytes.IndexByte(head, 0) < 0 {
+		return nil
+	}
+	if enc := detectEncoding(head); enc == encodingUTF16LE || enc == encodingUTF16BE {
+		return nil
+	}
 
-		results = append(results, result)
+	size := int64(len(head))
+	if info, err := os.Stat(fullPath); err == nil {
+		size = info.Size()
 	}
+	return fmt.Errorf("binary file (%d bytes); set force_binary to read it anyway", size)
+}
 
-	return results, nil
+// readmeVendorDirs are dependency directories GetReadmeFiles skips at any
+// depth when no include or exclude patterns are given
+var readmeVendorDirs = map[string]bool{
+	"node_modules":     true,
+	"vendor":           true,
+	"third_party":      true,
+	"bower_components": true,
+	".venv":            true,
+	"venv":             true,
 }
 
-// GetReadmeFiles finds all README files in the repository
-func GetReadmeFiles(repoPath string, recursive bool) ([]ReadmeFileInfo, error) {
+// GetReadmeFiles finds all README files in the repository, filtered by
+// include/exclude patterns as in ListFiles. Without any patterns, READMEs of
+// vendored dependencies (readmeVendorDirs) are left out.
+func GetReadmeFiles(repoPath string, recursive bool, includePatterns, excludePatterns []string) ([]ReadmeFileInfo, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
@@ -785,7 +2395,7 @@ func GetReadmeFiles(repoPath string, recursive bool) ([]ReadmeFileInfo, error) {
 	repoPath = validPath
 
 	var readmeFiles []ReadmeFileInfo
-	readmePatterns := []string{"README", "README.*", "readme", "readme.*", "Readme", "Readme.*"}
+	skipVendor := len(includePatterns) == 0 && len(excludePatterns) == 0
 
 	if recursive {
 		err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
@@ -798,41 +2408,39 @@ func GetReadmeFiles(repoPath string, recursive bool) ([]ReadmeFileInfo, error) {
 				return fs.SkipDir
 			}
 
-			// Skip directories
-			if d.IsDir() {
+			// Get relative path
+			relPath, err := filepath.Rel(repoPath, path)
+			if err != nil {
 				return nil
 			}
 
-			// Check if file matches README pattern
-			fileName := d.Name()
-			for _, pattern := range readmePatterns {
-				if matched, err := filepath.Match(pattern, fileName); err == nil && matched {
-					info, err := d.Info()
-					if err != nil {
-						return nil
-					}
-
-					// Get relative path
-					relPath, err := filepath.Rel(repoPath, path)
-					if err != nil {
-						return nil
-					}
+			// For directories, check if we should skip the entire subtree
+			if d.IsDir() {
+				if relPath != "." && ((skipVendor && readmeVendorDirs[d.Name()]) || shouldSkipDirectory(relPath, excludePatterns)) {
+					return fs.SkipDir
+				}
+				return nil
+			}
 
-					// Count lines for text files
-					_, lineCount := countFileCharacters(path)
+			if !isReadmeName(d.Name()) || !shouldIncludeFile(relPath, includePatterns, excludePatterns) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
 
-					readmeInfo := ReadmeFileInfo{
-						Path:      relPath,
-						Size:      info.Size(),
-						ModTime:   info.ModTime(),
-						LineCount: lineCount,
-					}
+			// Count lines for text files
+			_, lineCount := countFileCharacters(path)
 
-					readmeFiles = append(readmeFiles, readmeInfo)
-					break // Don't check other patterns for this file
-				}
+			readmeInfo := ReadmeFileInfo{
+				Path:      relPath,
+				Size:      info.Size(),
+				ModTime:   info.ModTime(),
+				LineCount: lineCount,
 			}
 
+			readmeFiles = append(readmeFiles, readmeInfo)
 			return nil
 		})
 
@@ -847,47 +2455,67 @@ func GetReadmeFiles(repoPath string, recursive bool) ([]ReadmeFileInfo, error) {
 		}
 
 		for _, entry := range entries {
-			if entry.IsDir() {
+			if entry.IsDir() || !isReadmeName(entry.Name()) || !shouldIncludeFile(entry.Name(), includePatterns, excludePatterns) {
 				continue
 			}
 
 			fileName := entry.Name()
-			for _, pattern := range readmePatterns {
-				if matched, err := filepath.Match(pattern, fileName); err == nil && matched {
-					info, err := entry.Info()
-					if err != nil {
-						continue
-					}
-
-					// Count lines for text files
-					fullPath := filepath.Join(repoPath, fileName)
-					_, lineCount := countFileCharacters(fullPath)
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
 
-					readmeInfo := ReadmeFileInfo{
-						Path:      fileName,
-						Size:      info.Size(),
-						ModTime:   info.ModTime(),
-						LineCount: lineCount,
-					}
+			// Count lines for text files
+			fullPath := filepath.Join(repoPath, fileName)
+			_, lineCount := countFileCharacters(fullPath)
 
-					readmeFiles = append(readmeFiles, readmeInfo)
-					break // Don't check other patterns for this file
-				}
+			readmeInfo := ReadmeFileInfo{
+				Path:      fileName,
+				Size:      info.Size(),
+				ModTime:   info.ModTime(),
+				LineCount: lineCount,
 			}
+
+			readmeFiles = append(readmeFiles, readmeInfo)
 		}
 	}
 
 	return readmeFiles, nil
 }
 
+// AddReadmeContents fills in the first maxLines lines of each README (all of
+// it when maxLines is 0). Binary or unreadable files are left without content.
+func AddReadmeContents(repoPath string, readmeFiles []ReadmeFileInfo, maxLines int) {
+	for i := range readmeFiles {
+		fullPath, err := ResolveWorkspaceFile(repoPath, readmeFiles[i].Path)
+		if err != nil || checkNotBinary(fullPath) != nil {
+			continue
+		}
+		content, _, _, _, err := GetFileContentWithLineNumbers(repoPath, readmeFiles[i].Path, 1, maxLines, false)
+		if err == nil {
+			readmeFiles[i].Content = content
+		}
+	}
+}
+
 // Helper functions
 
+// isGitRepository reports whether path has a .git directory, or a .git file
+// pointing at one ("gitdir: ...") as in worktrees and submodules
 func isGitRepository(path string) bool {
 	gitDir := filepath.Join(path, ".git")
-	if stat, err := os.Stat(gitDir); err == nil {
-		return stat.IsDir()
+	stat, err := os.Stat(gitDir)
+	if err != nil {
+		return false
 	}
-	return false
+	if stat.IsDir() {
+		return true
+	}
+	if !stat.Mode().IsRegular() {
+		return false
+	}
+	head, err := readFileHead(gitDir, len("gitdir:"))
+	return err == nil && string(head) == "gitdir:"
 }
 
 func getCommitCount(repoPath string) (int, error) {
@@ -913,6 +2541,8 @@ func getLastCommit(repoPath string) (time.Time, error) {
 	return time.Parse("2006-01-02 15:04:05 -0700", strings.TrimSpace(string(output)))
 }
 
+// getCurrentBranch returns the checked out branch, or "(detached HEAD at
+// <shorthash>)" when HEAD points at a tag or commit rather than a branch
 func getCurrentBranch(repoPath string) (string, error) {
 	cmd := exec.Command("git", "branch", "--show-current")
 	cmd.Dir = repoPath
@@ -920,8 +2550,193 @@ func getCurrentBranch(repoPath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if branch := strings.TrimSpace(string(output)); branch != "" {
+		return branch, nil
+	}
+
+	cmd = exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = repoPath
+	output, err = cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(detached HEAD at %s)", strings.TrimSpace(string(output))), nil
+}
 
-	return strings.TrimSpace(string(output)), nil
+// getDefaultBranch returns the branch origin/HEAD points at, as recorded by
+// git clone. Without it (no remote, or a remote added by hand) a main or master
+// branch is assumed to be the default; the remote itself is never contacted.
+func getDefaultBranch(repoPath string) (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	cmd.Dir = repoPath
+	if output, err := cmd.Output(); err == nil {
+		if branch, ok := strings.CutPrefix(strings.TrimSpace(string(output)), "origin/"); ok && branch != "" {
+			return branch, nil
+		}
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		for _, ref := range []string{"refs/remotes/origin/" + branch, "refs/heads/" + branch} {
+			cmd := exec.Command("git", "show-ref", "--verify", "--quiet", ref)
+			cmd.Dir = repoPath
+			if cmd.Run() == nil {
+				return branch, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no default branch found")
+}
+
+// ListRemotes returns every configured remote with its fetch and push URLs
+// (git remote -v), in git's order
+func ListRemotes(repoPath string) ([]Remote, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	cmd := exec.Command("git", "remote", "-v")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %v", err)
+	}
+
+	return parseRemotes(string(output)), nil
+}
+
+// parseRemotes parses "name<TAB>url (fetch|push)" lines from git remote -v
+func parseRemotes(output string) []Remote {
+	remotes := []Remote{}
+	index := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		name, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		remoteURL, kind, ok := strings.Cut(rest, " (")
+		if !ok {
+			continue
+		}
+
+		i, seen := index[name]
+		if !seen {
+			i = len(remotes)
+			index[name] = i
+			remotes = append(remotes, Remote{Name: name})
+		}
+		switch strings.TrimSuffix(kind, ")") {
+		case "fetch":
+			remotes[i].FetchURL = remoteURL
+		case "push":
+			remotes[i].PushURL = remoteURL
+		}
+	}
+	return remotes
+}
+
+// ListSubmodules returns the submodules declared in .gitmodules with the
+// commit each is at (git submodule status). A repository without submodules
+// yields an empty list.
+func ListSubmodules(repoPath string) ([]Submodule, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	submodules, err := readGitmodules(repoPath)
+	if err != nil || len(submodules) == 0 {
+		return submodules, err
+	}
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "submodule", "status")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get submodule status: %v", err)
+	}
+
+	// Lines look like "<state><sha> <path>[ (<describe>)]" where state is
+	// ' ' (up to date), '-' (not initialized), '+' (different commit) or 'U'
+	byPath := make(map[string]int, len(submodules))
+	for i, submodule := range submodules {
+		byPath[submodule.Path] = i
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		state := line[0]
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+		i, ok := byPath[fields[1]]
+		if !ok {
+			continue
+		}
+		submodules[i].Commit = fields[0]
+		submodules[i].Initialized = state != '-'
+		submodules[i].Modified = state == '+'
+	}
+
+	return submodules, nil
+}
+
+// readGitmodules reads the path and URL of each submodule from .gitmodules,
+// in file order. A missing .gitmodules yields an empty list.
+func readGitmodules(repoPath string) ([]Submodule, error) {
+	submodules := []Submodule{}
+	if _, err := os.Stat(filepath.Join(repoPath, ".gitmodules")); os.IsNotExist(err) {
+		return submodules, nil
+	}
+
+	cmd := exec.Command("git", "config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.(path|url)$`)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// Exit code 1: the file has no submodule entries
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return submodules, nil
+		}
+		return nil, fmt.Errorf("failed to read .gitmodules: %v", err)
+	}
+
+	// Keys are submodule.<name>.<key>; names may themselves contain dots
+	index := make(map[string]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		dot := strings.LastIndex(key, ".")
+		name := strings.TrimPrefix(key[:dot], "submodule.")
+
+		i, seen := index[name]
+		if !seen {
+			i = len(submodules)
+			index[name] = i
+			submodules = append(submodules, Submodule{})
+		}
+		if key[dot+1:] == "path" {
+			submodules[i].Path = value
+		} else {
+			submodules[i].URL = value
+		}
+	}
+
+	return submodules, nil
 }
 
 func getRemoteURL(repoPath string) (string, error) {
@@ -948,25 +2763,59 @@ func findLicenseFile(repoPath string) (string, error) {
 	return "", fmt.Errorf("no license file found")
 }
 
+// readmeExtensions are the extensions a README may have, in order of
+// preference when a directory has more than one
+var readmeExtensions = []string{".md", ".markdown", ".mdown", ".mkd", ".rst", ".adoc", ".asciidoc", ".txt", "", ".org", ".textile", ".rdoc", ".pod"}
+
+// isReadmeName reports whether name is a README: "readme" in any case with a
+// known extension or none, optionally language-tagged (README.ja.md)
+func isReadmeName(name string) bool {
+	return readmeRank(name) >= 0
+}
+
+// readmeRank orders README names by readmeExtensions, untagged before
+// language-tagged, returning -1 for names that are not READMEs
+func readmeRank(name string) int {
+	lower := strings.ToLower(name)
+	stem, rest, _ := strings.Cut(lower, ".")
+	if stem != "readme" || strings.Count(rest, ".") > 1 {
+		return -1
+	}
+
+	ext := filepath.Ext(lower)
+	tagged := 0
+	if strings.Contains(rest, ".") {
+		tagged = 1
+	}
+	for i, known := range readmeExtensions {
+		if ext == known {
+			return i*2 + tagged
+		}
+	}
+	return -1
+}
+
 func findAndReadReadme(repoPath string) (string, error) {
-	readmeFiles := []string{"README.md", "README.txt", "README", "readme.md", "readme.txt", "readme"}
+	entries, err := os.ReadDir(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	var readmeFiles []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isReadmeName(entry.Name()) {
+			readmeFiles = append(readmeFiles, entry.Name())
+		}
+	}
+	sort.SliceStable(readmeFiles, func(i, j int) bool {
+		return readmeRank(readmeFiles[i]) < readmeRank(readmeFiles[j])
+	})
 
 	for _, filename := range readmeFiles {
-		path := filepath.Join(repoPath, filename)
-		if _, err := os.Stat(path); err == nil {
-			// Count total lines in the README
-			fullPath := filepath.Join(repoPath, filename)
-			_, totalLines := countFileCharacters(fullPath)
-
-			// Get content (no limit to show full README)
-			content, err := GetFileContent(repoPath, filename, 0) // 0 = no limit
-			if err == nil {
-				// If README is very long, add a note about total lines
-				if totalLines > 100 {
-					content += fmt.Sprintf("\n[README: %d lines total]\n", totalLines)
-				}
-				return content, nil
-			}
+		// Read it all; GetRepositoryInfoWithReadmePreview shortens it for display
+		content, err := GetFileContent(repoPath, filename, 0) // 0 = no limit
+		if err == nil {
+			return content, nil
 		}
 	}
 
@@ -1247,7 +3096,170 @@ func shouldSkipDirectory(dirPath string, excludePatterns []string) bool {
 	return false
 }
 
-// countFileCharacters counts characters and lines in a text file
+// GetFileMetadata returns size, mode and type information for a path without
+// reading more than the first few KB of its content
+func GetFileMetadata(repoPath, filePath string) (*FileMetadata, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	fullPath := filepath.Join(repoPath, filePath)
+	if rel, err := filepath.Rel(repoPath, fullPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path is outside the repository: %s", filePath)
+	}
+	// Resolve the parent directories but not the last component, so a
+	// symlink leaf is reported without following it
+	if err := checkRepositoryDir(repoPath, filepath.Dir(filePath)); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Lstat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %v", err)
+	}
+
+	metadata := &FileMetadata{
+		Path:         filePath,
+		Size:         info.Size(),
+		Mode:         info.Mode().String(),
+		ModTime:      info.ModTime(),
+		IsDir:        info.IsDir(),
+		IsExecutable: info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0,
+		IsSymlink:    info.Mode()&os.ModeSymlink != 0,
+	}
+
+	// Symlinks are not followed so their targets are never read
+	if metadata.IsSymlink {
+		if target, err := os.Readlink(fullPath); err == nil {
+			metadata.LinkTarget = target
+		}
+		return metadata, nil
+	}
+	if !info.Mode().IsRegular() {
+		return metadata, nil
+	}
+
+	head, err := readFileHead(fullPath, binaryCheckSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	metadata.IsBinary = bytes.IndexByte(head, 0) >= 0
+	metadata.MIMEType = detectMIMEType(filePath, head)
+
+	return metadata, nil
+}
+
+// FileStats contains text statistics for a single file
+type FileStats struct {
+	Path              string `json:"path"`
+	Bytes             int64  `json:"bytes"`
+	Lines             int    `json:"lines"`
+	Words             int    `json:"words"`
+	BlankLines        int    `json:"blank_lines"`
+	LongestLine       int    `json:"longest_line"`        // in characters, excluding the line terminator
+	LongestLineNumber int    `json:"longest_line_number"` // 1-based; 0 for an empty file
+	EndsWithNewline   bool   `json:"ends_with_newline"`
+}
+
+// GetFileStats computes text statistics for a file in a single streaming pass.
+// A final line without a trailing newline still counts as a line.
+func GetFileStats(repoPath, filePath string) (*FileStats, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	fullPath := filepath.Join(repoPath, filePath)
+	if rel, err := filepath.Rel(repoPath, fullPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path is outside the repository: %s", filePath)
+	}
+	fullPath, err = ResolveWorkspaceFile(repoPath, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	stats := &FileStats{Path: filePath}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			stats.Bytes += int64(len(line))
+			stats.Lines++
+			stats.EndsWithNewline = strings.HasSuffix(line, "\n")
+
+			text := strings.TrimRight(line, "\r\n")
+			if strings.TrimSpace(text) == "" {
+				stats.BlankLines++
+			}
+			stats.Words += len(strings.Fields(text))
+			if length := utf8.RuneCountInString(text); length > stats.LongestLine || stats.LongestLineNumber == 0 {
+				stats.LongestLine = length
+				stats.LongestLineNumber = stats.Lines
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %v", err)
+		}
+	}
+
+	return stats, nil
+}
+
+// binaryCheckSize is how much of a file is scanned for NUL bytes
+const binaryCheckSize = 8 * 1024
+
+// maxScanFileSize is the largest file that whole-file scans (linting, line
+// counting for filters) will read; bigger files are skipped
+const maxScanFileSize = 1024 * 1024
+
+// readFileHead returns up to n bytes from the start of a file
+func readFileHead(fullPath string, n int) ([]byte, error) {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// detectMIMEType guesses a MIME type from the file extension, falling back to
+// content sniffing
+func detectMIMEType(filePath string, head []byte) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(filePath)); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(head)
+}
+
+// countFileCharacters counts characters (UTF-8 runes, including line
+// terminators) and lines in a text file. A final line without a trailing
+// newline still counts as a line.
 func countFileCharacters(fullPath string) (int, int) {
 	file, err := os.Open(fullPath)
 	if err != nil {
@@ -1256,17 +3268,30 @@ func countFileCharacters(fullPath string) (int, int) {
 	defer file.Close()
 
 	var charCount, lineCount int
-	scanner := bufio.NewScanner(file)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		charCount += len(line) + 1 // +1 for newline character
-		lineCount++
+	var last byte
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := file.Read(buf)
+		for _, b := range buf[:n] {
+			// Count every byte that starts a rune (skip UTF-8 continuation bytes)
+			if b&0xC0 != 0x80 {
+				charCount++
+			}
+			if b == '\n' {
+				lineCount++
+			}
+		}
+		if n > 0 {
+			last = buf[n-1]
+		}
+		if err != nil {
+			break
+		}
 	}
 
-	// If file doesn't end with newline, don't count the extra character
-	if lineCount > 0 && charCount > 0 {
-		charCount-- // Remove the last extra newline count
+	if charCount > 0 && last != '\n' {
+		lineCount++
 	}
 
 	return charCount, lineCount
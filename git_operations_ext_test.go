@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 var testMutex = &sync.Mutex{}
@@ -133,19 +138,927 @@ func TestGetCommitDiff(t *testing.T) {
 		t.Fatalf("GetCommitDiff failed: %v", err)
 	}
 
-	if !strings.Contains(diff, "commit "+latestCommitHash) {
+	if !strings.Contains(diff.Diff, "commit "+latestCommitHash) {
 		t.Errorf("Diff output should contain the commit hash")
 	}
 
-	if !strings.Contains(diff, "Second commit") {
+	if !strings.Contains(diff.Diff, "Second commit") {
 		t.Errorf("Diff output should contain the commit message")
 	}
 
-	if !strings.Contains(diff, "diff --git a/test.txt b/test.txt") {
+	if !strings.Contains(diff.Diff, "diff --git a/test.txt b/test.txt") {
 		t.Errorf("Diff output should contain the diff header for test.txt")
 	}
 
-	if !strings.Contains(diff, "+updated content") {
+	if !strings.Contains(diff.Diff, "+updated content") {
 		t.Errorf("Diff output should show the added line")
 	}
+
+	if _, err := GetCommitDiff(repoName, "--output=zz_out"); err == nil {
+		t.Error("Expected error for a commit hash starting with -")
+	}
+
+	t.Run("stat", func(t *testing.T) {
+		repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+		if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte("line one\nline two\nline three\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, "image.bin"), []byte{0, 1, 2, 3}, 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		cmd := exec.Command("git", "add", "test.txt", "image.bin")
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Git add failed: %v\n%s", err, output)
+		}
+		cmd = exec.Command("git", "commit", "-m", "Expand test.txt")
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Git commit failed: %v\n%s", err, output)
+		}
+
+		commits, err := ListCommits(repoName, 1)
+		if err != nil || len(commits) == 0 {
+			t.Fatalf("Could not get latest commit: %v", err)
+		}
+		diff, err := GetCommitDiff(repoName, commits[0].Hash)
+		if err != nil {
+			t.Fatalf("GetCommitDiff failed: %v", err)
+		}
+
+		stat := diff.Stat
+		if stat.FilesChanged != 2 || stat.Insertions != 3 || stat.Deletions != 1 || len(stat.Files) != 2 {
+			t.Fatalf("Expected 2 files, 3 insertions and 1 deletion, got %+v", stat)
+		}
+		if stat.Files[0].Path != "image.bin" || !stat.Files[0].Binary {
+			t.Errorf("Expected image.bin reported as binary, got %+v", stat.Files[0])
+		}
+		if stat.Files[1] != (FileDiffStat{Path: "test.txt", Insertions: 3, Deletions: 1}) {
+			t.Errorf("Unexpected stat for test.txt: %+v", stat.Files[1])
+		}
+
+		result, _, err := handleGetCommitDiff(context.Background(), nil, GetCommitDiffParams{Repository: repoName, CommitHash: commits[0].Hash})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetCommitDiff failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"2 files changed, 3 insertions(+), 1 deletion(-)", " image.bin | binary", " test.txt  | +3 -1"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+}
+
+func TestDiffStatSummary(t *testing.T) {
+	tests := []struct {
+		stat DiffStat
+		want string
+	}{
+		{DiffStat{FilesChanged: 3, Insertions: 42, Deletions: 10}, "3 files changed, 42 insertions(+), 10 deletions(-)"},
+		{DiffStat{FilesChanged: 1, Insertions: 1}, "1 file changed, 1 insertion(+)"},
+		{DiffStat{FilesChanged: 1, Deletions: 2}, "1 file changed, 2 deletions(-)"},
+		{DiffStat{FilesChanged: 1}, "1 file changed, 0 insertions(+), 0 deletions(-)"},
+	}
+	for _, tt := range tests {
+		if got := tt.stat.summary(); got != tt.want {
+			t.Errorf("summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestGetCommitFilePatches(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	if err := os.MkdirAll(filepath.Join(repoPath, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	files := map[string]string{
+		"test.txt":    "third content\n",
+		"src/main.go": "package main\n",
+		"notes.md":    "# Notes\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "Multi-file commit"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	commits, err := ListCommits(repoName, 1)
+	if err != nil || len(commits) == 0 {
+		t.Fatalf("Could not get latest commit to test patches")
+	}
+
+	patches, err := GetCommitFilePatches(repoName, commits[0].Hash)
+	if err != nil {
+		t.Fatalf("GetCommitFilePatches failed: %v", err)
+	}
+	if len(patches) != len(files) {
+		t.Fatalf("Expected %d patches, got %d: %v", len(files), len(patches), patches)
+	}
+	for name, content := range files {
+		patch, ok := patches[name]
+		if !ok {
+			t.Errorf("Missing patch for %s", name)
+			continue
+		}
+		if !strings.HasPrefix(patch, "diff --git a/"+name+" b/"+name) {
+			t.Errorf("Patch for %s should start with its own header, got %q", name, patch)
+		}
+		if strings.Count(patch, "diff --git") != 1 {
+			t.Errorf("Patch for %s should contain exactly one file header", name)
+		}
+		if !strings.Contains(patch, "+"+strings.TrimSuffix(content, "\n")) {
+			t.Errorf("Patch for %s should contain its added line", name)
+		}
+	}
+
+	if _, err := GetCommitFilePatches(repoName, "xyz"); err == nil {
+		t.Error("Expected error for invalid commit hash")
+	}
+
+	// A hash that looks like an option must not reach git as one
+	if _, err := GetCommitFilePatches(repoName, "--output=zz_out"); err == nil {
+		t.Error("Expected error for a commit hash starting with -")
+	}
+	if _, err := os.Stat(filepath.Join(repoPath, "zz_out")); err == nil {
+		t.Error("Expected git not to write the --output file")
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetCommitPatches(context.Background(), nil, GetCommitPatchesParams{
+			Repository: repoName,
+			CommitHash: commits[0].Hash,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetCommitPatches failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, label := range []string{"--- notes.md ---", "--- src/main.go ---", "--- test.txt ---"} {
+			if !strings.Contains(text, label) {
+				t.Errorf("Expected section %q in output: %s", label, text)
+			}
+		}
+	})
+}
+
+func TestGetCommitDiffHunks(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// A long file whose every tenth line changes yields many separate hunks
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	var before, after strings.Builder
+	for i := 1; i <= 100; i++ {
+		before.WriteString(fmt.Sprintf("line %d\n", i))
+		if i%10 == 0 {
+			after.WriteString(fmt.Sprintf("changed line %d\n", i))
+		} else {
+			after.WriteString(fmt.Sprintf("line %d\n", i))
+		}
+	}
+	for i, content := range []string{before.String(), after.String()} {
+		if err := os.WriteFile(filepath.Join(repoPath, "big.txt"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write big.txt: %v", err)
+		}
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", fmt.Sprintf("Big file %d", i)}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repoPath
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, output)
+			}
+		}
+	}
+
+	commits, err := ListCommits(repoName, 1)
+	if err != nil || len(commits) == 0 {
+		t.Fatalf("Could not get latest commit to test hunks")
+	}
+
+	hunks, total, err := GetCommitDiffHunks(repoName, commits[0].Hash, 3, 2)
+	if err != nil {
+		t.Fatalf("GetCommitDiffHunks failed: %v", err)
+	}
+	if total != 10 {
+		t.Errorf("Expected 10 hunks in total, got %d", total)
+	}
+	if len(hunks) != 2 {
+		t.Fatalf("Expected a window of 2 hunks, got %d", len(hunks))
+	}
+	for i, hunk := range hunks {
+		if hunk.File != "big.txt" {
+			t.Errorf("Hunk %d should belong to big.txt, got %q", i, hunk.File)
+		}
+		if !strings.HasPrefix(hunk.Body, "@@ ") {
+			t.Errorf("Hunk %d should start with its @@ header, got %q", i, hunk.Body)
+		}
+	}
+	if !strings.Contains(hunks[0].Body, "+changed line 40") || !strings.Contains(hunks[1].Body, "+changed line 50") {
+		t.Errorf("Expected the 4th and 5th hunks, got %q and %q", hunks[0].Body, hunks[1].Body)
+	}
+
+	hunks, total, err = GetCommitDiffHunks(repoName, commits[0].Hash, 20, 2)
+	if err != nil || len(hunks) != 0 || total != 10 {
+		t.Errorf("Expected an empty window past the end, got %d hunks (total %d, err %v)", len(hunks), total, err)
+	}
+
+	if _, _, err := GetCommitDiffHunks(repoName, commits[0].Hash, -1, 2); err == nil {
+		t.Error("Expected error for negative hunk offset")
+	}
+	if _, _, err := GetCommitDiffHunks(repoName, "--output=zz_out", 0, 2); err == nil {
+		t.Error("Expected error for a commit hash starting with -")
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetCommitDiff(context.Background(), nil, GetCommitDiffParams{
+			Repository: repoName,
+			CommitHash: commits[0].Hash,
+			HunkOffset: 8,
+			HunkLimit:  2,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetCommitDiff failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "hunks 9-10 of 10") {
+			t.Errorf("Expected window summary in output, got: %s", text)
+		}
+		if !strings.Contains(text, "--- big.txt ---") {
+			t.Errorf("Expected file label in output, got: %s", text)
+		}
+	})
+}
+
+func TestDiffRefs(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// base gets its own change after feature branches off
+	run("branch", "base")
+	run("checkout", "-q", "-b", "feature/x")
+	write("feature.txt", "feature work\n")
+	write("other.txt", "other work\n")
+	run("add", "-A")
+	run("commit", "-m", "Feature work")
+	run("checkout", "-q", "base")
+	write("base-only.txt", "base work\n")
+	run("add", "-A")
+	run("commit", "-m", "Base work")
+
+	diff, err := DiffRefs(repoName, "base", "feature/x", nil)
+	if err != nil {
+		t.Fatalf("DiffRefs failed: %v", err)
+	}
+	if !strings.Contains(diff, "+feature work") || !strings.Contains(diff, "other.txt") {
+		t.Errorf("Diff should contain feature changes: %s", diff)
+	}
+	if strings.Contains(diff, "base-only.txt") {
+		t.Errorf("Three-dot diff should not include changes made only on base: %s", diff)
+	}
+
+	diff, err = DiffRefs(repoName, "base", "feature/x", []string{"feature.txt"})
+	if err != nil {
+		t.Fatalf("DiffRefs with paths failed: %v", err)
+	}
+	if !strings.Contains(diff, "feature.txt") || strings.Contains(diff, "other.txt") {
+		t.Errorf("Path filter not applied: %s", diff)
+	}
+
+	stat, err := DiffRefsStat(repoName, "base", "feature/x", nil)
+	if err != nil {
+		t.Fatalf("DiffRefsStat failed: %v", err)
+	}
+	if !strings.Contains(stat, "2 files changed") || strings.Contains(stat, "+feature work") {
+		t.Errorf("Unexpected stat output: %s", stat)
+	}
+
+	for _, ref := range []string{"no-such-branch", "--output=/tmp/x"} {
+		if _, err := DiffRefs(repoName, ref, "feature/x", nil); err == nil {
+			t.Errorf("Expected error for ref %q", ref)
+		}
+	}
+	if _, err := DiffRefs(repoName, "base", "missing", nil); err == nil || !strings.Contains(err.Error(), "unknown ref: missing") {
+		t.Errorf("Expected unknown ref error, got %v", err)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleDiffRefs(context.Background(), nil, DiffRefsParams{
+			Repository: repoName,
+			Base:       "base",
+			Head:       "feature/x",
+			StatOnly:   true,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleDiffRefs failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Diff base...feature/x") || !strings.Contains(text, "feature.txt") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+
+		result, _, _ = handleDiffRefs(context.Background(), nil, DiffRefsParams{Repository: repoName, Base: "base"})
+		if !result.IsError {
+			t.Error("Expected error result when head is missing")
+		}
+	})
+}
+
+func TestListCommitsWithFilter(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	tests := []struct {
+		name          string
+		filter        CommitFilter
+		expectedCount int
+		expectError   bool
+	}{
+		{name: "matching author", filter: CommitFilter{Author: "Test User"}, expectedCount: 2},
+		{name: "unknown author", filter: CommitFilter{Author: "nobody-at-all"}, expectedCount: 0},
+		{name: "relative since", filter: CommitFilter{Since: "2 weeks ago"}, expectedCount: 2},
+		{name: "absolute until in the past", filter: CommitFilter{Until: "2000-01-01"}, expectedCount: 0},
+		{name: "author and since combined", filter: CommitFilter{Author: "Test", Since: "yesterday"}, expectedCount: 2},
+		{name: "invalid since", filter: CommitFilter{Since: "not a date"}, expectError: true},
+		{name: "option-like until", filter: CommitFilter{Until: "--all"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, err := ListCommitsWithFilter(repoName, 10, tt.filter)
+			if tt.expectError {
+				if err == nil {
+					t.Fatalf("Expected error for filter %+v", tt.filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListCommitsWithFilter failed: %v", err)
+			}
+			if len(commits) != tt.expectedCount {
+				t.Errorf("Expected %d commits, got %d", tt.expectedCount, len(commits))
+			}
+		})
+	}
+}
+
+func TestFormatCommitsWithFilter(t *testing.T) {
+	commits := []Commit{{Hash: "abc123", Author: "alice", Date: "2024-01-02", Message: "Fix bug"}}
+
+	output := formatCommits(commits, 20, CommitFilter{Author: "alice", Since: "1 week ago"})
+	if !strings.Contains(output, "author=alice, since=1 week ago") {
+		t.Errorf("Expected active filters in header, got: %s", output)
+	}
+
+	output = formatCommits(commits, 20, CommitFilter{})
+	if !strings.HasPrefix(output, "Commit History (1 commits):") {
+		t.Errorf("Expected plain header without filters, got: %s", output)
+	}
+}
+
+func TestListCommitsPagination(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	for i := 1; i <= 5; i++ {
+		if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte(fmt.Sprintf("revision %d", i)), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		cmd := exec.Command("git", "commit", "-am", fmt.Sprintf("Revision %d", i))
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Git commit failed: %v\n%s", err, output)
+		}
+	}
+
+	all, err := ListCommits(repoName, 0)
+	if err != nil || len(all) != 7 {
+		t.Fatalf("Expected 7 commits, got %d (%v)", len(all), err)
+	}
+
+	// Walk the history three commits at a time
+	var paged []Commit
+	before := ""
+	for page := 1; page <= 4; page++ {
+		commits, err := ListCommitsWithFilter(repoName, 3, CommitFilter{Before: before})
+		if err != nil {
+			t.Fatalf("Page %d failed: %v", page, err)
+		}
+		paged = append(paged, commits...)
+		if len(commits) < 3 {
+			break
+		}
+		before = commits[len(commits)-1].Hash
+	}
+	if len(paged) != len(all) {
+		t.Fatalf("Expected pages to cover all %d commits, got %d", len(all), len(paged))
+	}
+	for i := range all {
+		if paged[i].Hash != all[i].Hash {
+			t.Errorf("Commit %d: expected %s, got %s (pages must be contiguous and non-overlapping)", i, all[i].Hash, paged[i].Hash)
+		}
+	}
+
+	if _, err := ListCommitsWithFilter(repoName, 3, CommitFilter{Before: "--all"}); err == nil {
+		t.Error("Expected error for option-like before")
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleListCommits(context.Background(), nil, ListCommitsParams{Repository: repoName, Limit: 3})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListCommits failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Next cursor: "+all[2].Hash) {
+			t.Errorf("Expected the third commit as next cursor:\n%s", text)
+		}
+
+		result, _, err = handleListCommits(context.Background(), nil, ListCommitsParams{Repository: repoName, Limit: 3, Before: all[5].Hash})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListCommits failed: %v %v", err, result.Content)
+		}
+		text = result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "commit "+all[6].Hash) || strings.Contains(text, "Next cursor") {
+			t.Errorf("Expected only the root commit and no cursor on the last page:\n%s", text)
+		}
+	})
+}
+
+func TestSearchCommits(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	commits, err := SearchCommits(repoName, "SECOND", 20)
+	if err != nil {
+		t.Fatalf("SearchCommits failed: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Message != "Second commit" {
+		t.Errorf("Expected case-insensitive match on 'Second commit', got %+v", commits)
+	}
+
+	commits, err = SearchCommits(repoName, "commit", 20)
+	if err != nil {
+		t.Fatalf("SearchCommits failed: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("Expected 2 commits matching 'commit', got %d", len(commits))
+	}
+
+	if _, err := SearchCommits(repoName, "  ", 20); err == nil {
+		t.Error("Expected error for empty query")
+	}
+
+	t.Run("all_match requires every term", func(t *testing.T) {
+		commits, err := ListCommitsWithFilter(repoName, 20, CommitFilter{Grep: []string{"initial", "second"}, AllMatch: true})
+		if err != nil {
+			t.Fatalf("ListCommitsWithFilter failed: %v", err)
+		}
+		if len(commits) != 0 {
+			t.Errorf("Expected no commit to contain both terms, got %d", len(commits))
+		}
+
+		commits, err = ListCommitsWithFilter(repoName, 20, CommitFilter{Grep: []string{"initial", "second"}})
+		if err != nil {
+			t.Fatalf("ListCommitsWithFilter failed: %v", err)
+		}
+		if len(commits) != 2 {
+			t.Errorf("Expected either term to match 2 commits, got %d", len(commits))
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleSearchCommits(context.Background(), nil, SearchCommitsParams{
+			Repository: repoName,
+			Query:      "second commit",
+			AllMatch:   true,
+		})
+		if err != nil {
+			t.Fatalf("Handler returned unexpected error: %v", err)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if result.IsError {
+			t.Fatalf("Expected success, got: %s", text)
+		}
+		if !strings.Contains(text, "Second commit") || strings.Contains(text, "Initial commit") {
+			t.Errorf("Unexpected search output: %s", text)
+		}
+		if !strings.Contains(text, `message="second" AND "commit"`) {
+			t.Errorf("Expected header to describe the search, got: %s", text)
+		}
+	})
+}
+
+func TestGetStatus(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	status, err := GetStatus(repoName)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if !status.Clean {
+		t.Errorf("Expected a clean working tree, got %+v", status)
+	}
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	for name, content := range map[string]string{"notes/new.txt": "scratch", "test.txt": "edited", "staged.txt": "staged"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	cmd := exec.Command("git", "add", "staged.txt")
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Git add failed: %v\n%s", err, output)
+	}
+
+	status, err = GetStatus(repoName)
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Clean {
+		t.Error("Expected a dirty working tree")
+	}
+	if len(status.Untracked) != 1 || status.Untracked[0] != "notes/new.txt" {
+		t.Errorf("Expected notes/new.txt as untracked, got %v", status.Untracked)
+	}
+	if len(status.Modified) != 1 || status.Modified[0] != "test.txt" {
+		t.Errorf("Expected test.txt as modified, got %v", status.Modified)
+	}
+	if len(status.Staged) != 1 || status.Staged[0] != "staged.txt" {
+		t.Errorf("Expected staged.txt as staged, got %v", status.Staged)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetStatus(context.Background(), nil, GetStatusParams{Repository: repoName})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetStatus failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"Staged (1):\n  staged.txt", "Modified (1):\n  test.txt", "Untracked (1):\n  notes/new.txt"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+}
+
+func TestListRemotes(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	remotes, err := ListRemotes(repoName)
+	if err != nil {
+		t.Fatalf("ListRemotes failed: %v", err)
+	}
+	if len(remotes) != 0 {
+		t.Errorf("Expected no remotes, got %+v", remotes)
+	}
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	for _, args := range [][]string{
+		{"remote", "add", "origin", "https://github.com/me/repo.git"},
+		{"remote", "add", "upstream", "https://github.com/org/repo.git"},
+		{"remote", "set-url", "--push", "upstream", "no_push"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	remotes, err = ListRemotes(repoName)
+	if err != nil {
+		t.Fatalf("ListRemotes failed: %v", err)
+	}
+	expected := []Remote{
+		{Name: "origin", FetchURL: "https://github.com/me/repo.git", PushURL: "https://github.com/me/repo.git"},
+		{Name: "upstream", FetchURL: "https://github.com/org/repo.git", PushURL: "no_push"},
+	}
+	if fmt.Sprint(remotes) != fmt.Sprint(expected) {
+		t.Errorf("Expected %+v, got %+v", expected, remotes)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleListRemotes(context.Background(), nil, ListRemotesParams{Repository: repoName})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListRemotes failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"(2):", "  origin: https://github.com/me/repo.git\n", "  upstream: https://github.com/org/repo.git (push: no_push)\n"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("repository info", func(t *testing.T) {
+		result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repoName, IncludeRemotes: true})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Remotes:\n  origin: https://github.com/me/repo.git\n  upstream: https://github.com/org/repo.git (push: no_push)\n") {
+			t.Errorf("Expected both remotes in repository info:\n%s", text)
+		}
+	})
+}
+
+func TestWorktreeRepository(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	// A worktree has a .git file ("gitdir: .../.git/worktrees/wt") instead of a directory
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	wtPath := GetWorkspaceManager().GetRepositoryPath("wt")
+	cmd := exec.Command("git", "worktree", "add", "-b", "wt", wtPath)
+	cmd.Dir = repoPath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git worktree add failed: %v\n%s", err, output)
+	}
+	if stat, err := os.Stat(filepath.Join(wtPath, ".git")); err != nil || stat.IsDir() {
+		t.Fatalf("Expected .git to be a file in the worktree: %v", err)
+	}
+
+	if !isGitRepository(wtPath) {
+		t.Errorf("Expected the worktree to be recognized as a git repository")
+	}
+
+	repositories, err := GetWorkspaceManager().ListRepositories()
+	if err != nil {
+		t.Fatalf("ListRepositories failed: %v", err)
+	}
+	if !slices.Contains(repositories, "wt") {
+		t.Errorf("Expected the worktree in %v", repositories)
+	}
+
+	info, err := GetRepositoryInfo("wt")
+	if err != nil {
+		t.Fatalf("GetRepositoryInfo failed: %v", err)
+	}
+	if info.CurrentBranch != "wt" {
+		t.Errorf("Expected branch wt, got %q", info.CurrentBranch)
+	}
+}
+
+func TestListSubmodules(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	submodules, err := ListSubmodules(repoName)
+	if err != nil {
+		t.Fatalf("ListSubmodules failed: %v", err)
+	}
+	if submodules == nil || len(submodules) != 0 {
+		t.Errorf("Expected an empty list, got %#v", submodules)
+	}
+
+	dep := CreateTestRepositoryWithContent(t)
+	output, err := exec.Command("git", "-C", dep.Path, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("Failed to resolve dependency HEAD: %v", err)
+	}
+	depHead := strings.TrimSpace(string(output))
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	for _, args := range [][]string{
+		{"-c", "protocol.file.allow=always", "submodule", "add", dep.Path, "libs/dep"},
+		{"commit", "-m", "Add dep submodule"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	// A declared but never cloned submodule
+	gitmodules := "[submodule \"docs.theme\"]\n\tpath = docs/theme\n\turl = https://github.com/org/theme.git\n"
+	f, err := os.OpenFile(filepath.Join(repoPath, ".gitmodules"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open .gitmodules: %v", err)
+	}
+	f.WriteString(gitmodules)
+	f.Close()
+
+	submodules, err = ListSubmodules(repoName)
+	if err != nil {
+		t.Fatalf("ListSubmodules failed: %v", err)
+	}
+	expected := []Submodule{
+		{Path: "libs/dep", URL: dep.Path, Commit: depHead, Initialized: true},
+		{Path: "docs/theme", URL: "https://github.com/org/theme.git"},
+	}
+	if fmt.Sprint(submodules) != fmt.Sprint(expected) {
+		t.Errorf("Expected %+v, got %+v", expected, submodules)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleListSubmodules(context.Background(), nil, ListSubmodulesParams{Repository: repoName})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListSubmodules failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"(2):", "  libs/dep @ " + depHead[:7] + ": " + dep.Path + "\n", "  docs/theme @ : https://github.com/org/theme.git (not initialized)\n"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("repository info", func(t *testing.T) {
+		result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repoName})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Submodules: 2 ") {
+			t.Errorf("Expected the submodule count in repository info:\n%s", text)
+		}
+	})
+}
+
+func TestCompareBranches(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commitFile := func(name, message string) {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(message), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		run("add", name)
+		run("commit", "-m", message)
+	}
+
+	mainBranch := run("rev-parse", "--abbrev-ref", "HEAD")
+	forkPoint := run("rev-parse", "HEAD")
+
+	// feature gets two commits, the main branch one, after they diverge
+	run("checkout", "-b", "feature")
+	commitFile("feature1.txt", "Feature one")
+	commitFile("feature2.txt", "Feature two")
+	run("checkout", mainBranch)
+	commitFile("main.txt", "Main fix")
+
+	comparison, err := CompareBranches(repoName, "feature", mainBranch)
+	if err != nil {
+		t.Fatalf("CompareBranches failed: %v", err)
+	}
+	if comparison.MergeBase != forkPoint {
+		t.Errorf("Expected merge base %s, got %s", forkPoint, comparison.MergeBase)
+	}
+	if len(comparison.Ahead) != 2 || comparison.Ahead[0].Message != "Feature two" || comparison.Ahead[1].Message != "Feature one" {
+		t.Errorf("Expected the two feature commits ahead, got %+v", comparison.Ahead)
+	}
+	if len(comparison.Behind) != 1 || comparison.Behind[0].Message != "Main fix" {
+		t.Errorf("Expected the main commit behind, got %+v", comparison.Behind)
+	}
+
+	if _, err := CompareBranches(repoName, "feature", "no-such-branch"); err == nil {
+		t.Error("Expected error for a missing ref")
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleCompareBranches(context.Background(), nil, CompareBranchesParams{Repository: repoName, A: "feature", B: mainBranch, Limit: 1})
+		if err != nil || result.IsError {
+			t.Fatalf("handleCompareBranches failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"feature is ahead by 2 commits, behind by 1", "Merge base: " + forkPoint, "Only on feature (2):", "Feature two", "... and 1 more", "Main fix"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+}
+
+func TestDiffWorkingFileAgainst(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+
+	diff, err := DiffWorkingFileAgainst(repoName, "test.txt", "HEAD")
+	if err != nil {
+		t.Fatalf("DiffWorkingFileAgainst failed: %v", err)
+	}
+	if strings.TrimSpace(diff) != "" {
+		t.Errorf("Expected no diff for unmodified file, got: %s", diff)
+	}
+
+	if err := os.WriteFile(filepath.Join(repoPath, "test.txt"), []byte("locally edited"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+
+	diff, err = DiffWorkingFileAgainst(repoName, "test.txt", "HEAD")
+	if err != nil {
+		t.Fatalf("DiffWorkingFileAgainst failed: %v", err)
+	}
+	if !strings.Contains(diff, "-updated content") || !strings.Contains(diff, "+locally edited") {
+		t.Errorf("Expected working tree change in diff, got: %s", diff)
+	}
+
+	diff, err = DiffWorkingFileAgainst(repoName, "test.txt", "HEAD~1")
+	if err != nil {
+		t.Fatalf("DiffWorkingFileAgainst failed: %v", err)
+	}
+	if !strings.Contains(diff, "-initial content") || !strings.Contains(diff, "+locally edited") {
+		t.Errorf("Expected diff against first commit, got: %s", diff)
+	}
+
+	for _, tc := range []struct{ file, ref string }{
+		{"test.txt", "no-such-ref"},
+		{"test.txt", "--output=/tmp/x"},
+		{"missing.txt", "HEAD"},
+	} {
+		if _, err := DiffWorkingFileAgainst(repoName, tc.file, tc.ref); err == nil {
+			t.Errorf("Expected error for file %q ref %q", tc.file, tc.ref)
+		}
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleDiffFile(context.Background(), nil, DiffFileParams{Repository: repoName, FilePath: "test.txt"})
+		if err != nil || result.IsError {
+			t.Fatalf("handleDiffFile failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "working tree vs HEAD") || !strings.Contains(text, "+locally edited") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
+
+func TestFilesDifferingFromBranch(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	// Modify a file on develop, then return to main
+	repo.runGitCommand("checkout", "develop")
+	repo.WriteFile("main.go", "package main\n\nfunc main() {}\n")
+	repo.runGitCommand("commit", "-am", "Simplify main")
+	repo.runGitCommand("checkout", "main")
+
+	files, err := FilesDifferingFromBranch(repo.Path, "develop")
+	if err != nil {
+		t.Fatalf("FilesDifferingFromBranch failed: %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, f := range files {
+		statuses[f.Path] = f.Status
+	}
+	if statuses["main.go"] != "M" {
+		t.Errorf("Expected main.go modified, got %q (%v)", statuses["main.go"], files)
+	}
+	// version.txt was only committed on main, so it exists only in the working tree
+	if statuses["version.txt"] != "A" {
+		t.Errorf("Expected version.txt added, got %q (%v)", statuses["version.txt"], files)
+	}
+	if _, ok := statuses["README.md"]; ok {
+		t.Errorf("README.md is identical on both branches and should not be listed")
+	}
+	if current := repo.getCurrentBranch(); current != "main" {
+		t.Errorf("Expected to stay on main, got %s", current)
+	}
+
+	for _, branch := range []string{"no-such-branch", "--output=/tmp/x", ""} {
+		if _, err := FilesDifferingFromBranch(repo.Path, branch); err == nil {
+			t.Errorf("Expected error for branch %q", branch)
+		}
+	}
 }
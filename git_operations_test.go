@@ -1,8 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestGetRepositoryInfo(t *testing.T) {
@@ -143,6 +152,52 @@ func TestListBranches(t *testing.T) {
 	}
 }
 
+func TestListBranchesWithTracking(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	// develop gets one commit of its own; main has two commits develop lacks
+	repo.SwitchBranch("develop")
+	repo.WriteFile("develop.txt", "develop only")
+	repo.AddCommit("Develop work")
+	repo.SwitchBranch("main")
+
+	branches, err := ListBranchesWithTracking(repo.Path, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	byName := make(map[string]Branch)
+	for _, branch := range branches {
+		byName[branch.Name] = branch
+	}
+
+	if b := byName["develop"]; !b.HasTracking || b.Ahead != 1 || b.Behind != 2 {
+		t.Errorf("Expected develop ahead 1, behind 2, got %+v", b)
+	}
+	if b := byName["feature/test"]; !b.HasTracking || b.Ahead != 0 || b.Behind != 2 {
+		t.Errorf("Expected feature/test ahead 0, behind 2, got %+v", b)
+	}
+	if b := byName["main"]; b.HasTracking {
+		t.Errorf("Current branch should not carry tracking info, got %+v", b)
+	}
+
+	output := formatBranches(branches, false)
+	if !strings.Contains(output, "develop (ahead 1, behind 2)") {
+		t.Errorf("Expected ahead/behind in output: %s", output)
+	}
+
+	// The default path does not compute tracking info
+	branches, err = ListBranches(repo.Path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, branch := range branches {
+		if branch.HasTracking {
+			t.Errorf("Expected no tracking info without include_tracking, got %+v", branch)
+		}
+	}
+}
+
 func TestSwitchBranch(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -165,7 +220,7 @@ func TestSwitchBranch(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := CreateTestRepositoryWithContent(t)
 
-			output, err := SwitchBranch(repo.Path, tt.targetBranch)
+			output, err := SwitchBranch(repo.Path, tt.targetBranch, false)
 
 			if tt.expectError {
 				if err == nil {
@@ -203,6 +258,58 @@ func TestSwitchBranch(t *testing.T) {
 	}
 }
 
+func TestSwitchBranchDirtyWorkingTree(t *testing.T) {
+	t.Run("clean switch", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+
+		if _, err := SwitchBranch(repo.Path, "develop", false); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if current := repo.getCurrentBranch(); current != "develop" {
+			t.Errorf("Expected current branch develop, got %s", current)
+		}
+	})
+
+	t.Run("dirty tree is refused", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+		repo.WriteFile("README.md", "local edit\n")
+
+		output, err := SwitchBranch(repo.Path, "develop", false)
+		if err == nil {
+			t.Fatal("Expected error for dirty working tree")
+		}
+		if !strings.Contains(err.Error(), "uncommitted changes") {
+			t.Errorf("Expected uncommitted changes error, got: %v", err)
+		}
+		if !strings.Contains(output, "README.md") {
+			t.Errorf("Expected changed path in output, got: %q", output)
+		}
+		if current := repo.getCurrentBranch(); current != "main" {
+			t.Errorf("Expected to stay on main, got %s", current)
+		}
+		repo.AssertFileContent("README.md", "local edit\n")
+	})
+
+	t.Run("dirty tree with force", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+		repo.WriteFile("README.md", "local edit\n")
+
+		if _, err := SwitchBranch(repo.Path, "develop", true); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if current := repo.getCurrentBranch(); current != "develop" {
+			t.Errorf("Expected current branch develop, got %s", current)
+		}
+		content, err := os.ReadFile(filepath.Join(repo.Path, "README.md"))
+		if err != nil {
+			t.Fatalf("Failed to read README.md: %v", err)
+		}
+		if string(content) == "local edit\n" {
+			t.Errorf("Expected forced switch to discard the local edit")
+		}
+	})
+}
+
 func TestSearchFiles(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -245,7 +352,7 @@ func TestSearchFiles(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := CreateTestRepositoryWithContent(t)
 
-			results, err := SearchFiles(repo.Path, tt.keywords, "and", false, 0, nil, nil, tt.maxResults)
+			results, err := SearchFiles(repo.Path, tt.keywords, "and", false, 0, nil, nil, nil, tt.maxResults)
 
 			if tt.expectError {
 				if err == nil {
@@ -280,6 +387,179 @@ func TestSearchFiles(t *testing.T) {
 	}
 }
 
+func TestSearchFilesAndMode(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("notes.txt", "database only\n")
+	repo.AddCommit("Add notes")
+
+	tests := []struct {
+		name     string
+		keywords []string
+		expected []string
+	}{
+		{"all keywords in one file", []string{"database", "redis"}, []string{"config.json"}},
+		{"later keywords ignore case", []string{"database", "REDIS"}, []string{"config.json"}},
+		{"missing keyword", []string{"database", "nonexistent"}, nil},
+		{"single keyword", []string{"database"}, []string{"config.json", "notes.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := SearchFiles(repo.Path, tt.keywords, "and", false, 0, nil, nil, nil, 0)
+			if err != nil {
+				t.Fatalf("SearchFiles failed: %v", err)
+			}
+			var paths []string
+			for _, result := range results {
+				paths = append(paths, result.Path)
+			}
+			sort.Strings(paths)
+			if strings.Join(paths, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
+
+func TestSearchFilesContextLines(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	for _, keywords := range [][]string{{"Add"}, {"Add", "Multiply"}} {
+		t.Run(strings.Join(keywords, "+"), func(t *testing.T) {
+			results, err := SearchFiles(repo.Path, keywords, "and", false, 2, nil, nil, nil, 0)
+			if err != nil {
+				t.Fatalf("SearchFiles failed: %v", err)
+			}
+			if len(results) != 1 || results[0].Path != "src/utils.go" || len(results[0].Matches) != 1 {
+				t.Fatalf("Expected one match in src/utils.go, got %+v", results)
+			}
+
+			match := results[0].Matches[0]
+			if match.LineNumber != 3 || match.Content != "func Add(a, b int) int {" {
+				t.Errorf("Unexpected match: %+v", match)
+			}
+			expected := []string{"package src", "", "\treturn a + b", "}"}
+			if strings.Join(match.Context, "|") != strings.Join(expected, "|") {
+				t.Errorf("Expected context %q, got %q", expected, match.Context)
+			}
+		})
+	}
+
+	t.Run("no context requested", func(t *testing.T) {
+		results, err := SearchFiles(repo.Path, []string{"Add", "Multiply"}, "and", false, 0, nil, nil, nil, 0)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("SearchFiles failed: %v %+v", err, results)
+		}
+		if context := results[0].Matches[0].Context; context != nil {
+			t.Errorf("Expected no context, got %q", context)
+		}
+	})
+}
+
+func TestSearchFilesPhrase(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("logs/near.log", "dial tcp 10.0.0.1:5432: connection refused\n")
+	repo.WriteFile("logs/far.log", "connection opened\nrequest refused\n")
+	repo.WriteFile("notes/regex.txt", "price [USD] is $5.00*\npriceX[USD] is $5000\n")
+	repo.AddCommit("Add logs")
+
+	paths := func(results []SearchResult) string {
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Path)
+		}
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name       string
+		keywords   []string
+		searchMode string
+		want       string
+	}{
+		{"and matches words apart", []string{"connection", "refused"}, "and", "logs/far.log,logs/near.log"},
+		{"phrase needs them together", []string{"connection", "refused"}, "phrase", "logs/near.log"},
+		{"phrase is literal", []string{"[USD]", "is", "$5.00*"}, "phrase", "notes/regex.txt"},
+		{"phrase with regex characters misses", []string{"price.[USD]"}, "phrase", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := SearchFiles(repo.Path, tt.keywords, tt.searchMode, false, 0, nil, nil, nil, 0)
+			if err != nil {
+				t.Fatalf("SearchFiles failed: %v", err)
+			}
+			if got := paths(results); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("matched line", func(t *testing.T) {
+		results, _ := SearchFiles(repo.Path, []string{"[USD]", "is", "$5.00*"}, "phrase", false, 0, nil, nil, nil, 0)
+		if len(results) != 1 || len(results[0].Matches) != 1 || results[0].Matches[0].LineNumber != 1 {
+			t.Errorf("Expected only line 1 to match, got %+v", results)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleSearchFiles(context.Background(), nil, SearchFilesParams{Repository: repo.Path, Keywords: []string{"connection", "refused"}, SearchMode: "phrase"})
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, `Search Results for: "connection refused" (1 files found)`) {
+			t.Errorf("Expected the phrase in the header:\n%s", text)
+		}
+
+		result, _, _ = handleSearchFiles(context.Background(), nil, SearchFilesParams{Repository: repo.Path, Keywords: []string{"x"}, SearchMode: "regex"})
+		if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "unknown search_mode") {
+			t.Errorf("Expected an unknown search_mode error, got %v", result.Content)
+		}
+	})
+}
+
+func TestSearchFilesExcludeKeywords(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("src/a_test.go", "package src\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n")
+	repo.AddCommit("Add test file")
+
+	paths := func(results []SearchResult) string {
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Path)
+		}
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		name       string
+		keywords   []string
+		searchMode string
+		exclude    []string
+		maxResults int
+		want       string
+	}{
+		{"no exclusion", []string{"func"}, "and", nil, 0, "main.go,src/a_test.go,src/utils.go"},
+		{"exclude test files", []string{"func"}, "and", []string{"test"}, 0, "main.go,src/utils.go"},
+		{"exclude applies before limit", []string{"func"}, "and", []string{"TEST"}, 2, "main.go,src/utils.go"},
+		{"any exclude keyword drops the file", []string{"func"}, "and", []string{"nomatch", "Println"}, 0, "src/a_test.go,src/utils.go"},
+		{"or mode", []string{"Multiply", "TestAdd"}, "or", []string{"testing"}, 0, "src/utils.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := SearchFiles(repo.Path, tt.keywords, tt.searchMode, false, 0, nil, nil, tt.exclude, tt.maxResults)
+			if err != nil {
+				t.Fatalf("SearchFiles failed: %v", err)
+			}
+			if got := paths(results); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestListFiles(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -360,6 +640,242 @@ func TestListFiles(t *testing.T) {
 	}
 }
 
+func TestListFilesOrdering(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("a.txt", "a")
+	repo.WriteFile("zeta/inner.txt", "inner")
+	repo.WriteFile("src/b.go", "package src")
+	repo.WriteFile("src/sub/c.go", "package sub")
+	repo.AddCommit("Add ordering fixtures")
+
+	paths := func(files []FileInfo) []string {
+		var result []string
+		for _, file := range files {
+			result = append(result, filepath.ToSlash(file.Path))
+		}
+		return result
+	}
+
+	files, err := ListFiles(repo.Path, ".", true, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{
+		"docs/api.md",
+		"src/sub/c.go",
+		"src/b.go",
+		"src/utils.go",
+		"zeta/inner.txt",
+		"LICENSE",
+		"README.md",
+		"a.txt",
+		"config.json",
+		"main.go",
+		"version.txt",
+	}
+	if strings.Join(paths(files), ",") != strings.Join(expected, ",") {
+		t.Errorf("Unexpected recursive order:\n got: %v\nwant: %v", paths(files), expected)
+	}
+
+	// The limit applies after sorting, so pages are stable
+	files, err = ListFiles(repo.Path, ".", true, nil, nil, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(paths(files), ",") != strings.Join(expected[:3], ",") {
+		t.Errorf("Expected first 3 sorted entries, got %v", paths(files))
+	}
+
+	files, err = ListFiles(repo.Path, "src", false, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(paths(files), ",") != "src/b.go,src/utils.go" {
+		t.Errorf("Unexpected non-recursive order: %v", paths(files))
+	}
+}
+
+func TestListFilesMaxDepth(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	deep := "deep"
+	for i := 1; i <= maxListDepth+5; i++ {
+		deep += fmt.Sprintf("/d%d", i)
+		repo.WriteFile(deep+"/file.txt", "content")
+	}
+	repo.AddCommit("Add deep structure")
+
+	countUnder := func(files []FileInfo, dir string) int {
+		count := 0
+		for _, file := range files {
+			if strings.HasPrefix(filepath.ToSlash(file.Path), dir+"/") {
+				count++
+			}
+		}
+		return count
+	}
+
+	files, truncated, err := ListFilesWithDepth(repo.Path, "deep", true, nil, nil, 0, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// deep/d1/file.txt and deep/d1/d2/file.txt are within 3 levels of deep
+	if !truncated || countUnder(files, "deep") != 2 {
+		t.Errorf("Expected 2 files and truncation at depth 3, got %d files (truncated=%v)", len(files), truncated)
+	}
+
+	files, truncated, err = ListFilesWithDepth(repo.Path, "deep", true, nil, nil, 0, 1)
+	if err != nil || !truncated || len(files) != 0 {
+		t.Errorf("Expected no files directly in deep, got %d files (truncated=%v, err=%v)", len(files), truncated, err)
+	}
+
+	// Without a limit the walk still stops at maxListDepth
+	files, truncated, err = ListFilesWithDepth(repo.Path, "deep", true, nil, nil, 0, 0)
+	if err != nil || !truncated || countUnder(files, "deep") != maxListDepth-1 {
+		t.Errorf("Expected %d files capped at depth %d, got %d (truncated=%v, err=%v)", maxListDepth-1, maxListDepth, len(files), truncated, err)
+	}
+
+	// A shallow tree is not reported as truncated
+	_, truncated, err = ListFilesWithDepth(repo.Path, "src", true, nil, nil, 0, 5)
+	if err != nil || truncated {
+		t.Errorf("Expected no truncation for src, got truncated=%v err=%v", truncated, err)
+	}
+
+	result, _, err := handleListFiles(context.Background(), nil, ListFilesParams{Repository: repo.Path, Directory: "deep", Recursive: true, MaxDepth: 2})
+	if err != nil || result.IsError {
+		t.Fatalf("handleListFiles failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "deep/d1/file.txt") || strings.Contains(text, "d2/file.txt") || !strings.Contains(text, "(Stopped at depth 2;") {
+		t.Errorf("Expected listing cut off at depth 2 with a note:\n%s", text)
+	}
+
+	result, _, _ = handleListFiles(context.Background(), nil, ListFilesParams{Repository: repo.Path, Recursive: true, MaxDepth: -1})
+	if !result.IsError {
+		t.Error("Expected an error for a negative max_depth")
+	}
+}
+
+func TestListFilesNonGitDirectory(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	// A plain directory next to the repository, inside the same workspace
+	plainDir := filepath.Join(filepath.Dir(repo.Path), "plain-dir")
+	if err := os.MkdirAll(plainDir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(plainDir, "file.txt"), []byte("content"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	_, err := ListFiles(plainDir, ".", false, nil, nil, 50)
+	if err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Errorf("Expected 'not a git repository' error, got %v", err)
+	}
+}
+
+func TestFilterFilesByMetrics(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("metrics/short.txt", strings.Repeat("line\n", 5))
+	repo.WriteFile("metrics/medium.txt", strings.Repeat("line\n", 50))
+	repo.WriteFile("metrics/long.txt", strings.Repeat("line\n", 600))
+	repo.WriteFile("metrics/nested/long.go", strings.Repeat("// comment\n", 700))
+	repo.WriteFile("metrics/blob.bin", "\x00"+strings.Repeat("\n", 800))
+	repo.AddCommit("Add metric fixtures")
+
+	pathsOf := func(files []FileInfo) string {
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return strings.Join(paths, ",")
+	}
+
+	tests := []struct {
+		name               string
+		minLines, maxLines int
+		minBytes, maxBytes int64
+		recursive          bool
+		expected           string
+	}{
+		{"over 500 lines", 500, 0, 0, 0, false, "metrics/long.txt"},
+		{"over 500 lines recursive", 500, 0, 0, 0, true, "metrics/nested/long.go,metrics/long.txt"},
+		{"line range", 10, 100, 0, 0, false, "metrics/medium.txt"},
+		{"at most 10 lines", 0, 10, 0, 0, false, "metrics/short.txt"},
+		{"byte range", 0, 0, 100, 1000, false, "metrics/blob.bin,metrics/medium.txt"},
+		{"lines and bytes", 1, 0, 0, 100, false, "metrics/short.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := FilterFilesByMetrics(repo.Path, "metrics", tt.minLines, tt.maxLines, tt.minBytes, tt.maxBytes, tt.recursive)
+			if err != nil {
+				t.Fatalf("FilterFilesByMetrics failed: %v", err)
+			}
+			if got := pathsOf(files); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("line counts reported", func(t *testing.T) {
+		files, err := FilterFilesByMetrics(repo.Path, "metrics", 500, 0, 0, 0, false)
+		if err != nil || len(files) != 1 {
+			t.Fatalf("Expected one file, got %v (err %v)", files, err)
+		}
+		if files[0].LineCount != 600 || files[0].Size != 3000 {
+			t.Errorf("Expected 600 lines and 3000 bytes, got %d lines and %d bytes", files[0].LineCount, files[0].Size)
+		}
+	})
+
+	t.Run("invalid thresholds", func(t *testing.T) {
+		if _, err := FilterFilesByMetrics(repo.Path, ".", 100, 10, 0, 0, false); err == nil {
+			t.Error("Expected error when min_lines exceeds max_lines")
+		}
+		if _, err := FilterFilesByMetrics(repo.Path, ".", -1, 0, 0, 0, false); err == nil {
+			t.Error("Expected error for negative threshold")
+		}
+	})
+
+	t.Run("outside the repository", func(t *testing.T) {
+		outside := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "host.txt"), []byte(strings.Repeat("line\n", 20)), 0644); err != nil {
+			t.Fatalf("Failed to write outside file: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(repo.Path, "ext")); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+
+		for _, dir := range []string{"ext", "ext/", "../.."} {
+			for _, recursive := range []bool{false, true} {
+				if files, err := FilterFilesByMetrics(repo.Path, dir, 0, 0, 0, 0, recursive); err == nil {
+					t.Errorf("Expected %q (recursive=%v) to be refused, got %v", dir, recursive, files)
+				}
+			}
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleFilterFiles(context.Background(), nil, FilterFilesParams{
+			Repository: repo.Path,
+			Directory:  "metrics",
+			Recursive:  true,
+			MinLines:   500,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleFilterFiles failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "metrics/long.txt (2.9KB, 600L)") || strings.Contains(text, "medium.txt") {
+			t.Errorf("Unexpected filter output:\n%s", text)
+		}
+
+		result, _, _ = handleFilterFiles(context.Background(), nil, FilterFilesParams{Repository: repo.Path})
+		if !result.IsError {
+			t.Error("Expected error when no threshold is given")
+		}
+	})
+}
+
 func TestGetFileContent(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -414,6 +930,319 @@ func TestGetFileContent(t *testing.T) {
 	}
 }
 
+func TestFetchCitations(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	results, err := FetchCitations(repo.Path, []CitationRequest{
+		{FilePath: "main.go", StartLine: 5, EndLine: 7},
+		{FilePath: "src/utils.go", StartLine: 3, EndLine: 3},
+		{FilePath: "config.json", StartLine: 2, EndLine: 3},
+		{FilePath: "main.go", StartLine: 5, EndLine: 99},
+	})
+	if err != nil {
+		t.Fatalf("FetchCitations failed: %v", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+
+	expected := []string{
+		"   5: func main() {\n   6: \tfmt.Println(\"Hello, World!\")\n   7: }\n",
+		"   3: func Add(a, b int) int {\n",
+		"   2: \t\"database\": \"postgres\",\n   3: \t\"redis\": true,\n",
+	}
+	for i, want := range expected {
+		if results[i].Error != "" {
+			t.Errorf("Citation %d failed: %s", i, results[i].Error)
+			continue
+		}
+		if results[i].Content != want {
+			t.Errorf("Citation %d: expected %q, got %q", i, want, results[i].Content)
+		}
+	}
+	if results[3].Error == "" || !strings.Contains(results[3].Error, "beyond the end of the file") {
+		t.Errorf("Expected out-of-range error for the last citation, got %+v", results[3])
+	}
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		results, err := FetchCitations(repo.Path, []CitationRequest{
+			{FilePath: "main.go", StartLine: 0, EndLine: 2},
+			{FilePath: "main.go", StartLine: 4, EndLine: 2},
+			{FilePath: "missing.go", StartLine: 1, EndLine: 1},
+			{FilePath: "../outside.go", StartLine: 1, EndLine: 1},
+		})
+		if err != nil {
+			t.Fatalf("FetchCitations failed: %v", err)
+		}
+		for i, result := range results {
+			if result.Error == "" {
+				t.Errorf("Citation %d: expected an error, got content %q", i, result.Content)
+			}
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleFetchCitations(context.Background(), nil, FetchCitationsParams{
+			Repository: repo.Path,
+			Citations: []CitationRequest{
+				{FilePath: "main.go", StartLine: 1, EndLine: 1},
+				{FilePath: "main.go", StartLine: 50, EndLine: 60},
+			},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleFetchCitations failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "[main.go L1-1/7]\n   1: package main\n") || !strings.Contains(text, "[main.go ERR:") {
+			t.Errorf("Unexpected citation output:\n%s", text)
+		}
+	})
+}
+
+func TestGetFileRanges(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	var lines []string
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	repo.WriteFile("long.txt", strings.Join(lines, "\n")+"\n")
+
+	t.Run("disjoint ranges", func(t *testing.T) {
+		result, _, err := handleGetFileRanges(context.Background(), nil, GetFileRangesParams{
+			Repository: repo.Path,
+			FilePath:   "long.txt",
+			Ranges:     []LineRange{{Start: 80, End: 82}, {Start: 10, End: 11}},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetFileRanges failed: %v %v", err, result.Content)
+		}
+		want := "[long.txt L10-11/100]\n  10: line 10\n  11: line 11\n" +
+			"\n[long.txt L80-82/100]\n  80: line 80\n  81: line 81\n  82: line 82\n"
+		if text := result.Content[0].(*mcp.TextContent).Text; text != want {
+			t.Errorf("Expected both ranges in order:\n%q\ngot:\n%q", want, text)
+		}
+	})
+
+	t.Run("merging", func(t *testing.T) {
+		merged, err := mergeLineRanges([]LineRange{{Start: 15, End: 20}, {Start: 1, End: 5}, {Start: 10, End: 16}, {Start: 6, End: 8}, {Start: 1, End: 5}, {Start: 40, End: 40}})
+		if err != nil {
+			t.Fatalf("mergeLineRanges failed: %v", err)
+		}
+		want := []LineRange{{Start: 1, End: 8}, {Start: 10, End: 20}, {Start: 40, End: 40}}
+		if !slices.Equal(merged, want) {
+			t.Errorf("Expected %v, got %v", want, merged)
+		}
+
+		results, err := GetFileRanges(repo.Path, "long.txt", []LineRange{{Start: 3, End: 5}, {Start: 4, End: 6}, {Start: 98, End: 150}})
+		if err != nil {
+			t.Fatalf("GetFileRanges failed: %v", err)
+		}
+		if len(results) != 2 || results[0].StartLine != 3 || results[0].EndLine != 6 || results[1].StartLine != 98 || results[1].EndLine != 100 {
+			t.Errorf("Expected 3-6 and 98-100 (clamped), got %+v", results)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for name, ranges := range map[string][]LineRange{
+			"inverted":     {{Start: 10, End: 20}, {Start: 30, End: 25}},
+			"zero start":   {{Start: 0, End: 3}},
+			"past the end": {{Start: 101, End: 105}},
+			"no ranges":    nil,
+		} {
+			if _, err := GetFileRanges(repo.Path, "long.txt", ranges); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+		if _, err := GetFileRanges(repo.Path, "../outside.txt", []LineRange{{Start: 1, End: 1}}); err == nil {
+			t.Error("Expected an error for a path outside the repository")
+		}
+
+		result, _, _ := handleGetFileRanges(context.Background(), nil, GetFileRangesParams{
+			Repository: repo.Path,
+			FilePath:   "long.txt",
+			Ranges:     []LineRange{{Start: 30, End: 25}},
+		})
+		if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "range 30-25 is inverted") {
+			t.Errorf("Expected an inverted range error, got %v", result.Content)
+		}
+	})
+}
+
+func TestGetFilePage(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	var lines []string
+	for i := 1; i <= 250; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	repo.WriteFile("big.txt", strings.Join(lines, "\n")+"\n")
+	repo.AddCommit("Add 250-line file")
+
+	page, err := GetFilePage(repo.Path, "big.txt", 3, 100)
+	if err != nil {
+		t.Fatalf("GetFilePage failed: %v", err)
+	}
+	if page.TotalPages != 3 {
+		t.Errorf("Expected 3 total pages, got %d", page.TotalPages)
+	}
+	if page.StartLine != 201 || page.EndLine != 250 {
+		t.Errorf("Expected lines 201-250, got %d-%d", page.StartLine, page.EndLine)
+	}
+	if !strings.HasPrefix(page.Content, " 201: line 201\n") || !strings.HasSuffix(page.Content, " 250: line 250\n") {
+		t.Errorf("Unexpected page content: %q", page.Content)
+	}
+
+	page, err = GetFilePage(repo.Path, "big.txt", 1, 100)
+	if err != nil {
+		t.Fatalf("GetFilePage failed: %v", err)
+	}
+	if page.StartLine != 1 || page.EndLine != 100 {
+		t.Errorf("Expected lines 1-100, got %d-%d", page.StartLine, page.EndLine)
+	}
+
+	for _, tc := range []struct{ page, size int }{{4, 100}, {0, 100}, {1, 0}} {
+		if _, err := GetFilePage(repo.Path, "big.txt", tc.page, tc.size); err == nil {
+			t.Errorf("Expected error for page %d with size %d", tc.page, tc.size)
+		}
+	}
+}
+
+func TestGetFileTailWithLineNumbers(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	repo.WriteFile("app.log", strings.Join(lines, "\n")+"\n")
+	repo.AddCommit("Add log file")
+
+	content, totalLines, startLine, endLine, err := GetFileTailWithLineNumbers(repo.Path, "app.log", 5, true)
+	if err != nil {
+		t.Fatalf("GetFileTailWithLineNumbers failed: %v", err)
+	}
+	if totalLines != 12 || startLine != 8 || endLine != 12 {
+		t.Errorf("Expected lines 8-12 of 12, got %d-%d of %d", startLine, endLine, totalLines)
+	}
+	if !strings.HasPrefix(content, "   8: line 8\n") || !strings.HasSuffix(content, "  12: line 12\n") {
+		t.Errorf("Unexpected tail content: %q", content)
+	}
+
+	// Asking for more lines than the file has returns the whole file
+	_, totalLines, startLine, endLine, err = GetFileTailWithLineNumbers(repo.Path, "app.log", 50, true)
+	if err != nil {
+		t.Fatalf("GetFileTailWithLineNumbers failed: %v", err)
+	}
+	if totalLines != 12 || startLine != 1 || endLine != 12 {
+		t.Errorf("Expected lines 1-12 of 12, got %d-%d of %d", startLine, endLine, totalLines)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetFileContent(context.Background(), nil, GetFileContentParams{
+			Repository: repo.Path,
+			FilePath:   "app.log",
+			MaxLines:   5,
+			FromEnd:    true,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetFileContent failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.HasPrefix(text, "[app.log L8-12/12]\n") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
+
+func TestGetFileMetadata(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("image.bin", "\x89PNG\x00\x01\x02binary")
+	repo.WriteFile("run.sh", "#!/bin/sh\necho hi\n")
+	if err := os.Chmod(filepath.Join(repo.Path, "run.sh"), 0755); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	if err := os.Symlink("README.md", filepath.Join(repo.Path, "link.md")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	repo.AddCommit("Add metadata fixtures")
+
+	t.Run("text file", func(t *testing.T) {
+		metadata, err := GetFileMetadata(repo.Path, "main.go")
+		if err != nil {
+			t.Fatalf("GetFileMetadata failed: %v", err)
+		}
+		if metadata.IsBinary || metadata.IsSymlink || metadata.IsExecutable {
+			t.Errorf("Expected plain text file, got %+v", metadata)
+		}
+		if metadata.Size == 0 || !strings.HasPrefix(metadata.Mode, "-rw") {
+			t.Errorf("Unexpected size/mode: %+v", metadata)
+		}
+		if !strings.HasPrefix(metadata.MIMEType, "text/") {
+			t.Errorf("Expected text MIME type, got %q", metadata.MIMEType)
+		}
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		metadata, err := GetFileMetadata(repo.Path, "image.bin")
+		if err != nil {
+			t.Fatalf("GetFileMetadata failed: %v", err)
+		}
+		if !metadata.IsBinary {
+			t.Errorf("Expected binary file, got %+v", metadata)
+		}
+	})
+
+	t.Run("executable", func(t *testing.T) {
+		metadata, err := GetFileMetadata(repo.Path, "run.sh")
+		if err != nil {
+			t.Fatalf("GetFileMetadata failed: %v", err)
+		}
+		if !metadata.IsExecutable || metadata.Mode != "-rwxr-xr-x" {
+			t.Errorf("Expected executable file, got %+v", metadata)
+		}
+	})
+
+	t.Run("symlink", func(t *testing.T) {
+		metadata, err := GetFileMetadata(repo.Path, "link.md")
+		if err != nil {
+			t.Fatalf("GetFileMetadata failed: %v", err)
+		}
+		if !metadata.IsSymlink || metadata.LinkTarget != "README.md" {
+			t.Errorf("Expected symlink to README.md, got %+v", metadata)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := GetFileMetadata(repo.Path, "missing.txt"); err == nil {
+			t.Error("Expected error for missing file")
+		}
+		if _, err := GetFileMetadata(repo.Path, "../outside.txt"); err == nil {
+			t.Error("Expected error for path outside the repository")
+		}
+	})
+
+	t.Run("symlinked directory outside the repository", func(t *testing.T) {
+		outside := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+			t.Fatalf("Failed to write outside file: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(repo.Path, "ext")); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+
+		if metadata, err := GetFileMetadata(repo.Path, "ext/secret.txt"); err == nil {
+			t.Errorf("Expected file behind a symlinked directory to be refused, got %+v", metadata)
+		}
+		metadata, err := GetFileMetadata(repo.Path, "ext")
+		if err != nil {
+			t.Fatalf("GetFileMetadata failed: %v", err)
+		}
+		if !metadata.IsSymlink {
+			t.Errorf("Expected the symlink itself to be reported, got %+v", metadata)
+		}
+	})
+}
+
 func TestPullRepository(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -465,6 +1294,172 @@ func TestPullRepository(t *testing.T) {
 	}
 }
 
+func TestGetFileStats(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("stats.txt", "one two three\n\n  \nthe longest line here\nlast")
+	repo.WriteFile("newline.txt", "héllo wörld\n")
+	repo.WriteFile("empty.txt", "")
+	repo.AddCommit("Add stats fixtures")
+
+	tests := []struct {
+		file     string
+		expected FileStats
+	}{
+		{"stats.txt", FileStats{Path: "stats.txt", Bytes: 44, Lines: 5, Words: 8, BlankLines: 2, LongestLine: 21, LongestLineNumber: 4, EndsWithNewline: false}},
+		{"newline.txt", FileStats{Path: "newline.txt", Bytes: 14, Lines: 1, Words: 2, LongestLine: 11, LongestLineNumber: 1, EndsWithNewline: true}},
+		{"empty.txt", FileStats{Path: "empty.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			stats, err := GetFileStats(repo.Path, tt.file)
+			if err != nil {
+				t.Fatalf("GetFileStats failed: %v", err)
+			}
+			if *stats != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *stats)
+			}
+		})
+	}
+
+	t.Run("path outside repository", func(t *testing.T) {
+		if _, err := GetFileStats(repo.Path, "../outside.txt"); err == nil {
+			t.Error("Expected error for path outside repository")
+		}
+	})
+}
+
+func TestGetMultipleFileContentsOrder(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	var filePaths []string
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("notes/note_%02d.txt", i)
+		repo.WriteFile(path, fmt.Sprintf("note %d\n", i))
+		filePaths = append(filePaths, path)
+	}
+	// Requested out of name order, with a failing file in the middle
+	slices.Reverse(filePaths)
+	filePaths = slices.Insert(filePaths, 10, "missing.txt")
+
+	results, err := GetMultipleFileContentsWithLineNumbers(repo.Path, filePaths, 1, 0, false)
+	if err != nil {
+		t.Fatalf("GetMultipleFileContentsWithLineNumbers failed: %v", err)
+	}
+	if len(results) != len(filePaths) {
+		t.Fatalf("Expected %d results, got %d", len(filePaths), len(results))
+	}
+	for i, result := range results {
+		if result.FilePath != filePaths[i] {
+			t.Errorf("Result %d is %s, expected %s", i, result.FilePath, filePaths[i])
+			continue
+		}
+		if result.FilePath == "missing.txt" {
+			if result.Error == "" {
+				t.Errorf("Expected an error for missing.txt")
+			}
+			continue
+		}
+		var n int
+		fmt.Sscanf(result.FilePath, "notes/note_%d.txt", &n)
+		if result.Error != "" || result.Content != fmt.Sprintf("note %d\n", n) {
+			t.Errorf("Unexpected result for %s: %+v", result.FilePath, result)
+		}
+	}
+}
+
+func TestFetchRepository(t *testing.T) {
+	upstream := CreateTestRepositoryWithContent(t)
+	branch := upstream.getCurrentBranch()
+
+	clonePath := filepath.Join(filepath.Dir(upstream.Path), "clone-repo")
+	if output, err := exec.Command("git", "clone", "-q", upstream.Path, clonePath).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to clone: %v\n%s", err, output)
+	}
+
+	upstream.WriteFile("incoming.txt", "new upstream work\n")
+	upstream.AddCommit("Incoming change")
+
+	if _, err := FetchRepository(clonePath); err != nil {
+		t.Fatalf("FetchRepository failed: %v", err)
+	}
+
+	commits, err := ListCommits(clonePath, 1)
+	if err != nil {
+		t.Fatalf("ListCommits failed: %v", err)
+	}
+	if commits[0].Message == "Incoming change" {
+		t.Error("Expected local branch to be left unchanged by fetch")
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "incoming.txt")); !os.IsNotExist(err) {
+		t.Error("Expected working tree to be left unchanged by fetch")
+	}
+
+	diff, err := DiffRefsStat(clonePath, branch, "origin/"+branch, nil)
+	if err != nil {
+		t.Fatalf("DiffRefsStat failed: %v", err)
+	}
+	if !strings.Contains(diff, "incoming.txt") {
+		t.Errorf("Expected fetched change in diff, got: %s", diff)
+	}
+
+	t.Run("non-git directory", func(t *testing.T) {
+		if _, err := FetchRepository(t.TempDir()); err == nil {
+			t.Error("Expected error for non-git directory")
+		}
+	})
+}
+
+func TestPreviewPull(t *testing.T) {
+	upstream := CreateTestRepositoryWithContent(t)
+
+	clonePath := filepath.Join(filepath.Dir(upstream.Path), "clone-repo")
+	if output, err := exec.Command("git", "clone", "-q", upstream.Path, clonePath).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to clone: %v\n%s", err, output)
+	}
+
+	preview, err := PreviewPull(clonePath)
+	if err != nil {
+		t.Fatalf("PreviewPull failed: %v", err)
+	}
+	if !preview.UpToDate || len(preview.Commits) != 0 {
+		t.Errorf("Expected up to date preview, got %+v", preview)
+	}
+
+	upstream.WriteFile("incoming.txt", "new upstream work\n")
+	upstream.AddCommit("Incoming change")
+
+	preview, err = PreviewPull(clonePath)
+	if err != nil {
+		t.Fatalf("PreviewPull failed: %v", err)
+	}
+	if preview.UpToDate || len(preview.Commits) != 1 || preview.Commits[0].Message != "Incoming change" {
+		t.Errorf("Expected one incoming commit, got %+v", preview.Commits)
+	}
+	if !strings.Contains(preview.Diff, "+new upstream work") {
+		t.Errorf("Expected incoming diff, got: %s", preview.Diff)
+	}
+	if _, err := os.Stat(filepath.Join(clonePath, "incoming.txt")); !os.IsNotExist(err) {
+		t.Error("Expected working tree to be left unchanged by preview")
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handlePreviewPull(context.Background(), nil, PreviewPullParams{Repository: clonePath})
+		if err != nil || result.IsError {
+			t.Fatalf("handlePreviewPull failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Incoming commits (1)") || !strings.Contains(text, "Incoming change") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+
+	t.Run("no upstream", func(t *testing.T) {
+		if _, err := PreviewPull(upstream.Path); err == nil {
+			t.Error("Expected error for branch without upstream")
+		}
+	})
+}
+
 func TestHelperFunctions(t *testing.T) {
 	t.Run("isGitRepository", func(t *testing.T) {
 		// Test with git repository
@@ -478,6 +1473,14 @@ func TestHelperFunctions(t *testing.T) {
 		if isGitRepository(tempDir) {
 			t.Errorf("Expected %s to not be recognized as git repository", tempDir)
 		}
+
+		// A .git file is only a repository if it points at a git dir
+		if err := os.WriteFile(filepath.Join(tempDir, ".git"), []byte("not a pointer\n"), 0644); err != nil {
+			t.Fatalf("Failed to write .git file: %v", err)
+		}
+		if isGitRepository(tempDir) {
+			t.Errorf("Expected a .git file without gitdir to not be recognized")
+		}
 	})
 
 	t.Run("findLicenseFile", func(t *testing.T) {
@@ -501,6 +1504,40 @@ func TestHelperFunctions(t *testing.T) {
 			t.Errorf("Expected README to contain 'Test Repository', got: %s", readme)
 		}
 	})
+
+	t.Run("countFileCharacters", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			content   string
+			wantChars int
+			wantLines int
+		}{
+			{"empty file", "", 0, 0},
+			{"LF with trailing newline", "ab\ncd\n", 6, 2},
+			{"LF without trailing newline", "ab\ncd", 5, 2},
+			{"CRLF with trailing newline", "ab\r\ncd\r\n", 8, 2},
+			{"CRLF without trailing newline", "ab\r\ncd", 6, 2},
+			{"single newline", "\n", 1, 1},
+			{"blank lines", "\n\n\n", 3, 3},
+			{"UTF-8 runes", "héllo\n日本\n", 9, 2},
+		}
+
+		dir := t.TempDir()
+		for _, tt := range tests {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_"))
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+			chars, lines := countFileCharacters(path)
+			if chars != tt.wantChars || lines != tt.wantLines {
+				t.Errorf("%s: expected %d chars, %d lines; got %d chars, %d lines", tt.name, tt.wantChars, tt.wantLines, chars, lines)
+			}
+		}
+
+		if chars, lines := countFileCharacters(filepath.Join(dir, "missing")); chars != 0 || lines != 0 {
+			t.Errorf("Expected 0, 0 for missing file, got %d, %d", chars, lines)
+		}
+	})
 }
 
 func TestEdgeCases(t *testing.T) {
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// governanceKinds are the governance documents looked for, in output order
+var governanceKinds = []string{
+	"CODEOWNERS",
+	"CONTRIBUTING",
+	"SECURITY",
+	"CODE_OF_CONDUCT",
+	"GOVERNANCE",
+	"MAINTAINERS",
+	"SUPPORT",
+}
+
+// governanceDirs are the directories searched, in the order GitHub resolves
+// CODEOWNERS (.github/, then the root, then docs/)
+var governanceDirs = []string{".github", ".", "docs"}
+
+// GovernanceFile is a project ownership or policy document
+type GovernanceFile struct {
+	Kind    string `json:"kind"` // e.g. "CODEOWNERS", "CONTRIBUTING"
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	Preview string `json:"preview,omitempty"`
+}
+
+// FindGovernanceFiles finds CODEOWNERS, CONTRIBUTING, SECURITY, CODE_OF_CONDUCT
+// and similar files in the repository root, .github/ and docs/. Names match
+// case-insensitively with any extension (e.g. contributing.md).
+func FindGovernanceFiles(repoPath string) ([]GovernanceFile, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	found := make(map[string][]GovernanceFile)
+	for _, dir := range governanceDirs {
+		entries, err := os.ReadDir(filepath.Join(repoPath, dir))
+		if err != nil {
+			continue // directory does not exist
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() {
+				continue
+			}
+			name := entry.Name()
+			kind := strings.ToUpper(strings.TrimSuffix(name, filepath.Ext(name)))
+			if !isGovernanceKind(kind) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			found[kind] = append(found[kind], GovernanceFile{
+				Kind: kind,
+				Path: filepath.ToSlash(filepath.Join(dir, name)),
+				Size: info.Size(),
+			})
+		}
+	}
+
+	var files []GovernanceFile
+	for _, kind := range governanceKinds {
+		files = append(files, found[kind]...)
+	}
+	return files, nil
+}
+
+// AddGovernancePreviews fills in the first previewLines lines of each file
+func AddGovernancePreviews(repoPath string, files []GovernanceFile, previewLines int) {
+	for i := range files {
+		content, _, _, _, err := GetFileContentWithLineNumbers(repoPath, files[i].Path, 1, previewLines, false)
+		if err == nil {
+			files[i].Preview = content
+		}
+	}
+}
+
+func isGovernanceKind(kind string) bool {
+	for _, known := range governanceKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFindGovernanceFiles(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile(".github/CODEOWNERS", "* @core-team\n/docs/ @docs-team\n")
+	repo.WriteFile("contributing.md", "# Contributing\n\nOpen a PR.\n")
+	repo.WriteFile("docs/SECURITY.md", "# Security Policy\n")
+	repo.WriteFile("docs/CODEOWNERS.bak/keep", "not a governance file\n")
+	repo.AddCommit("Add governance files")
+
+	files, err := FindGovernanceFiles(repo.Path)
+	if err != nil {
+		t.Fatalf("FindGovernanceFiles failed: %v", err)
+	}
+
+	var got []string
+	for _, file := range files {
+		got = append(got, file.Kind+"="+file.Path)
+	}
+	expected := "CODEOWNERS=.github/CODEOWNERS,CONTRIBUTING=contributing.md,SECURITY=docs/SECURITY.md"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, ","))
+	}
+
+	t.Run("handler with previews", func(t *testing.T) {
+		result, _, err := handleListGovernanceFiles(context.Background(), nil, ListGovernanceFilesParams{
+			Repository:   repo.Path,
+			PreviewLines: 1,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListGovernanceFiles failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, ".github/CODEOWNERS [CODEOWNERS]") || !strings.Contains(text, "* @core-team") {
+			t.Errorf("Expected CODEOWNERS with preview, got:\n%s", text)
+		}
+		if strings.Contains(text, "@docs-team") {
+			t.Errorf("Expected preview limited to 1 line, got:\n%s", text)
+		}
+	})
+
+	t.Run("no governance files", func(t *testing.T) {
+		other := CreateTestRepositoryWithContent(t)
+		files, err := FindGovernanceFiles(other.Path)
+		if err != nil {
+			t.Fatalf("FindGovernanceFiles failed: %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("Expected no governance files, got %+v", files)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/"
+
+// lfsPointerMaxSize bounds pointer files; git-lfs itself never treats a
+// larger file as a pointer
+const lfsPointerMaxSize = 1024
+
+// LFSPointer is the object a Git LFS pointer file stands in for
+type LFSPointer struct {
+	OID  string // e.g. "sha256:4d7a..."
+	Size int64
+}
+
+// lfsAvailable reports whether git-lfs is installed. The lookup runs once per process.
+var lfsAvailable = sync.OnceValue(func() bool {
+	return exec.Command("git", "lfs", "version").Run() == nil
+})
+
+// readLFSPointer returns the pointer stored in fullPath along with the raw
+// pointer file, or nil when the file is not a Git LFS pointer (or cannot be read)
+func readLFSPointer(fullPath string) (*LFSPointer, []byte) {
+	info, err := os.Stat(fullPath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > lfsPointerMaxSize {
+		return nil, nil
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, nil
+	}
+	pointer := parseLFSPointer(data)
+	if pointer == nil {
+		return nil, nil
+	}
+	return pointer, data
+}
+
+// parseLFSPointer parses the "key value" lines of a pointer file, returning
+// nil unless it has the spec version line, an oid and a size
+func parseLFSPointer(data []byte) *LFSPointer {
+	if !bytes.HasPrefix(data, []byte(lfsPointerPrefix)) {
+		return nil
+	}
+
+	pointer := &LFSPointer{Size: -1}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			return nil
+		}
+		switch key {
+		case "oid":
+			pointer.OID = value
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return nil
+			}
+			pointer.Size = size
+		}
+	}
+	if pointer.OID == "" || pointer.Size < 0 {
+		return nil
+	}
+	return pointer
+}
+
+// String describes the pointer for error messages
+func (p *LFSPointer) String() string {
+	return fmt.Sprintf("Git LFS pointer to %s (%s)", p.OID, formatByteSize(p.Size))
+}
+
+// smudgeLFSPointer returns the real content behind a pointer file by running
+// git lfs smudge, which fetches the object if it is not cached locally. The
+// working tree is left untouched. Objects over the max file bytes limit are
+// neither fetched nor read. Without git-lfs, or if the object cannot be
+// fetched, the error describes the pointer instead.
+func smudgeLFSPointer(repoPath, filePath string, pointer *LFSPointer, data []byte) ([]byte, error) {
+	// The smudged object is buffered whole, so it is held to the same cap as a full read
+	if maxBytes := GetSessionConfig().GetMaxFileBytes(); pointer.Size > maxBytes {
+		return nil, fmt.Errorf("file is a %s; the object is too large to read (limit %d bytes)", pointer, maxBytes)
+	}
+	if !lfsAvailable() {
+		return nil, fmt.Errorf("file is a %s; git-lfs is not installed, so its content cannot be fetched", pointer)
+	}
+
+	cmd := exec.Command("git", "lfs", "smudge", "--", filePath)
+	cmd.Dir = repoPath
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	content, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("file is a %s; git lfs smudge failed: %s", pointer, strings.TrimSpace(stderr.String()))
+	}
+	return content, nil
+}
+
+// openTextContent is openTextFile for content already in memory
+func openTextContent(content []byte, encoding string) (io.ReadCloser, string, error) {
+	if encoding == "" {
+		encoding = detectEncoding(content[:min(len(content), binaryCheckSize)])
+	}
+	return io.NopCloser(newDecodingReader(bytes.NewReader(content), encoding)), encoding, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const lfsPointerSample = "version https://git-lfs.github.com/spec/v1\n" +
+	"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+	"size 12345\n"
+
+func TestParseLFSPointer(t *testing.T) {
+	pointer := parseLFSPointer([]byte(lfsPointerSample))
+	if pointer == nil {
+		t.Fatal("Expected the sample to parse as a pointer")
+	}
+	if pointer.OID != "sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393" || pointer.Size != 12345 {
+		t.Errorf("Unexpected pointer %+v", pointer)
+	}
+
+	for name, content := range map[string]string{
+		"plain text":   "hello world\n",
+		"missing oid":  "version https://git-lfs.github.com/spec/v1\nsize 12345\n",
+		"missing size": "version https://git-lfs.github.com/spec/v1\noid sha256:abc\n",
+		"bad size":     "version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize many\n",
+	} {
+		if pointer := parseLFSPointer([]byte(content)); pointer != nil {
+			t.Errorf("%s: expected no pointer, got %+v", name, pointer)
+		}
+	}
+}
+
+func TestHandleGetFileContentLFSPointer(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("assets/model.bin", lfsPointerSample)
+
+	result, _, err := handleGetFileContent(context.Background(), nil, GetFileContentParams{
+		Repository: repo.Path,
+		FilePath:   "assets/model.bin",
+	})
+	if err != nil {
+		t.Fatalf("handleGetFileContent failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	// The object does not exist, so even with git-lfs installed the pointer is reported
+	want := "Git LFS pointer to sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393 (12.1 KB)"
+	if !result.IsError || !strings.Contains(text, want) {
+		t.Errorf("Expected an error describing the pointer, got:\n%s", text)
+	}
+	if strings.Contains(text, "version https://git-lfs") {
+		t.Errorf("Expected the pointer text not to be returned as content:\n%s", text)
+	}
+}
+
+func TestHandleGetFileContentLFSPointerOverLimit(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("assets/huge.bin", "version https://git-lfs.github.com/spec/v1\n"+
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n"+
+		"size 1099511627776\n")
+
+	result, _, err := handleGetFileContent(context.Background(), nil, GetFileContentParams{
+		Repository: repo.Path,
+		FilePath:   "assets/huge.bin",
+	})
+	if err != nil {
+		t.Fatalf("handleGetFileContent failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !result.IsError || !strings.Contains(text, "too large to read") {
+		t.Errorf("Expected the object to be refused as too large, got:\n%s", text)
+	}
+}
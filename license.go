@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+)
+
+// licenseScanLines is how much of a license file is searched for a signature
+const licenseScanLines = 40
+
+// licenseUnknown is the license type of a license file matching no signature
+const licenseUnknown = "Unknown"
+
+// licenseSignatures identify licenses by phrases from their text, all of which
+// must appear. They are tried in order, so the more specific GPL variants come
+// before GPL-3.0 and BSD-3-Clause before BSD-2-Clause.
+var licenseSignatures = []struct {
+	spdx    string
+	phrases []string
+}{
+	{"MIT", []string{"permission is hereby granted, free of charge"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name of"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+}
+
+// detectLicenseType returns the SPDX identifier of the license in the
+// repository's license file, judged from its first licenseScanLines lines, or
+// licenseUnknown. It is read like any other file, so a symlink leading out of
+// the workspace is not followed.
+func detectLicenseType(repoPath, license string) string {
+	head, err := GetFileContent(repoPath, license, licenseScanLines)
+	if err != nil {
+		return licenseUnknown
+	}
+	return identifyLicense(head)
+}
+
+// identifyLicense matches text against licenseSignatures. Line breaks and runs
+// of spaces are collapsed so phrases wrapped across lines still match; a title
+// line alone ("MIT License") is enough for MIT.
+func identifyLicense(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	for _, signature := range licenseSignatures {
+		matched := true
+		for _, phrase := range signature.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return signature.spdx
+		}
+	}
+	if strings.HasPrefix(normalized, "mit license") || strings.HasPrefix(normalized, "the mit license") {
+		return "MIT"
+	}
+	return licenseUnknown
+}
+
+// formatLicense renders a license file with its type, e.g. "LICENSE (MIT)"
+func formatLicense(info *RepositoryInfo) string {
+	if info.LicenseType == "" {
+		return info.License
+	}
+	return info.License + " (" + info.LicenseType + ")"
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const mitLicenseBody = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software.
+`
+
+const apacheLicenseBody = `
+                                 Apache License
+                           Version 2.0, January 2004
+                        http://www.apache.org/licenses/
+
+   TERMS AND CONDITIONS FOR USE, REPRODUCTION, AND DISTRIBUTION
+`
+
+func TestIdentifyLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"mit", mitLicenseBody, "MIT"},
+		{"mit title only", "The MIT License (MIT)\n\nCopyright (c) 2024 Example\n", "MIT"},
+		{"apache", apacheLicenseBody, "Apache-2.0"},
+		{"gpl-3.0", "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n", "GPL-3.0"},
+		{"lgpl-3.0", "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n", "LGPL-3.0"},
+		{"bsd-3-clause", "Redistribution and use in source and binary\nforms, with or without modification, are permitted...\n3. Neither the name of the copyright holder\n", "BSD-3-Clause"},
+		{"mpl-2.0", "Mozilla Public License Version 2.0\n==================================\n", "MPL-2.0"},
+		{"unknown", "All rights reserved.\n", licenseUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identifyLicense(tt.text); got != tt.want {
+				t.Errorf("identifyLicense() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepositoryInfoLicenseType(t *testing.T) {
+	for _, tt := range []struct {
+		body string
+		want string
+	}{
+		{mitLicenseBody, "License: LICENSE (MIT)\n"},
+		{apacheLicenseBody, "License: LICENSE (Apache-2.0)\n"},
+		{"Proprietary. Do not distribute.\n", "License: LICENSE (Unknown)\n"},
+	} {
+		repo := CreateTestRepositoryWithContent(t)
+		repo.WriteFile("LICENSE", tt.body)
+		repo.AddCommit("Update license")
+
+		result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repo.Path})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, tt.want) {
+			t.Errorf("Expected %q in output:\n%s", tt.want, text)
+		}
+	}
+}
+
+func TestRepositoryInfoLicenseSymlinkOutside(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	outside := filepath.Join(t.TempDir(), "LICENSE")
+	if err := os.WriteFile(outside, []byte(mitLicenseBody), 0644); err != nil {
+		t.Fatalf("Failed to write outside file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(repo.Path, "LICENSE")); err != nil {
+		t.Fatalf("Failed to remove LICENSE: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(repo.Path, "LICENSE")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	info, err := GetRepositoryInfo(repo.Path)
+	if err != nil {
+		t.Fatalf("GetRepositoryInfo failed: %v", err)
+	}
+	if info.LicenseType == "MIT" {
+		t.Errorf("Expected the license outside the workspace not to be read, got %s", info.LicenseType)
+	}
+}
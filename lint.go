@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// FileLintIssue lists the text hygiene problems found in one file
+type FileLintIssue struct {
+	Path   string   `json:"path"`
+	Issues []string `json:"issues"`
+}
+
+// LintFiles scans tracked text files for CRLF or mixed line endings, trailing
+// whitespace, a missing final newline and invalid UTF-8. Binary files and files
+// over maxScanFileSize are skipped. Only files with at least one issue are returned.
+func LintFiles(repoPath string, includePatterns []string) ([]FileLintIssue, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "ls-files")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+
+	var results []FileLintIssue
+	for _, trackedPath := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if trackedPath == "" || !matchesPatterns(trackedPath, includePatterns) {
+			continue
+		}
+
+		fullPath := filepath.Join(repoPath, trackedPath)
+		info, err := os.Lstat(fullPath)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxScanFileSize {
+			continue
+		}
+
+		content, err := os.ReadFile(fullPath)
+		if err != nil || bytes.IndexByte(content[:min(len(content), binaryCheckSize)], 0) >= 0 {
+			continue
+		}
+
+		if issues := lintContent(content); len(issues) > 0 {
+			results = append(results, FileLintIssue{Path: trackedPath, Issues: issues})
+		}
+	}
+
+	return results, nil
+}
+
+// lintContent returns a description of each hygiene issue found in content
+func lintContent(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	var issues []string
+	var crlfLines, lfLines, trailingLines, firstTrailing int
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		text := line
+		if bytes.HasSuffix(text, []byte("\r\n")) {
+			crlfLines++
+			text = text[:len(text)-2]
+		} else if bytes.HasSuffix(text, []byte("\n")) {
+			lfLines++
+			text = text[:len(text)-1]
+		}
+		if len(text) > 0 && (text[len(text)-1] == ' ' || text[len(text)-1] == '\t') {
+			trailingLines++
+			if firstTrailing == 0 {
+				firstTrailing = i + 1
+			}
+		}
+	}
+
+	switch {
+	case crlfLines > 0 && lfLines > 0:
+		issues = append(issues, fmt.Sprintf("mixed line endings (%d CRLF, %d LF)", crlfLines, lfLines))
+	case crlfLines > 0:
+		issues = append(issues, fmt.Sprintf("CRLF line endings (%d lines)", crlfLines))
+	}
+	if trailingLines > 0 {
+		issues = append(issues, fmt.Sprintf("trailing whitespace on %d lines (first: line %d)", trailingLines, firstTrailing))
+	}
+	if content[len(content)-1] != '\n' {
+		issues = append(issues, "missing final newline")
+	}
+	if !utf8.Valid(content) {
+		issues = append(issues, "invalid UTF-8")
+	}
+
+	return issues
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLintFiles(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("fixtures/windows.txt", "first\r\nsecond\r\nthird\r\n")
+	repo.WriteFile("fixtures/mixed.txt", "first\r\nsecond\n")
+	repo.WriteFile("fixtures/trailing.txt", "clean\nspaces   \ntab\t\nclean\n")
+	repo.WriteFile("fixtures/clean.txt", "nothing wrong\n")
+	repo.WriteFile("fixtures/latin1.txt", "caf\xe9\n")
+	repo.WriteFile("fixtures/image.bin", "\x00\x01\x02 \r\n")
+	repo.AddCommit("Add lint fixtures")
+
+	files, err := LintFiles(repo.Path, []string{"fixtures/"})
+	if err != nil {
+		t.Fatalf("LintFiles failed: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, file := range files {
+		got[file.Path] = strings.Join(file.Issues, "; ")
+	}
+
+	expected := map[string]string{
+		"fixtures/windows.txt":  "CRLF line endings (3 lines)",
+		"fixtures/mixed.txt":    "mixed line endings (1 CRLF, 1 LF)",
+		"fixtures/trailing.txt": "trailing whitespace on 2 lines (first: line 2)",
+		"fixtures/latin1.txt":   "invalid UTF-8",
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d files with issues, got %d: %v", len(expected), len(got), got)
+	}
+	for path, issues := range expected {
+		if got[path] != issues {
+			t.Errorf("Expected %s to report %q, got %q", path, issues, got[path])
+		}
+	}
+
+	t.Run("missing final newline", func(t *testing.T) {
+		files, err := LintFiles(repo.Path, []string{"version.txt"})
+		if err != nil {
+			t.Fatalf("LintFiles failed: %v", err)
+		}
+		if len(files) != 1 || strings.Join(files[0].Issues, "; ") != "missing final newline" {
+			t.Errorf("Expected version.txt to miss its final newline, got %+v", files)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleLintFiles(context.Background(), nil, LintFilesParams{
+			Repository:      repo.Path,
+			IncludePatterns: []string{"fixtures/"},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleLintFiles failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Lint Results (4 files with issues)") || !strings.Contains(text, "fixtures/trailing.txt") {
+			t.Errorf("Unexpected lint output:\n%s", text)
+		}
+	})
+}
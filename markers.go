@@ -0,0 +1,43 @@
+package main
+
+// outputMarkers holds the symbols formatters use to decorate their output
+type outputMarkers struct {
+	File    string
+	Dir     string
+	Memo    string
+	OK      string
+	Error   string
+	Changed string
+	Arrow   string
+}
+
+var emojiMarkers = outputMarkers{
+	File:    "📄",
+	Dir:     "📁",
+	Memo:    "📝",
+	OK:      "✓",
+	Error:   "✗",
+	Changed: "●",
+	Arrow:   "→",
+}
+
+var plainMarkers = outputMarkers{
+	File:    "[file]",
+	Dir:     "[dir]",
+	Memo:    "[memo]",
+	OK:      "[ok]",
+	Error:   "[error]",
+	Changed: "[changed]",
+	Arrow:   "->",
+}
+
+// defaultNoEmoji is set by the --no-emoji flag and can be overridden per session
+var defaultNoEmoji bool
+
+// markers returns the output markers for the current session
+func markers() outputMarkers {
+	if GetSessionConfig().GetNoEmoji() {
+		return plainMarkers
+	}
+	return emojiMarkers
+}
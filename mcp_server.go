@@ -3,13 +3,26 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
+// shutdownTimeout bounds how long the HTTP transports wait for in-flight
+// requests (clones, searches) to finish after SIGINT or SIGTERM
+const shutdownTimeout = 30 * time.Second
+
+// readOnlyMode, set by --read-only, leaves out every tool that changes
+// repository or workspace state (clone, remove, rename, pull, fetch, switch
+// branch, memo writes) and keeps session configuration in memory only
+var readOnlyMode bool
+
 // McpCmd is the command for starting MCP server
 var McpCmd = &cobra.Command{
 	Use:   "mcp",
@@ -17,12 +30,15 @@ var McpCmd = &cobra.Command{
 	Long: `Start a Model Context Protocol (MCP) server that provides Git read operations.
 
 Supports repository information, branch listing, file operations, and search capabilities.
-Supports both stdio (default) and HTTP transports for maximum compatibility.`,
+Supports stdio (default), streamable HTTP and SSE transports for maximum compatibility.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		transport, _ := cmd.Flags().GetString("transport")
 		port, _ := cmd.Flags().GetInt("port")
 		host, _ := cmd.Flags().GetString("host")
+		ssePath, _ := cmd.Flags().GetString("sse-path")
 		workspace, _ := cmd.Flags().GetString("workspace")
+		defaultNoEmoji, _ = cmd.Flags().GetBool("no-emoji")
+		readOnlyMode, _ = cmd.Flags().GetBool("read-only")
 
 		// For stdio mode, logs are automatically redirected to stderr
 		// to avoid protocol contamination on stdout
@@ -40,6 +56,11 @@ Supports both stdio (default) and HTTP transports for maximum compatibility.`,
 			return fmt.Errorf("failed to initialize memo store: %v", err)
 		}
 
+		// Restore session configuration saved by a previous run
+		if err := LoadSessionConfig(workspace); err != nil {
+			return fmt.Errorf("failed to load session config: %v", err)
+		}
+
 		// Create MCP server
 		server := CreateMCPServer()
 
@@ -58,10 +79,7 @@ Supports both stdio (default) and HTTP transports for maximum compatibility.`,
 
 			// Create mux for routing
 			mux := http.NewServeMux()
-			mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-				w.WriteHeader(http.StatusOK)
-				w.Write([]byte("ok"))
-			})
+			mux.HandleFunc("/health", handleHealth)
 			mux.Handle("/mcp", mcpHandler)
 			mux.Handle("/mcp/", mcpHandler)
 
@@ -69,16 +87,87 @@ Supports both stdio (default) and HTTP transports for maximum compatibility.`,
 			fmt.Printf("Starting Git Remote MCP server on %s\n", address)
 			fmt.Printf("  MCP endpoint: http://%s/mcp\n", address)
 			fmt.Printf("  Health check: http://%s/health\n", address)
-			return http.ListenAndServe(address, mux)
+			return listenAndServeGracefully(address, mux)
+
+		case "sse":
+			// Use the classic Server-Sent Events transport
+			mux := newSSEMux(server, ssePath)
+
+			address := fmt.Sprintf("%s:%d", host, port)
+			fmt.Printf("Starting Git Remote MCP server (SSE) on %s\n", address)
+			fmt.Printf("  SSE endpoint: http://%s%s\n", address, ssePath)
+			fmt.Printf("  Health check: http://%s/health\n", address)
+			return listenAndServeGracefully(address, mux)
 
 		default:
 			fmt.Fprintf(cmd.ErrOrStderr(), "Error: Unsupported transport: %s\n", transport)
-			fmt.Fprintf(cmd.ErrOrStderr(), "Supported transports: stdio, http\n")
+			fmt.Fprintf(cmd.ErrOrStderr(), "Supported transports: stdio, http, sse\n")
 			return fmt.Errorf("unsupported transport: %s", transport)
 		}
 	},
 }
 
+// handleHealth answers the /health check used by the HTTP transports
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// listenAndServeGracefully serves handler on address until SIGINT or SIGTERM,
+// then shuts down without cutting off in-flight requests
+func listenAndServeGracefully(address string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	return serveUntilSignal(&http.Server{Handler: handler}, ln, stop, shutdownTimeout)
+}
+
+// serveUntilSignal runs srv on ln until a signal arrives on stop, then calls
+// Shutdown so active requests can finish within timeout. Connections still
+// open after the timeout (such as SSE streams) are closed forcibly.
+func serveUntilSignal(srv *http.Server, ln net.Listener, stop <-chan os.Signal, timeout time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case sig := <-stop:
+		fmt.Printf("Received %v, shutting down (waiting up to %v for in-flight requests)\n", sig, timeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+		return fmt.Errorf("graceful shutdown did not finish: %v", err)
+	}
+
+	fmt.Printf("Server stopped\n")
+	return nil
+}
+
+// newSSEMux routes the SSE transport: clients open an event stream with GET on
+// ssePath and post their messages back to the session URL it announces
+func newSSEMux(server *mcp.Server, ssePath string) *http.ServeMux {
+	sseHandler := mcp.NewSSEHandler(func(*http.Request) *mcp.Server {
+		return server
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", handleHealth)
+	mux.Handle(ssePath, sseHandler)
+	return mux
+}
+
 // CreateMCPServer creates a new MCP server with all tools registered
 func CreateMCPServer() *mcp.Server {
 	// Create server with Git Remote implementation info and options
@@ -97,12 +186,18 @@ func CreateMCPServer() *mcp.Server {
 	// Register all Memo tools
 	RegisterMemoTools(server)
 
+	// Register all Session tools
+	RegisterSessionTools(server)
+
 	return server
 }
 
 func init() {
-	McpCmd.Flags().String("transport", "stdio", "Transport protocol (stdio or http)")
-	McpCmd.Flags().Int("port", 8080, "Port for HTTP transport (ignored for stdio)")
-	McpCmd.Flags().String("host", "localhost", "Host address for HTTP transport (use 0.0.0.0 for all interfaces)")
+	McpCmd.Flags().String("transport", "stdio", "Transport protocol (stdio, http, or sse for Server-Sent Events)")
+	McpCmd.Flags().Int("port", 8080, "Port for HTTP and SSE transports (ignored for stdio)")
+	McpCmd.Flags().String("sse-path", "/sse", "Endpoint path for the SSE transport")
+	McpCmd.Flags().String("host", "localhost", "Host address for HTTP and SSE transports (use 0.0.0.0 for all interfaces)")
 	McpCmd.Flags().String("workspace", "./workspace", "Workspace directory for Git repositories")
+	McpCmd.Flags().Bool("no-emoji", false, "Use plain ASCII markers instead of emoji in tool output")
+	McpCmd.Flags().Bool("read-only", false, "Do not register tools that clone, remove, rename, pull, fetch, switch branches or write memos, and do not save session configuration")
 }
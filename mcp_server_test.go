@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSETransport(t *testing.T) {
+	mux := newSSEMux(CreateMCPServer(), "/sse")
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	t.Run("health", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + "/health")
+		if err != nil {
+			t.Fatalf("Health check failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+			t.Errorf("Expected 200 ok, got %d %q", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("event stream announces endpoint", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse", nil)
+		if err != nil {
+			t.Fatalf("Failed to build request: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("SSE request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+			t.Errorf("Expected text/event-stream, got %q", ct)
+		}
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read first event: %v", err)
+		}
+		if strings.TrimSpace(line) != "event: endpoint" {
+			t.Errorf("Expected endpoint event, got %q", line)
+		}
+	})
+}
+
+func TestServeUntilSignalShutsDownGracefully(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", handleHealth)
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("done"))
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	baseURL := "http://" + ln.Addr().String()
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- serveUntilSignal(&http.Server{Handler: mux}, ln, stop, 5*time.Second)
+	}()
+
+	resp, err := http.Get(baseURL + "/health")
+	if err != nil {
+		t.Fatalf("Health check failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// Start a request that is still running when the signal arrives
+	slowBody := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			slowBody <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		slowBody <- string(body)
+	}()
+	<-started
+
+	stop <- os.Interrupt
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not shut down")
+	}
+
+	if body := <-slowBody; body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q", body)
+	}
+
+	if _, err := http.Get(baseURL + "/health"); err == nil {
+		t.Errorf("Expected requests to fail after shutdown")
+	}
+}
@@ -2,68 +2,187 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // GetRepositoryInfoParams parameters for get_repository_info tool
 type GetRepositoryInfoParams struct {
-	Repository       string   `json:"repository,omitempty"`
-	IncludeMemos     bool     `json:"include_memos,omitempty"`     // Include memos associated with this repository
-	MemoLimit        int      `json:"memo_limit,omitempty"`        // Limit for memo list (default: 10)
-	ExcludePatterns  []string `json:"exclude_patterns,omitempty"` // File patterns to exclude from statistics
+	Repository      string   `json:"repository,omitempty"`
+	IncludeMemos    bool     `json:"include_memos,omitempty"`    // Include memos associated with this repository
+	IncludeRemotes  bool     `json:"include_remotes,omitempty"`  // List every remote instead of only origin
+	MemoLimit       int      `json:"memo_limit,omitempty"`       // Limit for memo list (default: 10)
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"` // File patterns to exclude from statistics
+	Refresh         bool     `json:"refresh,omitempty"`          // Recompute instead of using the cached info (kept 30s while HEAD is unchanged)
+	ReadmeLines     int      `json:"readme_lines,omitempty"`     // README lines shown (default: session default_readme_preview_lines, 100)
+}
+
+// ContextPackParams parameters for context_pack tool
+type ContextPackParams struct {
+	Repository string `json:"repository,omitempty"`
+	MaxBytes   int    `json:"max_bytes,omitempty"` // Output budget in bytes (default: 8000, roughly 2000 tokens)
+}
+
+// ExportOverviewParams parameters for export_overview tool
+type ExportOverviewParams struct {
+	Repository     string `json:"repository,omitempty"`
+	MaxFilesInTree int    `json:"max_files_in_tree,omitempty"` // Files shown in the directory tree (default: 200)
+	ReadmeLines    int    `json:"readme_lines,omitempty"`      // README lines included (default: 100)
 }
 
 // PullRepositoryParams parameters for pull_repository tool
 type PullRepositoryParams struct {
-	Repository string `json:"repository"`
+	Repository string `json:"repository,omitempty"`
+}
+
+// FetchRepositoryParams parameters for fetch_repository tool
+type FetchRepositoryParams struct {
+	Repository string `json:"repository,omitempty"`
+}
+
+// PreviewPullParams parameters for preview_pull tool
+type PreviewPullParams struct {
+	Repository string `json:"repository,omitempty"`
 }
 
 // ListBranchesParams parameters for list_branches tool
 type ListBranchesParams struct {
-	Repository string `json:"repository"`
-	Limit      int    `json:"limit,omitempty"`
+	Repository      string `json:"repository,omitempty"`
+	Limit           int    `json:"limit,omitempty"`
+	IncludeTracking bool   `json:"include_tracking,omitempty"` // Show ahead/behind counts relative to the current branch
 }
 
 // SwitchBranchParams parameters for switch_branch tool
 type SwitchBranchParams struct {
-	Repository string `json:"repository"`
+	Repository string `json:"repository,omitempty"`
 	Branch     string `json:"branch"`
+	Force      bool   `json:"force,omitempty"`
 }
 
 // SearchFilesParams parameters for search_files tool
 type SearchFilesParams struct {
-	Repository      string   `json:"repository,omitempty"`       // Single repository (uses session default if empty)
-	Repositories    []string `json:"repositories,omitempty"`     // Multiple repositories for cross-repo search
-	Keywords        []string `json:"keywords"`
-	SearchMode      string   `json:"search_mode,omitempty"`      // "and" or "or", defaults to "and"
-	IncludeFilename bool     `json:"include_filename,omitempty"` // search in filenames too, defaults to false
-	ContextLines    int      `json:"context_lines,omitempty"`    // number of context lines before/after match, 0=no context
-	IncludePatterns []string `json:"include_patterns,omitempty"` // file patterns to include (glob)
-	ExcludePatterns []string `json:"exclude_patterns,omitempty"` // file patterns to exclude (glob)
-	Limit           int      `json:"limit,omitempty"`
+	Repository        string   `json:"repository,omitempty"`   // Single repository (uses session default if empty)
+	Repositories      []string `json:"repositories,omitempty"` // Multiple repositories for cross-repo search
+	Keywords          []string `json:"keywords"`
+	ExcludeKeywords   []string `json:"exclude_keywords,omitempty"` // drop files whose content contains any of these (case-insensitive)
+	SearchMode        string   `json:"search_mode,omitempty"`      // "and", "or" or "phrase" (keywords joined by spaces, matched literally), defaults to "and"
+	IncludeFilename   bool     `json:"include_filename,omitempty"` // search in filenames too, defaults to false
+	ContextLines      int      `json:"context_lines,omitempty"`    // number of context lines before/after match, 0=no context
+	IncludePatterns   []string `json:"include_patterns,omitempty"` // file patterns to include (glob)
+	ExcludePatterns   []string `json:"exclude_patterns,omitempty"` // file patterns to exclude (glob)
+	Limit             int      `json:"limit,omitempty"`
+	OutputFormat      string   `json:"output_format,omitempty"`        // "text" (default), "grouped" (sections by match type) or "json"
+	MaxMatchesPerFile int      `json:"max_matches_per_file,omitempty"` // matching lines shown per file (default: 10, -1 = all)
+	FunctionContext   bool     `json:"function_context,omitempty"`     // show the function each match is in (git grep -p), defaults to false
+}
+
+// SearchInSymbolParams parameters for search_in_symbol tool
+type SearchInSymbolParams struct {
+	Repository string `json:"repository,omitempty"`
+	Symbol     string `json:"symbol"`             // Symbol name, e.g. "handleRequest" or "Server.Start"
+	Keyword    string `json:"keyword"`            // Text to find within the symbol (case-insensitive)
+	Language   string `json:"language,omitempty"` // Outline language (default: "go")
+}
+
+// FindRelatedTestsParams parameters for find_related_tests tool
+type FindRelatedTestsParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"` // Source file to find tests for, or test file to find the source of
+}
+
+// PathContextParams parameters for path_context tool
+type PathContextParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"` // File or directory to show the breadcrumb for
 }
 
 // ListFilesParams parameters for list_files tool
 type ListFilesParams struct {
-	Repository      string   `json:"repository"`
+	Repository      string   `json:"repository,omitempty"`
 	Directory       string   `json:"directory,omitempty"`
 	Recursive       bool     `json:"recursive,omitempty"`
 	IncludePatterns []string `json:"include_patterns,omitempty"` // file patterns to include (glob)
 	ExcludePatterns []string `json:"exclude_patterns,omitempty"` // file patterns to exclude (glob)
 	Limit           int      `json:"limit,omitempty"`
+	MaxDepth        int      `json:"max_depth,omitempty"`     // With recursive, directory levels to list (1 = directory only; default and cap 64)
+	Tree            bool     `json:"tree,omitempty"`          // With recursive, render an indented tree instead of flat paths
+	OutputFormat    string   `json:"output_format,omitempty"` // "text" (default) or "json" ([]FileInfo)
+}
+
+// FilterFilesParams parameters for filter_files tool
+type FilterFilesParams struct {
+	Repository string `json:"repository,omitempty"`
+	Directory  string `json:"directory,omitempty"`
+	Recursive  bool   `json:"recursive,omitempty"`
+	MinLines   int    `json:"min_lines,omitempty"` // Only files with at least this many lines
+	MaxLines   int    `json:"max_lines,omitempty"` // Only files with at most this many lines (0 = no limit)
+	MinBytes   int64  `json:"min_bytes,omitempty"` // Only files of at least this size
+	MaxBytes   int64  `json:"max_bytes,omitempty"` // Only files of at most this size (0 = no limit)
+	Limit      int    `json:"limit,omitempty"`
 }
 
 // GetFileContentParams parameters for get_file_content tool
 type GetFileContentParams struct {
-	Repository string   `json:"repository"`
-	FilePath   string   `json:"file_path,omitempty"`  // Single file path (for backward compatibility)
-	FilePaths  []string `json:"file_paths,omitempty"` // Multiple file paths
-	StartLine  int      `json:"start_line,omitempty"` // Start reading from this line (1-based, default: 1)
-	EndLine    int      `json:"end_line,omitempty"`   // End line (inclusive, default: start_line + 100)
-	MaxLines   int      `json:"max_lines,omitempty"`  // Deprecated: use end_line instead
+	Repository   string   `json:"repository,omitempty"`
+	FilePath     string   `json:"file_path,omitempty"`     // Single file path (for backward compatibility; merged with file_paths)
+	FilePaths    []string `json:"file_paths,omitempty"`    // Multiple file paths
+	StartLine    int      `json:"start_line,omitempty"`    // Start reading from this line (1-based, default: 1)
+	EndLine      int      `json:"end_line,omitempty"`      // End line (inclusive, clamped to the file's last line); max_lines is ignored when set
+	MaxLines     int      `json:"max_lines,omitempty"`     // Deprecated: use end_line instead
+	ForceBinary  bool     `json:"force_binary,omitempty"`  // Read files even if they look binary
+	FromEnd      bool     `json:"from_end,omitempty"`      // Read the last max_lines lines instead (start_line/end_line ignored)
+	OutputFormat string   `json:"output_format,omitempty"` // "text" (default) or "json" ([]FileContentResult without line number prefixes)
+
+	TrimTrailingWhitespace bool   `json:"trim_trailing_whitespace,omitempty"` // Strip trailing spaces, tabs and CRs from each line
+	Encoding               string `json:"encoding,omitempty"`                 // Force a source encoding (utf-8, utf-16le, utf-16be, shift_jis, iso-8859-1); detected when empty
+}
+
+// ReadParams parameters for read tool
+type ReadParams struct {
+	Path      string `json:"path"`                 // File path inside the session default repository
+	StartLine int    `json:"start_line,omitempty"` // Start reading from this line (1-based, default: 1)
+	EndLine   int    `json:"end_line,omitempty"`   // End line (inclusive, default: start_line + max_lines)
+}
+
+// GetFileRangesParams parameters for get_file_ranges tool
+type GetFileRangesParams struct {
+	Repository string      `json:"repository,omitempty"`
+	FilePath   string      `json:"file_path"`
+	Ranges     []LineRange `json:"ranges"` // Inclusive line ranges, each {start, end}; overlapping ranges are merged
+}
+
+// FetchCitationsParams parameters for fetch_citations tool
+type FetchCitationsParams struct {
+	Repository string            `json:"repository,omitempty"`
+	Citations  []CitationRequest `json:"citations"` // Exact line ranges to quote, each {file_path, start_line, end_line}
+}
+
+// GetFileMetadataParams parameters for get_file_metadata tool
+type GetFileMetadataParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+}
+
+// FileStatsParams parameters for file_stats tool
+type FileStatsParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+}
+
+// PageFileParams parameters for page_file tool
+type PageFileParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+	Page       int    `json:"page,omitempty"`      // Page number (1-based, default: 1)
+	PageSize   int    `json:"page_size,omitempty"` // Lines per page (default: session max_lines or 100)
 }
 
 // CloneRepositoryParams parameters for clone_repository tool
@@ -72,6 +191,23 @@ type CloneRepositoryParams struct {
 	Name            string `json:"name,omitempty"`             // Optional: will be extracted from URL if not provided
 	IncludeInfo     bool   `json:"include_info,omitempty"`     // Include repository info after clone
 	IncludeBranches bool   `json:"include_branches,omitempty"` // Include branch list after clone
+	Depth           int    `json:"depth,omitempty"`            // Shallow clone with this many commits (0 = full history)
+	Branch          string `json:"branch,omitempty"`           // Clone only this branch
+	Username        string `json:"username,omitempty"`         // Username for token auth (default: x-access-token)
+	Token           string `json:"token,omitempty"`            // Access token for private https repositories; never echoed back
+	SSHKeyPath      string `json:"ssh_key_path,omitempty"`     // Private key for ssh (git@host:...) URLs
+}
+
+// CloneRepositoriesParams parameters for clone_repositories tool
+type CloneRepositoriesParams struct {
+	Repositories []CloneRepositoryEntry `json:"repositories"`
+	Depth        int                    `json:"depth,omitempty"` // Shallow clone each with this many commits (0 = full history)
+}
+
+// CloneRepositoryEntry one repository to clone with clone_repositories
+type CloneRepositoryEntry struct {
+	URL  string `json:"url"`
+	Name string `json:"name,omitempty"` // Optional: will be extracted from URL if not provided
 }
 
 // ListWorkspaceRepositoriesParams parameters for list_workspace_repositories tool
@@ -79,6 +215,7 @@ type ListWorkspaceRepositoriesParams struct {
 	IncludeStatus  bool `json:"include_status,omitempty"`  // Include git status for each repo
 	IncludeCommits bool `json:"include_commits,omitempty"` // Include recent commits for each repo
 	CommitLimit    int  `json:"commit_limit,omitempty"`    // Number of commits to include (default: 5)
+	Detailed       bool `json:"detailed,omitempty"`        // Table of branch, clean/dirty status, disk usage, last commit date and remote per repo
 }
 
 // RemoveRepositoryParams parameters for remove_repository tool
@@ -86,34 +223,171 @@ type RemoveRepositoryParams struct {
 	Name string `json:"name"`
 }
 
+// RenameRepositoryParams parameters for rename_repository tool
+type RenameRepositoryParams struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name"`
+}
+
+// WhichRepositoryParams parameters for which_repository tool
+type WhichRepositoryParams struct {
+	Path string `json:"path"` // Absolute path to resolve
+}
+
 // GetReadmeFilesParams parameters for get_readme_files tool
 type GetReadmeFilesParams struct {
-	Repository string `json:"repository"`
-	Recursive  bool   `json:"recursive,omitempty"` // Search subdirectories
+	Repository      string   `json:"repository,omitempty"`
+	Recursive       bool     `json:"recursive,omitempty"`        // Search subdirectories
+	IncludeContent  bool     `json:"include_content,omitempty"`  // Include each README's content
+	MaxLines        int      `json:"max_lines,omitempty"`        // Lines of content per README (default: session max_lines)
+	IncludePatterns []string `json:"include_patterns,omitempty"` // Paths to include (glob), e.g. "docs/**"
+	ExcludePatterns []string `json:"exclude_patterns,omitempty"` // Paths to exclude (glob); without any patterns vendor dirs such as node_modules/ are skipped
+}
+
+// ListGovernanceFilesParams parameters for list_governance_files tool
+type ListGovernanceFilesParams struct {
+	Repository   string `json:"repository,omitempty"`
+	PreviewLines int    `json:"preview_lines,omitempty"` // Include the first N lines of each file (0 = paths only)
+}
+
+// LintFilesParams parameters for lint_files tool
+type LintFilesParams struct {
+	Repository      string   `json:"repository,omitempty"`
+	IncludePatterns []string `json:"include_patterns,omitempty"` // Only lint files matching these patterns
 }
 
 // ListCommitsParams parameters for list_commits tool
 type ListCommitsParams struct {
-	Repository string `json:"repository"`
-	Limit      int    `json:"limit,omitempty"`
+	Repository   string `json:"repository,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+	Author       string `json:"author,omitempty"`        // Only commits whose author matches (git log --author)
+	Since        string `json:"since,omitempty"`         // Only commits after this date, e.g. "2024-01-31" or "2 weeks ago"
+	Until        string `json:"until,omitempty"`         // Only commits before this date
+	Before       string `json:"before,omitempty"`        // Cursor: start just before this commit hash (the previous page's next cursor)
+	OutputFormat string `json:"output_format,omitempty"` // "text" (default) or "json" ([]Commit)
+}
+
+// SearchCommitsParams parameters for search_commits tool
+type SearchCommitsParams struct {
+	Repository string `json:"repository,omitempty"`
+	Query      string `json:"query"`               // Text to find in commit messages (case-insensitive)
+	AllMatch   bool   `json:"all_match,omitempty"` // Split query into words and require all of them
+	Limit      int    `json:"limit,omitempty"`     // Maximum commits to return (default: 20)
+}
+
+// FileHistoryParams parameters for file_history tool
+type FileHistoryParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+	Limit      int    `json:"limit,omitempty"` // Maximum commits to return (default: 20)
+}
+
+// BlameFileParams parameters for blame_file tool
+type BlameFileParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+	StartLine  int    `json:"start_line,omitempty"` // First line to blame (1-based)
+	EndLine    int    `json:"end_line,omitempty"`   // Last line to blame (inclusive)
+}
+
+// FileAuthorsParams parameters for file_authors tool
+type FileAuthorsParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+}
+
+// GetContributorsParams parameters for get_contributors tool
+type GetContributorsParams struct {
+	Repository string `json:"repository,omitempty"`
+	Limit      int    `json:"limit,omitempty"` // Maximum contributors to list (default: 20)
+}
+
+// GetActivitySummaryParams parameters for get_activity_summary tool
+type GetActivitySummaryParams struct {
+	Repository string `json:"repository,omitempty"`
+	Days       int    `json:"days,omitempty"`      // Window to summarize (default: 30)
+	TopFiles   int    `json:"top_files,omitempty"` // Most changed files to list (default: 10)
+}
+
+// DirectoryOwnershipParams parameters for directory_ownership tool
+type DirectoryOwnershipParams struct {
+	Repository string `json:"repository,omitempty"`
+	Directory  string `json:"directory,omitempty"` // Directory to analyze (default: repository root)
+}
+
+// LineHistoryParams parameters for line_history tool
+type LineHistoryParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+	Line       int    `json:"line"`            // 1-based line number
+	Limit      int    `json:"limit,omitempty"` // Maximum commits to return (default: 20)
+}
+
+// HistoryBloatParams parameters for history_bloat tool
+type HistoryBloatParams struct {
+	Repository string `json:"repository,omitempty"`
+	Limit      int    `json:"limit,omitempty"` // Number of blobs to report (default: 20)
 }
 
 // GetCommitDiffParams parameters for get_commit_diff tool
 type GetCommitDiffParams struct {
-	Repository string `json:"repository"`
+	Repository string `json:"repository,omitempty"`
 	CommitHash string `json:"commit_hash"`
+	HunkOffset int    `json:"hunk_offset,omitempty"` // Skip this many hunks (0-based)
+	HunkLimit  int    `json:"hunk_limit,omitempty"`  // Return at most this many hunks (0 = full diff)
 }
 
-// SessionParams parameters for session tool (unified set/get/clear)
-type SessionParams struct {
-	Action                 string   `json:"action"`                            // "set", "get", or "clear"
-	DefaultRepository      string   `json:"default_repository,omitempty"`      // for "set"
-	DefaultIncludePatterns []string `json:"default_include_patterns,omitempty"` // for "set"
-	DefaultExcludePatterns []string `json:"default_exclude_patterns,omitempty"` // for "set"
-	DefaultSearchLimit     int      `json:"default_search_limit,omitempty"`     // for "set"
-	DefaultListFilesLimit  int      `json:"default_list_files_limit,omitempty"` // for "set"
-	DefaultMaxLines        int      `json:"default_max_lines,omitempty"`        // for "set"
-	DefaultCommitLimit     int      `json:"default_commit_limit,omitempty"`     // for "set"
+// GetCommitPatchesParams parameters for get_commit_patches tool
+type GetCommitPatchesParams struct {
+	Repository string `json:"repository,omitempty"`
+	CommitHash string `json:"commit_hash"`
+}
+
+// DiffRefsParams parameters for diff_refs tool
+type DiffRefsParams struct {
+	Repository string   `json:"repository,omitempty"`
+	Base       string   `json:"base"`                // Base ref, e.g. "main"
+	Head       string   `json:"head"`                // Head ref, e.g. "feature/x"
+	Paths      []string `json:"paths,omitempty"`     // Limit the diff to these paths
+	StatOnly   bool     `json:"stat_only,omitempty"` // Show `git diff --stat` summary instead of the patch
+}
+
+// CompareBranchesParams parameters for compare_branches tool
+type CompareBranchesParams struct {
+	Repository string `json:"repository,omitempty"`
+	A          string `json:"a"`               // Branch to report on, e.g. "feature/x"
+	B          string `json:"b"`               // Branch to compare against, e.g. "main"
+	Limit      int    `json:"limit,omitempty"` // Maximum commits listed per side (default: 20; counts are always exact)
+}
+
+// DiffFileParams parameters for diff_file tool
+type DiffFileParams struct {
+	Repository string `json:"repository,omitempty"`
+	FilePath   string `json:"file_path"`
+	Ref        string `json:"ref,omitempty"` // Commit, branch or tag to compare against (default: HEAD)
+}
+
+// FilesDifferingFromBranchParams parameters for files_differing_from_branch tool
+type FilesDifferingFromBranchParams struct {
+	Repository string `json:"repository,omitempty"`
+	Branch     string `json:"branch"`
+}
+
+// GetStatusParams parameters for get_status tool
+type GetStatusParams struct {
+	Repository string `json:"repository,omitempty"`
+}
+
+// ListSubmodulesParams parameters for list_submodules tool
+type ListSubmodulesParams struct {
+	Repository   string `json:"repository,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"` // "text" (default) or "json" ([]Submodule)
+}
+
+// ListRemotesParams parameters for list_remotes tool
+type ListRemotesParams struct {
+	Repository   string `json:"repository,omitempty"`
+	OutputFormat string `json:"output_format,omitempty"` // "text" (default) or "json" ([]Remote)
 }
 
 // BatchParams parameters for batch tool (unified clone/pull/status)
@@ -142,6 +416,8 @@ type RepositoryOverview struct {
 	RemoteURL     string   `json:"remote_url,omitempty"`
 	RecentCommits []Commit `json:"recent_commits,omitempty"`
 	BranchCount   int      `json:"branch_count,omitempty"`
+	SizeBytes     int64    `json:"size_bytes,omitempty"`  // on-disk size, detailed mode only
+	LastCommit    string   `json:"last_commit,omitempty"` // date of the last commit, detailed mode only
 	Error         string   `json:"error,omitempty"`
 }
 
@@ -161,74 +437,255 @@ func RegisterGitTools(server *mcp.Server) {
 	}, handleGetRepositoryInfo)
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "pull_repository",
-		Description: "Git pull on repository",
-	}, handlePullRepository)
+		Name:        "context_pack",
+		Description: "Get README, manifests, recent commits and file tree packed into a byte budget",
+	}, handleContextPack)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "export_overview",
+		Description: "Export metadata, directory tree, README and recent commits as one Markdown document",
+	}, handleExportOverview)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_branches",
 		Description: "List branches in repository",
 	}, handleListBranches)
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "switch_branch",
-		Description: "Switch to branch",
-	}, handleSwitchBranch)
-
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "search_files",
 		Description: "Search files by keywords. Cross-repo via repositories array.",
 	}, handleSearchFiles)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search_in_symbol",
+		Description: "Search for a keyword only inside a named function, method or type",
+	}, handleSearchInSymbol)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "find_related_tests",
+		Description: "Find the test files for a source file, or the source file for a test (Go, JS/TS, Python)",
+	}, handleFindRelatedTests)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "path_context",
+		Description: "Show where a file sits: each ancestor directory up to the repo root with its immediate children",
+	}, handlePathContext)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_files",
 		Description: "List files in directory with pattern filtering",
 	}, handleListFiles)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "filter_files",
+		Description: "List files by line-count or byte-size thresholds (e.g. all files over 500 lines)",
+	}, handleFilterFiles)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_file_content",
-		Description: "Get file content with line range support",
+		Description: "Get file content with line range support (set from_end to read the last lines)",
 	}, handleGetFileContent)
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "clone_repository",
-		Description: "Clone repo. Can include info and branches.",
-	}, handleCloneRepository)
+		Name:        "fetch_citations",
+		Description: "Quote exact line ranges from several files in one call; each range succeeds or fails on its own",
+	}, handleFetchCitations)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_file_ranges",
+		Description: "Read several line ranges of one file in one call, e.g. lines 10-20 and 80-95",
+	}, handleGetFileRanges)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "read",
+		Description: "Read a file from the session default repository by path only",
+	}, handleRead)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_file_metadata",
+		Description: "Get file size, mode, symlink and binary/MIME type without reading content",
+	}, handleGetFileMetadata)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "file_stats",
+		Description: "Get byte, line, word and blank-line counts, longest line and trailing newline of a file",
+	}, handleFileStats)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "page_file",
+		Description: "Read a file page by page in fixed-size chunks, with total page count",
+	}, handlePageFile)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_repositories",
-		Description: "List workspace repos with optional status/commits",
+		Description: "List workspace repos with optional status/commits, or a detailed branch/status/remote table",
 	}, handleListWorkspaceRepositories)
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "remove_repository",
-		Description: "Remove repository from workspace",
-	}, handleRemoveRepository)
+		Name:        "which_repository",
+		Description: "Find which workspace repo an absolute path belongs to",
+	}, handleWhichRepository)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_readme_files",
 		Description: "Find README files in repository",
 	}, handleGetReadmeFiles)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_governance_files",
+		Description: "Find CODEOWNERS, CONTRIBUTING, SECURITY, CODE_OF_CONDUCT and similar files",
+	}, handleListGovernanceFiles)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "lint_files",
+		Description: "Report text files with CRLF/mixed line endings, trailing whitespace, no final newline or invalid UTF-8",
+	}, handleLintFiles)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "list_commits",
-		Description: "List commit history",
+		Description: "List commit history. Filter by author, since, until.",
 	}, handleListCommits)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search_commits",
+		Description: "Search commit messages. all_match=true requires every word.",
+	}, handleSearchCommits)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "file_history",
+		Description: "List commits that touched a file (follows renames)",
+	}, handleFileHistory)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "blame_file",
+		Description: "Show who last changed each line of a file",
+	}, handleBlameFile)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "file_authors",
+		Description: "List who committed to a file, with commit counts (cheaper than blame)",
+	}, handleFileAuthors)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_contributors",
+		Description: "List the repository's contributors with commit counts, most active first",
+	}, handleGetContributors)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_activity_summary",
+		Description: "Summarize recent activity: commits, authors and most changed files over the last N days (default 30)",
+	}, handleGetActivitySummary)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "directory_ownership",
+		Description: "Show each author's share of the current lines in a directory (blame-based ownership)",
+	}, handleDirectoryOwnership)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "line_history",
+		Description: "Show every commit that changed a single line of a file (git log -L)",
+	}, handleLineHistory)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "history_bloat",
+		Description: "Find the largest blobs ever committed (diagnose slow clones)",
+	}, handleHistoryBloat)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_commit_diff",
-		Description: "Get diff for a commit",
+		Description: "Get diff for a commit (use hunk_offset/hunk_limit to page through large diffs hunk by hunk)",
 	}, handleGetCommitDiff)
 
 	mcp.AddTool(server, &mcp.Tool{
-		Name:        "session",
-		Description: "Session config: action=set/get/clear. Set defaults for repo, patterns, limits.",
-	}, handleSession)
+		Name:        "get_commit_patches",
+		Description: "Get a commit's diff split into one patch per file",
+	}, handleGetCommitPatches)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diff_refs",
+		Description: "Compare two branches or commits (changes on head since it diverged from base)",
+	}, handleDiffRefs)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "compare_branches",
+		Description: "Show the commits each of two branches has that the other lacks (ahead/behind) and their merge base",
+	}, handleCompareBranches)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diff_file",
+		Description: "Diff a file's working tree version against a commit, branch or tag",
+	}, handleDiffFile)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "files_differing_from_branch",
+		Description: "List files whose working tree version differs from another branch, without switching",
+	}, handleFilesDifferingFromBranch)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_status",
+		Description: "Show staged, modified and untracked files in the working tree (check before pulling)",
+	}, handleGetStatus)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_remotes",
+		Description: "List all remotes (origin, upstream, forks) with their fetch and push URLs",
+	}, handleListRemotes)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_submodules",
+		Description: "List submodules from .gitmodules with their URL and checked out commit",
+	}, handleListSubmodules)
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "batch",
 		Description: "Batch ops: operation=clone/pull/status on multiple repos",
 	}, handleBatch)
+
+	// Tools that change repository or workspace state are not registered in
+	// read-only mode
+	if readOnlyMode {
+		return
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "clone_repository",
+		Description: "Clone repo. Can include info and branches.",
+	}, handleCloneRepository)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "clone_repositories",
+		Description: "Clone several repos in parallel ({url, name} entries); existing ones are pulled. Reports each result.",
+	}, handleCloneRepositories)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "remove_repository",
+		Description: "Remove repository from workspace",
+	}, handleRemoveRepository)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "rename_repository",
+		Description: "Rename repository in workspace",
+	}, handleRenameRepository)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "pull_repository",
+		Description: "Git pull on repository",
+	}, handlePullRepository)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "fetch_repository",
+		Description: "Git fetch --all --prune (updates remote-tracking refs only, no merge)",
+	}, handleFetchRepository)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "preview_pull",
+		Description: "Fetch and show incoming commits and diff for the current branch without merging",
+	}, handlePreviewPull)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "switch_branch",
+		Description: "Switch to branch. Refuses when the working tree has uncommitted changes unless force is true, which discards them",
+	}, handleSwitchBranch)
 }
 
 func handleGetRepositoryInfo(ctx context.Context, req *mcp.CallToolRequest, args GetRepositoryInfoParams) (*mcp.CallToolResult, any, error) {
@@ -244,8 +701,17 @@ func handleGetRepositoryInfo(ctx context.Context, req *mcp.CallToolRequest, args
 
 	var result strings.Builder
 
+	if args.Refresh {
+		if err := InvalidateRepositoryInfo(repository); err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get repository info: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+	}
+
 	// Get basic repository info
-	info, err := GetRepositoryInfo(repository)
+	info, err := GetRepositoryInfoWithReadmePreview(repository, sc.GetReadmePreviewLines(args.ReadmeLines))
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get repository info: %v", err)}},
@@ -256,14 +722,25 @@ func handleGetRepositoryInfo(ctx context.Context, req *mcp.CallToolRequest, args
 	result.WriteString(fmt.Sprintf("Repository: %s\n", info.Path))
 	result.WriteString(strings.Repeat("=", 50) + "\n\n")
 	result.WriteString(fmt.Sprintf("Branch: %s\n", info.CurrentBranch))
+	if info.DefaultBranch != "" {
+		result.WriteString(fmt.Sprintf("Default Branch: %s\n", info.DefaultBranch))
+	}
 	if !info.LastUpdate.IsZero() {
 		result.WriteString(fmt.Sprintf("Updated: %s\n", info.LastUpdate.Format("2006-01-02 15:04:05")))
 	}
-	if info.RemoteURL != "" {
+	if args.IncludeRemotes {
+		if remotes, err := ListRemotes(repository); err == nil && len(remotes) > 0 {
+			result.WriteString("Remotes:\n")
+			writeRemotes(&result, remotes)
+		}
+	} else if info.RemoteURL != "" {
 		result.WriteString(fmt.Sprintf("Remote: %s\n", info.RemoteURL))
 	}
 	if info.License != "" {
-		result.WriteString(fmt.Sprintf("License: %s\n", info.License))
+		result.WriteString(fmt.Sprintf("License: %s\n", formatLicense(info)))
+	}
+	if info.SubmoduleCount > 0 {
+		result.WriteString(fmt.Sprintf("Submodules: %d (see list_submodules)\n", info.SubmoduleCount))
 	}
 
 	// File statistics (always shown)
@@ -328,7 +805,7 @@ func handleGetRepositoryInfo(ctx context.Context, req *mcp.CallToolRequest, args
 				result.WriteString("  No memos found for this repository\n")
 			} else {
 				for _, memo := range memos {
-					result.WriteString(fmt.Sprintf("  📝 %s\n", memo.Title))
+					result.WriteString(fmt.Sprintf("  %s %s\n", markers().Memo, memo.Title))
 					result.WriteString(fmt.Sprintf("     ID: %s\n", memo.ID))
 					if len(memo.Tags) > 0 {
 						result.WriteString(fmt.Sprintf("     Tags: %s\n", strings.Join(memo.Tags, ", ")))
@@ -362,122 +839,258 @@ func handleGetRepositoryInfo(ctx context.Context, req *mcp.CallToolRequest, args
 	}, nil, nil
 }
 
-func handlePullRepository(ctx context.Context, req *mcp.CallToolRequest, args PullRepositoryParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+func handleContextPack(ctx context.Context, req *mcp.CallToolRequest, args ContextPackParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	output, err := PullRepository(args.Repository)
+	maxBytes := args.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultContextPackBytes
+	}
+
+	pack, err := BuildContextPack(repository, maxBytes)
 	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Pull failed: %v\nOutput: %s", err, output)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to build context pack: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	resultText := fmt.Sprintf("Git pull completed successfully:\n%s", output)
 	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+		Content: []mcp.Content{&mcp.TextContent{Text: pack.Content}},
 	}, nil, nil
 }
 
-func handleListBranches(ctx context.Context, req *mcp.CallToolRequest, args ListBranchesParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+func handleExportOverview(ctx context.Context, req *mcp.CallToolRequest, args ExportOverviewParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	branches, err := ListBranches(args.Repository)
+	markdown, err := ExportRepositoryOverview(repository, args.MaxFilesInTree, args.ReadmeLines)
 	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list branches: %v", err)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to export overview: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	// Apply limit if specified
-	if args.Limit > 0 && len(branches) > args.Limit {
-		branches = branches[:args.Limit]
-	}
-
-	resultText := formatBranches(branches, args.Limit > 0)
 	return &mcp.CallToolResult{
-		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+		Content: []mcp.Content{&mcp.TextContent{Text: markdown}},
 	}, nil, nil
 }
 
-func handleSwitchBranch(ctx context.Context, req *mcp.CallToolRequest, args SwitchBranchParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+func handlePullRepository(ctx context.Context, req *mcp.CallToolRequest, args PullRepositoryParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	if args.Branch == "" {
+	output, err := PullRepository(repository)
+	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: branch name is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Pull failed: %v\nOutput: %s", err, output)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := fmt.Sprintf("Git pull completed successfully:\n%s", output)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleFetchRepository(ctx context.Context, req *mcp.CallToolRequest, args FetchRepositoryParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	output, err := SwitchBranch(args.Repository, args.Branch)
+	output, err := FetchRepository(repository)
 	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Branch switch failed: %v\nOutput: %s", err, output)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Fetch failed: %v\nOutput: %s", err, output)}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	resultText := fmt.Sprintf("Successfully switched to branch '%s':\n%s", args.Branch, output)
+	if strings.TrimSpace(output) == "" {
+		output = "Remote-tracking refs already up to date\n"
+	}
+	resultText := fmt.Sprintf("Git fetch completed successfully:\n%s", output)
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
-func handleSearchFiles(ctx context.Context, req *mcp.CallToolRequest, args SearchFilesParams) (*mcp.CallToolResult, any, error) {
-	if len(args.Keywords) == 0 {
+func handlePreviewPull(ctx context.Context, req *mcp.CallToolRequest, args PreviewPullParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: at least one keyword is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	sc := GetSessionConfig()
+	preview, err := PreviewPull(repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to preview pull: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 
-	// Default limit to prevent token overflow
-	limit := sc.GetSearchLimit(args.Limit)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatPullPreview(preview)}},
+	}, nil, nil
+}
+
+func handleListBranches(ctx context.Context, req *mcp.CallToolRequest, args ListBranchesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	branches, err := ListBranchesWithTracking(repository, args.IncludeTracking)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list branches: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	// Apply limit if specified
+	if args.Limit > 0 && len(branches) > args.Limit {
+		branches = branches[:args.Limit]
+	}
+
+	resultText := formatBranches(branches, args.Limit > 0)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleSwitchBranch(ctx context.Context, req *mcp.CallToolRequest, args SwitchBranchParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if args.Branch == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: branch name is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	output, err := SwitchBranch(repository, args.Branch, args.Force)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Branch switch failed: %v\nOutput: %s", err, output)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := fmt.Sprintf("Successfully switched to branch '%s':\n%s", args.Branch, output)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleSearchFiles(ctx context.Context, req *mcp.CallToolRequest, args SearchFilesParams) (*mcp.CallToolResult, any, error) {
+	if len(args.Keywords) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: at least one keyword is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	sc := GetSessionConfig()
+
+	// Default limit to prevent token overflow
+	limit := sc.GetSearchLimit(args.Limit)
 
 	// Default search mode to "and"
 	searchMode := args.SearchMode
-	if searchMode == "" {
+	switch searchMode {
+	case "":
 		searchMode = "and"
+	case "and", "or", "phrase":
+	default:
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: unknown search_mode %q (expected and, or or phrase)", args.SearchMode)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	switch args.OutputFormat {
+	case "", "default", "text", "grouped", "json":
+	default:
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: unknown output_format %q (expected text, grouped or json)", args.OutputFormat)}},
+			IsError: true,
+		}, nil, nil
 	}
 
 	includePatterns := sc.GetIncludePatterns(args.IncludePatterns)
 	excludePatterns := sc.GetExcludePatterns(args.ExcludePatterns)
 
+	maxMatches := args.MaxMatchesPerFile
+	if maxMatches == 0 {
+		maxMatches = DefaultMaxMatchesPerFile
+	}
+
+	search := SearchFiles
+	if args.FunctionContext {
+		search = SearchFilesWithFunctionContext
+	}
+
 	// Multi-repository search if repositories array is provided
 	if len(args.Repositories) > 0 {
 		var allResults []RepoSearchResult
 
 		for _, repoName := range args.Repositories {
 			repoResult := RepoSearchResult{Repository: repoName}
-			results, err := SearchFiles(repoName, args.Keywords, searchMode, args.IncludeFilename, args.ContextLines, includePatterns, excludePatterns, limit)
+			results, err := search(repoName, args.Keywords, searchMode, args.IncludeFilename, args.ContextLines, includePatterns, excludePatterns, args.ExcludeKeywords, limit)
 			if err != nil {
 				repoResult.Error = err.Error()
 			} else {
-				repoResult.Results = results
+				repoResult.Results = limitMatchesPerFile(results, maxMatches)
 				repoResult.TotalCount = len(results)
 			}
 			allResults = append(allResults, repoResult)
 		}
 
+		if args.OutputFormat == "json" {
+			return jsonResult(allResults)
+		}
 		resultText := formatMultiRepoSearchResults(allResults, args.Keywords, searchMode)
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
@@ -493,24 +1106,162 @@ func handleSearchFiles(ctx context.Context, req *mcp.CallToolRequest, args Searc
 		}, nil, nil
 	}
 
-	results, err := SearchFiles(repository, args.Keywords, searchMode, args.IncludeFilename, args.ContextLines, includePatterns, excludePatterns, limit)
+	results, err := search(repository, args.Keywords, searchMode, args.IncludeFilename, args.ContextLines, includePatterns, excludePatterns, args.ExcludeKeywords, limit)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Search failed: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
+	results = limitMatchesPerFile(results, maxMatches)
 
-	resultText := formatSearchResults(results, args.Keywords, searchMode)
+	if args.OutputFormat == "json" {
+		return jsonResult(nonNil(results))
+	}
+	var resultText string
+	if args.OutputFormat == "grouped" {
+		resultText = formatGroupedSearchResults(results, args.Keywords, searchMode)
+	} else {
+		resultText = formatSearchResults(results, args.Keywords, searchMode)
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
+func handleSearchInSymbol(ctx context.Context, req *mcp.CallToolRequest, args SearchInSymbolParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Symbol == "" || args.Keyword == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: symbol and keyword are required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	matches, err := SearchInSymbol(repository, args.Symbol, args.Keyword, args.Language)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search in symbol: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatSymbolMatches(args.Symbol, args.Keyword, matches)}},
+	}, nil, nil
+}
+
+func handleFindRelatedTests(ctx context.Context, req *mcp.CallToolRequest, args FindRelatedTestsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	related, err := FindRelatedTests(repository, args.FilePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find related tests: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Related files for %s (%d found):\n", args.FilePath, len(related)))
+	result.WriteString(strings.Repeat("-", 50) + "\n")
+	if len(related) == 0 {
+		result.WriteString("No related files found.\n")
+	}
+	for _, path := range related {
+		result.WriteString(fmt.Sprintf("%s %s\n", markers().File, path))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
+func handlePathContext(ctx context.Context, req *mcp.CallToolRequest, args PathContextParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	pathContext, err := GetPathContext(repository, args.FilePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get path context: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatPathContext(pathContext)}},
+	}, nil, nil
+}
+
+// formatPathContext renders one block per ancestor directory, marking the
+// entry that leads to the requested path
+func formatPathContext(pc *PathContext) string {
+	m := markers()
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Path context for %s:\n", pc.Path))
+	result.WriteString(strings.Repeat("-", 50) + "\n")
+	for _, level := range pc.Levels {
+		dir := level.Dir
+		if dir == "" {
+			dir = "(repository root)"
+		}
+		result.WriteString(fmt.Sprintf("\n%s %s\n", m.Dir, dir))
+		for _, entry := range level.Entries {
+			marker, name := m.File, entry.Name
+			if entry.IsDir {
+				marker, name = m.Dir, entry.Name+"/"
+			}
+			if entry.OnPath {
+				result.WriteString(fmt.Sprintf("  %s %s %s\n", m.Arrow, marker, name))
+			} else {
+				result.WriteString(fmt.Sprintf("    %s %s\n", marker, name))
+			}
+		}
+	}
+
+	return result.String()
+}
+
 func handleListFiles(ctx context.Context, req *mcp.CallToolRequest, args ListFilesParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
@@ -520,13 +1271,23 @@ func handleListFiles(ctx context.Context, req *mcp.CallToolRequest, args ListFil
 		directory = "."
 	}
 
+	if errResult := checkTextOrJSON(args.OutputFormat); errResult != nil {
+		return errResult, nil, nil
+	}
+
 	// Default limit to prevent token overflow
-	limit := args.Limit
-	if limit == 0 {
-		limit = 50
+	limit := sc.GetListFilesLimit(args.Limit)
+	includePatterns := sc.GetIncludePatterns(args.IncludePatterns)
+	excludePatterns := sc.GetExcludePatterns(args.ExcludePatterns)
+
+	if args.MaxDepth < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: max_depth must not be negative"}},
+			IsError: true,
+		}, nil, nil
 	}
 
-	files, err := ListFiles(args.Repository, directory, args.Recursive, args.IncludePatterns, args.ExcludePatterns, limit)
+	files, truncated, err := ListFilesWithDepth(repository, directory, args.Recursive, includePatterns, excludePatterns, limit, args.MaxDepth)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list files: %v", err)}},
@@ -534,26 +1295,89 @@ func handleListFiles(ctx context.Context, req *mcp.CallToolRequest, args ListFil
 		}, nil, nil
 	}
 
-	resultText := formatFileList(files, directory, args.Recursive, limit)
+	if args.OutputFormat == "json" {
+		return jsonResult(nonNil(files))
+	}
+	var resultText string
+	if args.Recursive && args.Tree {
+		resultText = formatFileTree(files, directory, limit)
+	} else {
+		resultText = formatFileList(files, directory, args.Recursive, limit)
+	}
+	if truncated {
+		depth := args.MaxDepth
+		if depth == 0 || depth > maxListDepth {
+			depth = maxListDepth
+		}
+		resultText += fmt.Sprintf("\n(Stopped at depth %d; deeper directories were not listed)", depth)
+	}
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
+func handleFilterFiles(ctx context.Context, req *mcp.CallToolRequest, args FilterFilesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.MinLines == 0 && args.MaxLines == 0 && args.MinBytes == 0 && args.MaxBytes == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: at least one of min_lines, max_lines, min_bytes or max_bytes is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	directory := args.Directory
+	if directory == "" {
+		directory = "."
+	}
+
+	files, err := FilterFilesByMetrics(repository, directory, args.MinLines, args.MaxLines, args.MinBytes, args.MaxBytes, args.Recursive)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to filter files: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := sc.GetListFilesLimit(args.Limit)
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatFileList(files, directory, args.Recursive, limit)}},
+	}, nil, nil
+}
+
 func handleGetFileContent(ctx context.Context, req *mcp.CallToolRequest, args GetFileContentParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	// Determine which file paths to use (backward compatibility)
+	// Determine which file paths to use (backward compatibility).
+	// When both are given, file_path is read first followed by file_paths,
+	// with duplicates removed.
 	var filePaths []string
-	if len(args.FilePaths) > 0 {
-		filePaths = args.FilePaths
-	} else if args.FilePath != "" {
-		filePaths = []string{args.FilePath}
+	if args.FilePath != "" || len(args.FilePaths) > 0 {
+		seen := make(map[string]bool)
+		for _, path := range append([]string{args.FilePath}, args.FilePaths...) {
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			filePaths = append(filePaths, path)
+		}
 	} else {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file path(s) required"}},
@@ -569,7 +1393,9 @@ func handleGetFileContent(ctx context.Context, req *mcp.CallToolRequest, args Ge
 
 	// Calculate maxLines: end_line > max_lines > session default > 100
 	var maxLines int
-	if args.EndLine > 0 {
+	if args.FromEnd {
+		maxLines = sc.GetMaxLines(args.MaxLines)
+	} else if args.EndLine > 0 {
 		if args.EndLine < startLine {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: end_line (%d) must be >= start_line (%d)", args.EndLine, startLine)}},
@@ -579,28 +1405,62 @@ func handleGetFileContent(ctx context.Context, req *mcp.CallToolRequest, args Ge
 		maxLines = args.EndLine - startLine + 1
 	} else {
 		// Use session config default (falls back to 100 if not set)
-		maxLines = GetSessionConfig().GetMaxLines(args.MaxLines)
+		maxLines = sc.GetMaxLines(args.MaxLines)
+	}
+
+	if errResult := checkTextOrJSON(args.OutputFormat); errResult != nil {
+		return errResult, nil, nil
+	}
+
+	encoding, err := normalizeEncoding(args.Encoding)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
 	}
 
-	showLineNumbers := true
+	// JSON carries start and end lines as fields, so the content stays raw
+	showLineNumbers := args.OutputFormat != "json"
 
 	if len(filePaths) == 1 {
 		// Single file
-		content, totalLines, actualStart, actualEnd, err := GetFileContentWithLineNumbers(args.Repository, filePaths[0], startLine, maxLines, showLineNumbers)
+		if !args.ForceBinary {
+			if validPath, err := ValidateWorkspacePath(repository); err == nil {
+				fullPath, err := ResolveWorkspaceFile(validPath, filePaths[0])
+				if err == nil {
+					err = checkNotBinary(fullPath)
+				}
+				if err != nil {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s ERR:%v]", filePaths[0], err)}},
+						IsError: true,
+					}, nil, nil
+				}
+			}
+		}
+
+		fileResult, err := readFileLines(repository, filePaths[0], startLine, maxLines, showLineNumbers, args.FromEnd, encoding)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s ERR:%v]", filePaths[0], err)}},
 				IsError: true,
 			}, nil, nil
 		}
+		if args.TrimTrailingWhitespace {
+			fileResult.Content = trimTrailingWhitespace(fileResult.Content)
+		}
 
-		resultText := fmt.Sprintf("[%s L%d-%d/%d]\n%s", filePaths[0], actualStart, actualEnd, totalLines, content)
+		if args.OutputFormat == "json" {
+			return jsonResult([]FileContentResult{fileResult})
+		}
+		resultText := fileContentHeader(fileResult) + fileResult.Content
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 		}, nil, nil
 	} else {
 		// Multiple files
-		results, err := GetMultipleFileContentsWithLineNumbers(args.Repository, filePaths, startLine, maxLines, showLineNumbers)
+		results, err := getMultipleFileContents(repository, filePaths, startLine, maxLines, showLineNumbers, args.ForceBinary, args.FromEnd, encoding)
 		if err != nil {
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("ERR:%v", err)}},
@@ -608,6 +1468,15 @@ func handleGetFileContent(ctx context.Context, req *mcp.CallToolRequest, args Ge
 			}, nil, nil
 		}
 
+		if args.TrimTrailingWhitespace {
+			for i := range results {
+				results[i].Content = trimTrailingWhitespace(results[i].Content)
+			}
+		}
+
+		if args.OutputFormat == "json" {
+			return jsonResult(results)
+		}
 		resultText := formatMultipleFileContents(results)
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
@@ -615,10 +1484,223 @@ func handleGetFileContent(ctx context.Context, req *mcp.CallToolRequest, args Ge
 	}
 }
 
-func handleCloneRepository(ctx context.Context, req *mcp.CallToolRequest, args CloneRepositoryParams) (*mcp.CallToolResult, any, error) {
-	if args.URL == "" {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository URL is required"}},
+// trimTrailingWhitespace strips trailing spaces, tabs and carriage returns
+// from every line of content
+func trimTrailingWhitespace(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkTextOrJSON returns an error result for an output_format other than
+// "text" (or empty) and "json"
+func checkTextOrJSON(format string) *mcp.CallToolResult {
+	switch format {
+	case "", "text", "json":
+		return nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: unknown output_format %q (expected text or json)", format)}},
+		IsError: true,
+	}
+}
+
+// jsonResult returns v marshaled as indented JSON in the tool result text
+func jsonResult(v any) (*mcp.CallToolResult, any, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to encode JSON: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: string(data)}},
+	}, nil, nil
+}
+
+// nonNil turns a nil slice into an empty one so it encodes as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func handleFetchCitations(ctx context.Context, req *mcp.CallToolRequest, args FetchCitationsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if len(args.Citations) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: at least one citation is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	results, err := FetchCitations(repository, args.Citations)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to fetch citations: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatMultipleFileContents(results)}},
+	}, nil, nil
+}
+
+func handleGetFileRanges(ctx context.Context, req *mcp.CallToolRequest, args GetFileRangesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	results, err := GetFileRanges(repository, args.FilePath, args.Ranges)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to read ranges: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatMultipleFileContents(results)}},
+	}, nil, nil
+}
+
+func handleRead(ctx context.Context, req *mcp.CallToolRequest, args ReadParams) (*mcp.CallToolResult, any, error) {
+	repository := GetSessionConfig().GetRepository("")
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: read needs a default repository (set one with set_session_config)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Path == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return handleGetFileContent(ctx, req, GetFileContentParams{
+		Repository: repository,
+		FilePath:   args.Path,
+		StartLine:  args.StartLine,
+		EndLine:    args.EndLine,
+	})
+}
+
+func handleGetFileMetadata(ctx context.Context, req *mcp.CallToolRequest, args GetFileMetadataParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	metadata, err := GetFileMetadata(repository, args.FilePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s ERR:%v]", args.FilePath, err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatFileMetadata(metadata)}},
+	}, nil, nil
+}
+
+func handleFileStats(ctx context.Context, req *mcp.CallToolRequest, args FileStatsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	stats, err := GetFileStats(repository, args.FilePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s ERR:%v]", args.FilePath, err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatFileStats(stats)}},
+	}, nil, nil
+}
+
+func handlePageFile(ctx context.Context, req *mcp.CallToolRequest, args PageFileParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	page := args.Page
+	if page == 0 {
+		page = 1
+	}
+
+	filePage, err := GetFilePage(repository, args.FilePath, page, sc.GetMaxLines(args.PageSize))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("[%s ERR:%v]", args.FilePath, err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := fmt.Sprintf("[%s page %d/%d L%d-%d/%d]\n%s", filePage.FilePath, filePage.Page, filePage.TotalPages, filePage.StartLine, filePage.EndLine, filePage.TotalLines, filePage.Content)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleCloneRepository(ctx context.Context, req *mcp.CallToolRequest, args CloneRepositoryParams) (*mcp.CallToolResult, any, error) {
+	if args.URL == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository URL is required"}},
 			IsError: true,
 		}, nil, nil
 	}
@@ -627,7 +1709,20 @@ func handleCloneRepository(ctx context.Context, req *mcp.CallToolRequest, args C
 	var repoName string
 	var cloneSuccess bool
 
-	output, actualName, err := CloneRepository(args.URL, args.Name)
+	if args.Depth < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: depth must be non-negative, got %d", args.Depth)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	output, actualName, err := CloneRepositoryWithOptions(ctx, args.URL, args.Name, CloneOptions{
+		Depth:      args.Depth,
+		Branch:     args.Branch,
+		Username:   args.Username,
+		Token:      args.Token,
+		SSHKeyPath: args.SSHKeyPath,
+	})
 	repoName = actualName
 
 	if err != nil {
@@ -655,8 +1750,21 @@ func handleCloneRepository(ctx context.Context, req *mcp.CallToolRequest, args C
 			}, nil, nil
 		}
 	} else {
+		var details []string
+		if args.Name == "" {
+			details = append(details, "from URL")
+		}
+		if args.Depth > 0 {
+			details = append(details, fmt.Sprintf("shallow clone, depth %d", args.Depth))
+		}
+		if args.Branch != "" {
+			details = append(details, fmt.Sprintf("branch %s only", args.Branch))
+		}
+
 		if args.Name == "" {
-			result.WriteString(fmt.Sprintf("Cloned as '%s' (from URL):\n%s\n", actualName, strings.TrimSpace(output)))
+			result.WriteString(fmt.Sprintf("Cloned as '%s' (%s):\n%s\n", actualName, strings.Join(details, ", "), strings.TrimSpace(output)))
+		} else if len(details) > 0 {
+			result.WriteString(fmt.Sprintf("Cloned '%s' (%s):\n%s\n", actualName, strings.Join(details, ", "), strings.TrimSpace(output)))
 		} else {
 			result.WriteString(fmt.Sprintf("Cloned '%s':\n%s\n", actualName, strings.TrimSpace(output)))
 		}
@@ -699,6 +1807,98 @@ func handleCloneRepository(ctx context.Context, req *mcp.CallToolRequest, args C
 	}, nil, nil
 }
 
+func handleCloneRepositories(ctx context.Context, req *mcp.CallToolRequest, args CloneRepositoriesParams) (*mcp.CallToolResult, any, error) {
+	if len(args.Repositories) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repositories array is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Depth < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: depth must be non-negative, got %d", args.Depth)}},
+			IsError: true,
+		}, nil, nil
+	}
+	if GetWorkspaceManager() == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: workspace not initialized"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	results := cloneRepositories(ctx, args.Repositories, CloneOptions{Depth: args.Depth})
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatBatchResults("clone", results)}},
+	}, nil, nil
+}
+
+// maxConcurrentClones bounds the git clone processes run at once by cloneRepositories
+const maxConcurrentClones = 4
+
+// cloneRepositories clones the entries in parallel, pulling those that already
+// exist in the workspace. A failed entry is reported in its result without
+// stopping the others. Results keep the order of entries.
+func cloneRepositories(ctx context.Context, entries []CloneRepositoryEntry, opts CloneOptions) []BatchResult {
+	results := make([]BatchResult, len(entries))
+	sem := make(chan struct{}, maxConcurrentClones)
+	var wg sync.WaitGroup
+
+	// Names are settled up front: two entries cloning into the same directory
+	// at once would clobber each other
+	claimed := make(map[string]bool)
+	for i, entry := range entries {
+		name := entry.Name
+		if name == "" && entry.URL != "" {
+			name, _ = extractRepoNameFromURL(entry.URL)
+		}
+		if name != "" && claimed[name] {
+			results[i] = BatchResult{Name: name, URL: entry.URL, Error: fmt.Sprintf("'%s' is already used by another entry in this batch", name)}
+			continue
+		}
+		claimed[name] = true
+
+		wg.Add(1)
+		go func(i int, url, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = cloneOrPullRepository(ctx, url, name, opts)
+		}(i, entry.URL, name)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// cloneOrPullRepository clones url into the workspace as name, or pulls the
+// repository if name already exists
+func cloneOrPullRepository(ctx context.Context, url, name string, opts CloneOptions) BatchResult {
+	result := BatchResult{URL: url, Name: name}
+	output, actualName, err := CloneRepositoryWithOptions(ctx, url, name, opts)
+	if actualName != "" {
+		result.Name = actualName
+	}
+
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists in workspace") {
+			pullOutput, pullErr := PullRepository(actualName)
+			if pullErr != nil {
+				result.Error = fmt.Sprintf("Already exists but pull failed: %v", pullErr)
+			} else {
+				result.Success = true
+				result.Message = fmt.Sprintf("Already exists, pulled: %s", strings.TrimSpace(pullOutput))
+			}
+		} else {
+			result.Error = err.Error()
+		}
+	} else {
+		result.Success = true
+		result.Message = fmt.Sprintf("Cloned: %s", strings.TrimSpace(output))
+	}
+	return result
+}
+
 func handleListWorkspaceRepositories(ctx context.Context, req *mcp.CallToolRequest, args ListWorkspaceRepositoriesParams) (*mcp.CallToolResult, any, error) {
 	wm := GetWorkspaceManager()
 	if wm == nil {
@@ -716,6 +1916,14 @@ func handleListWorkspaceRepositories(ctx context.Context, req *mcp.CallToolReque
 		}, nil, nil
 	}
 
+	// Detailed mode: one table row per repository
+	if args.Detailed {
+		resultText := formatRepositoryTable(wm.GetWorkspaceDir(), collectRepositoryDetails(repositories))
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+		}, nil, nil
+	}
+
 	// Extended mode: include status and/or commits
 	if args.IncludeStatus || args.IncludeCommits {
 		sc := GetSessionConfig()
@@ -771,6 +1979,47 @@ func handleListWorkspaceRepositories(ctx context.Context, req *mcp.CallToolReque
 	}, nil, nil
 }
 
+// maxConcurrentRepoQueries bounds the git processes run at once by collectRepositoryDetails
+const maxConcurrentRepoQueries = 8
+
+// collectRepositoryDetails looks up the branch, clean/dirty status, remote URL,
+// on-disk size and last commit date of each repository in parallel. Sizes are
+// computed by walking each repository. Results keep the order of repoNames.
+func collectRepositoryDetails(repoNames []string) []RepositoryOverview {
+	overviews := make([]RepositoryOverview, len(repoNames))
+	sem := make(chan struct{}, maxConcurrentRepoQueries)
+	var wg sync.WaitGroup
+
+	for i, repoName := range repoNames {
+		wg.Add(1)
+		go func(i int, repoName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			overview := RepositoryOverview{Name: repoName}
+			status, err := GetRepositoryStatus(repoName)
+			if err != nil {
+				overview.Error = err.Error()
+			} else {
+				overview.CurrentBranch = status.CurrentBranch
+				overview.HasChanges = status.HasChanges
+				if validPath, err := ValidateWorkspacePath(repoName); err == nil {
+					overview.RemoteURL, _ = getRemoteURL(validPath)
+					overview.SizeBytes, _ = directorySize(validPath)
+					if lastCommit, err := getLastCommit(validPath); err == nil {
+						overview.LastCommit = lastCommit.Format("2006-01-02")
+					}
+				}
+			}
+			overviews[i] = overview
+		}(i, repoName)
+	}
+
+	wg.Wait()
+	return overviews
+}
+
 func handleRemoveRepository(ctx context.Context, req *mcp.CallToolRequest, args RemoveRepositoryParams) (*mcp.CallToolResult, any, error) {
 	if args.Name == "" {
 		return &mcp.CallToolResult{
@@ -801,89 +2050,779 @@ func handleRemoveRepository(ctx context.Context, req *mcp.CallToolRequest, args
 	}, nil, nil
 }
 
-func handleGetReadmeFiles(ctx context.Context, req *mcp.CallToolRequest, args GetReadmeFilesParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+func handleRenameRepository(ctx context.Context, req *mcp.CallToolRequest, args RenameRepositoryParams) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" || args.NewName == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: name and new_name are required"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	readmeFiles, err := GetReadmeFiles(args.Repository, args.Recursive)
-	if err != nil {
+	wm := GetWorkspaceManager()
+	if wm == nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find README files: %v", err)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: workspace not initialized"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	resultText := formatReadmeFiles(readmeFiles, args.Recursive)
+	if err := wm.RenameRepository(args.Name, args.NewName); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to rename repository: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := fmt.Sprintf("Successfully renamed repository '%s' to '%s'", args.Name, args.NewName)
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
-// Formatting functions
-
-func handleListCommits(ctx context.Context, req *mcp.CallToolRequest, args ListCommitsParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
+func handleWhichRepository(ctx context.Context, req *mcp.CallToolRequest, args WhichRepositoryParams) (*mcp.CallToolResult, any, error) {
+	if args.Path == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: path is required"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	limit := args.Limit
-	if limit == 0 {
-		limit = 20 // Default limit
-	}
-
-	commits, err := ListCommits(args.Repository, limit)
+	repoName, relPath, err := WhichRepository(args.Path)
 	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list commits: %v", err)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to resolve repository: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	resultText := formatCommits(commits, limit)
+	resultText := fmt.Sprintf("Repository: %s\nPath: %s\n", repoName, relPath)
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
-func handleGetCommitDiff(ctx context.Context, req *mcp.CallToolRequest, args GetCommitDiffParams) (*mcp.CallToolResult, any, error) {
-	if args.Repository == "" {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository path is required"}},
-			IsError: true,
-		}, nil, nil
-	}
-	if args.CommitHash == "" {
+func handleGetReadmeFiles(ctx context.Context, req *mcp.CallToolRequest, args GetReadmeFilesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: commit_hash is required"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
 			IsError: true,
 		}, nil, nil
 	}
 
-	diff, err := GetCommitDiff(args.Repository, args.CommitHash)
+	// Session include patterns are meant for code searches and would hide
+	// READMEs, so only the session's exclude patterns apply
+	readmeFiles, err := GetReadmeFiles(repository, args.Recursive, args.IncludePatterns, sc.GetExcludePatterns(args.ExcludePatterns))
 	if err != nil {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get commit diff: %v", err)}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find README files: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
+	if args.IncludeContent {
+		if validPath, err := ValidateWorkspacePath(repository); err == nil {
+			AddReadmeContents(validPath, readmeFiles, sc.GetMaxLines(args.MaxLines))
+		}
+	}
 
-	resultText := formatCommitDiff(args.CommitHash, diff)
+	resultText := formatReadmeFiles(readmeFiles, args.Recursive)
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
 
-func formatCommits(commits []Commit, limit int) string {
-	var result strings.Builder
+func handleListGovernanceFiles(ctx context.Context, req *mcp.CallToolRequest, args ListGovernanceFilesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	files, err := FindGovernanceFiles(repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find governance files: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.PreviewLines > 0 {
+		AddGovernancePreviews(repository, files, args.PreviewLines)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatGovernanceFiles(files)}},
+	}, nil, nil
+}
+
+func handleLintFiles(ctx context.Context, req *mcp.CallToolRequest, args LintFilesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	issues, err := LintFiles(repository, sc.GetIncludePatterns(args.IncludePatterns))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to lint files: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatLintIssues(issues)}},
+	}, nil, nil
+}
+
+// Formatting functions
+
+func handleListCommits(ctx context.Context, req *mcp.CallToolRequest, args ListCommitsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if errResult := checkTextOrJSON(args.OutputFormat); errResult != nil {
+		return errResult, nil, nil
+	}
+
+	limit := sc.GetCommitLimit(args.Limit)
+
+	filter := CommitFilter{
+		Author: args.Author,
+		Since:  args.Since,
+		Until:  args.Until,
+		Before: args.Before,
+	}
+
+	commits, err := ListCommitsWithFilter(repository, limit, filter)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list commits: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if args.OutputFormat == "json" {
+		return jsonResult(nonNil(commits))
+	}
+	resultText := formatCommits(commits, limit, filter)
+	if len(commits) == limit {
+		resultText += fmt.Sprintf("Next cursor: %s (pass as before for the next page)\n", commits[len(commits)-1].Hash)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleSearchCommits(ctx context.Context, req *mcp.CallToolRequest, args SearchCommitsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: query is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := sc.GetCommitLimit(args.Limit)
+
+	filter := CommitFilter{Grep: []string{args.Query}}
+	if args.AllMatch {
+		filter = CommitFilter{Grep: strings.Fields(args.Query), AllMatch: true}
+	}
+
+	commits, err := ListCommitsWithFilter(repository, limit, filter)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search commits: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := formatCommits(commits, limit, filter)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleFileHistory(ctx context.Context, req *mcp.CallToolRequest, args FileHistoryParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := sc.GetCommitLimit(args.Limit)
+
+	commits, err := GetFileHistory(repository, args.FilePath, limit)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get file history: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := formatFileHistory(args.FilePath, commits, limit)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleBlameFile(ctx context.Context, req *mcp.CallToolRequest, args BlameFileParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	lines, err := BlameFile(repository, args.FilePath, args.StartLine, args.EndLine)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to blame file: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatBlame(args.FilePath, lines)}},
+	}, nil, nil
+}
+
+func handleFileAuthors(ctx context.Context, req *mcp.CallToolRequest, args FileAuthorsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	authors, err := FileAuthors(repository, args.FilePath)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get file authors: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatFileAuthors(args.FilePath, authors)}},
+	}, nil, nil
+}
+
+func handleGetContributors(ctx context.Context, req *mcp.CallToolRequest, args GetContributorsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	contributors, err := GetContributors(repository, limit)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get contributors: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatContributors(contributors, limit)}},
+	}, nil, nil
+}
+
+func handleGetActivitySummary(ctx context.Context, req *mcp.CallToolRequest, args GetActivitySummaryParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Days < 0 || args.TopFiles < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: days and top_files must not be negative"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	days := args.Days
+	if days == 0 {
+		days = 30
+	}
+	topFiles := args.TopFiles
+	if topFiles == 0 {
+		topFiles = 10
+	}
+
+	summary, err := GetActivitySummary(repository, days, topFiles)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get activity summary: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatActivitySummary(summary)}},
+	}, nil, nil
+}
+
+func handleDirectoryOwnership(ctx context.Context, req *mcp.CallToolRequest, args DirectoryOwnershipParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	directory := args.Directory
+	if directory == "" {
+		directory = "."
+	}
+
+	shares, err := DirectoryOwnership(repository, directory)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute ownership: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatDirectoryOwnership(directory, shares)}},
+	}, nil, nil
+}
+
+func handleLineHistory(ctx context.Context, req *mcp.CallToolRequest, args LineHistoryParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" || args.Line < 1 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path and line (>= 1) are required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	changes, err := LineHistory(repository, args.FilePath, args.Line, sc.GetCommitLimit(args.Limit))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get line history: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatLineHistory(args.FilePath, args.Line, changes)}},
+	}, nil, nil
+}
+
+func handleHistoryBloat(ctx context.Context, req *mcp.CallToolRequest, args HistoryBloatParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	blobs, err := FindLargeBlobsInHistory(repository, limit)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to scan history: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatHistoryBloat(blobs)}},
+	}, nil, nil
+}
+
+func handleGetCommitDiff(ctx context.Context, req *mcp.CallToolRequest, args GetCommitDiffParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.CommitHash == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: commit_hash is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if args.HunkOffset != 0 || args.HunkLimit > 0 {
+		hunks, total, err := GetCommitDiffHunks(repository, args.CommitHash, args.HunkOffset, args.HunkLimit)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get commit diff: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: formatCommitDiffHunks(args.CommitHash, hunks, args.HunkOffset, total)}},
+		}, nil, nil
+	}
+
+	diff, err := GetCommitDiff(repository, args.CommitHash)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get commit diff: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	resultText := formatCommitDiff(diff)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
+	}, nil, nil
+}
+
+func handleGetCommitPatches(ctx context.Context, req *mcp.CallToolRequest, args GetCommitPatchesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.CommitHash == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: commit_hash is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	patches, err := GetCommitFilePatches(repository, args.CommitHash)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get commit patches: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatCommitPatches(args.CommitHash, patches)}},
+	}, nil, nil
+}
+
+func handleDiffRefs(ctx context.Context, req *mcp.CallToolRequest, args DiffRefsParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Base == "" || args.Head == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: base and head are required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	var diff string
+	var err error
+	if args.StatOnly {
+		diff, err = DiffRefsStat(repository, args.Base, args.Head, args.Paths)
+	} else {
+		diff, err = DiffRefs(repository, args.Base, args.Head, args.Paths)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to diff refs: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatRefDiff(args.Base, args.Head, diff)}},
+	}, nil, nil
+}
+
+func handleCompareBranches(ctx context.Context, req *mcp.CallToolRequest, args CompareBranchesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.A == "" || args.B == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: a and b are required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	comparison, err := CompareBranches(repository, args.A, args.B)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compare branches: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatBranchComparison(comparison, limit)}},
+	}, nil, nil
+}
+
+func handleDiffFile(ctx context.Context, req *mcp.CallToolRequest, args DiffFileParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.FilePath == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: file_path is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	ref := args.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	diff, err := DiffWorkingFileAgainst(repository, args.FilePath, ref)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to diff file: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Diff %s (working tree vs %s):\n", args.FilePath, ref))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+	if strings.TrimSpace(diff) == "" {
+		result.WriteString(fmt.Sprintf("No changes since %s.\n", ref))
+	} else {
+		result.WriteString(diff)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
+func handleFilesDifferingFromBranch(ctx context.Context, req *mcp.CallToolRequest, args FilesDifferingFromBranchParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Branch == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: branch name is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	files, err := FilesDifferingFromBranch(repository, args.Branch)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compare with branch: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Files differing from %s (%d files, working tree vs %s):\n", args.Branch, len(files), args.Branch))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+	if len(files) == 0 {
+		result.WriteString(fmt.Sprintf("Working tree matches %s.\n", args.Branch))
+	}
+	for _, file := range files {
+		if file.OldPath != "" {
+			result.WriteString(fmt.Sprintf("%s  %s -> %s\n", file.Status, file.OldPath, file.Path))
+		} else {
+			result.WriteString(fmt.Sprintf("%s  %s\n", file.Status, file.Path))
+		}
+	}
+	if len(files) > 0 {
+		result.WriteString(fmt.Sprintf("\nA = only in the working tree, D = only on %s, M = modified\n", args.Branch))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
+func handleGetStatus(ctx context.Context, req *mcp.CallToolRequest, args GetStatusParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	status, err := GetStatus(repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get status: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatWorkingTreeStatus(repository, status)}},
+	}, nil, nil
+}
+
+func handleListRemotes(ctx context.Context, req *mcp.CallToolRequest, args ListRemotesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if errResult := checkTextOrJSON(args.OutputFormat); errResult != nil {
+		return errResult, nil, nil
+	}
+
+	remotes, err := ListRemotes(repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list remotes: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if args.OutputFormat == "json" {
+		return jsonResult(remotes)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatRemotes(repository, remotes)}},
+	}, nil, nil
+}
+
+func handleListSubmodules(ctx context.Context, req *mcp.CallToolRequest, args ListSubmodulesParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	repository := sc.GetRepository(args.Repository)
+	if repository == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: repository required (no default set)"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if errResult := checkTextOrJSON(args.OutputFormat); errResult != nil {
+		return errResult, nil, nil
+	}
+
+	submodules, err := ListSubmodules(repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list submodules: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if args.OutputFormat == "json" {
+		return jsonResult(submodules)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: formatSubmodules(repository, submodules)}},
+	}, nil, nil
+}
+
+func formatCommits(commits []Commit, limit int, filter CommitFilter) string {
+	var result strings.Builder
 
-	result.WriteString(fmt.Sprintf("Commit History (%d commits):\n", len(commits)))
+	if filters := filter.describe(); filters != "" {
+		result.WriteString(fmt.Sprintf("Commit History (%d commits, %s):\n", len(commits), filters))
+	} else {
+		result.WriteString(fmt.Sprintf("Commit History (%d commits):\n", len(commits)))
+	}
 	result.WriteString(strings.Repeat("=", 50) + "\n\n")
 
 	if len(commits) == 0 {
@@ -898,18 +2837,356 @@ func formatCommits(commits []Commit, limit int) string {
 		result.WriteString(fmt.Sprintf("\n    %s\n\n", commit.Message))
 	}
 
-	if len(commits) == limit {
-		result.WriteString(fmt.Sprintf("(Limited to %d commits)\n", limit))
+	if len(commits) == limit {
+		result.WriteString(fmt.Sprintf("(Limited to %d commits)\n", limit))
+	}
+
+	return result.String()
+}
+
+func formatFileHistory(filePath string, commits []Commit, limit int) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("File: %s\n", filePath))
+	result.WriteString(formatCommits(commits, limit, CommitFilter{}))
+	return result.String()
+}
+
+func formatBlame(filePath string, lines []BlameLine) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Blame: %s (%d lines)\n", filePath, len(lines)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	for _, line := range lines {
+		result.WriteString(fmt.Sprintf("%s (%s %s) %s\n", line.CommitHash, line.Author, line.Date, line.Content))
+	}
+
+	return result.String()
+}
+
+func formatFileAuthors(filePath string, authors []AuthorContribution) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Authors of %s (%d authors):\n", filePath, len(authors)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	for _, author := range authors {
+		result.WriteString(fmt.Sprintf("%5d  %s\n", author.Commits, author.Author))
+	}
+
+	return result.String()
+}
+
+func formatContributors(contributors []Contributor, limit int) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Contributors (%d):\n", len(contributors)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	for _, contributor := range contributors {
+		if contributor.Email != "" {
+			result.WriteString(fmt.Sprintf("%5d  %s <%s>\n", contributor.CommitCount, contributor.Name, contributor.Email))
+		} else {
+			result.WriteString(fmt.Sprintf("%5d  %s\n", contributor.CommitCount, contributor.Name))
+		}
+	}
+
+	if len(contributors) == limit {
+		result.WriteString(fmt.Sprintf("\n(Limited to %d contributors)", limit))
+	}
+
+	return result.String()
+}
+
+func formatActivitySummary(summary *ActivitySummary) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Activity in the last %d days:\n", summary.Days))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	if summary.Commits == 0 {
+		result.WriteString("No commits in this period.\n")
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("Commits: %d\n", summary.Commits))
+	result.WriteString(fmt.Sprintf("Authors: %d\n", summary.Authors))
+
+	if len(summary.TopFiles) > 0 {
+		result.WriteString("\nMost changed files:\n")
+		for _, file := range summary.TopFiles {
+			result.WriteString(fmt.Sprintf("%5d  %s\n", file.Commits, file.Path))
+		}
+	}
+
+	return result.String()
+}
+
+func formatDirectoryOwnership(directory string, shares []OwnershipShare) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Ownership of '%s' (%d authors):\n", directory, len(shares)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	for _, share := range shares {
+		result.WriteString(fmt.Sprintf("%5.1f%%  %6d lines  %s\n", share.Percent, share.Lines, share.Author))
+	}
+
+	return result.String()
+}
+
+func formatLineHistory(filePath string, line int, changes []LineChange) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("History of %s:%d (%d commits):\n", filePath, line, len(changes)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	for _, change := range changes {
+		hash := change.Commit.Hash
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		result.WriteString(fmt.Sprintf("\n%s %s (%s, %s)\n", hash, change.Commit.Message, change.Commit.Author, change.Commit.Date))
+		result.WriteString(fmt.Sprintf("    %s\n", change.Content))
+	}
+
+	return result.String()
+}
+
+func formatHistoryBloat(blobs []BlobInfo) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Largest blobs in history (%d):\n", len(blobs)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	if len(blobs) == 0 {
+		result.WriteString("No blobs found.\n")
+		return result.String()
+	}
+
+	for _, blob := range blobs {
+		result.WriteString(fmt.Sprintf("%10s  %s  %s\n", formatByteSize(blob.Size), blob.SHA[:7], strings.Join(blob.Paths, ", ")))
+	}
+
+	return result.String()
+}
+
+func formatCommitDiff(diff *CommitDiff) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Diff for commit %s:\n", diff.Hash))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	result.WriteString(diff.Stat.summary() + "\n")
+	width := 0
+	for _, file := range diff.Stat.Files {
+		width = max(width, len(file.Path))
+	}
+	for _, file := range diff.Stat.Files {
+		if file.Binary {
+			result.WriteString(fmt.Sprintf(" %-*s | binary\n", width, file.Path))
+		} else {
+			result.WriteString(fmt.Sprintf(" %-*s | +%d -%d\n", width, file.Path, file.Insertions, file.Deletions))
+		}
+	}
+
+	result.WriteString("\n")
+	result.WriteString(diff.Diff)
+	return result.String()
+}
+
+func formatCommitDiffHunks(commitHash string, hunks []DiffHunk, offset, total int) string {
+	var result strings.Builder
+	if len(hunks) == 0 {
+		result.WriteString(fmt.Sprintf("Diff for commit %s: no hunks at offset %d (total hunks: %d)\n", commitHash, offset, total))
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("Diff for commit %s: hunks %d-%d of %d\n", commitHash, offset+1, offset+len(hunks), total))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	file := ""
+	for _, hunk := range hunks {
+		if hunk.File != file {
+			file = hunk.File
+			result.WriteString(fmt.Sprintf("\n--- %s ---\n", file))
+		}
+		result.WriteString(hunk.Body)
+	}
+
+	if next := offset + len(hunks); next < total {
+		result.WriteString(fmt.Sprintf("\n(%d more hunks; use hunk_offset=%d to continue)\n", total-next, next))
+	}
+	return result.String()
+}
+
+func formatCommitPatches(commitHash string, patches map[string]string) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Patches for commit %s (%d files):\n", commitHash, len(patches)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	paths := make([]string, 0, len(patches))
+	for path := range patches {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		result.WriteString(fmt.Sprintf("\n--- %s ---\n", path))
+		result.WriteString(patches[path])
+	}
+	return result.String()
+}
+
+func formatRefDiff(base, head, diff string) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Diff %s...%s:\n", base, head))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+	if strings.TrimSpace(diff) == "" {
+		result.WriteString("No differences found.\n")
+		return result.String()
+	}
+	result.WriteString(diff)
+	return result.String()
+}
+
+func formatBranchComparison(comparison *BranchComparison, limit int) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Compare %s with %s:\n", comparison.A, comparison.B))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+
+	result.WriteString(fmt.Sprintf("%s is ahead by %d commits, behind by %d\n", comparison.A, len(comparison.Ahead), len(comparison.Behind)))
+	if comparison.MergeBase != "" {
+		result.WriteString(fmt.Sprintf("Merge base: %s\n", comparison.MergeBase))
+	} else {
+		result.WriteString("Merge base: none (unrelated histories)\n")
+	}
+
+	for _, side := range []struct {
+		title   string
+		commits []Commit
+	}{
+		{fmt.Sprintf("Only on %s", comparison.A), comparison.Ahead},
+		{fmt.Sprintf("Only on %s", comparison.B), comparison.Behind},
+	} {
+		if len(side.commits) == 0 {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n%s (%d):\n", side.title, len(side.commits)))
+		for i, commit := range side.commits {
+			if i == limit {
+				result.WriteString(fmt.Sprintf("  ... and %d more\n", len(side.commits)-limit))
+				break
+			}
+			shortHash := commit.Hash
+			if len(shortHash) > 7 {
+				shortHash = shortHash[:7]
+			}
+			result.WriteString(fmt.Sprintf("  %s %s (%s)\n", shortHash, commit.Message, commit.Author))
+		}
+	}
+
+	return result.String()
+}
+
+func formatWorkingTreeStatus(repository string, status *WorkingTreeStatus) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Status of %s:\n", repository))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	if status.Clean {
+		result.WriteString("Working tree clean\n")
+		return result.String()
+	}
+
+	for _, group := range []struct {
+		title string
+		paths []string
+	}{
+		{"Staged", status.Staged},
+		{"Modified", status.Modified},
+		{"Untracked", status.Untracked},
+	} {
+		if len(group.paths) == 0 {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n%s (%d):\n", group.title, len(group.paths)))
+		for _, path := range group.paths {
+			result.WriteString(fmt.Sprintf("  %s\n", path))
+		}
+	}
+
+	return result.String()
+}
+
+func formatRemotes(repository string, remotes []Remote) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Remotes of %s (%d):\n", repository, len(remotes)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	if len(remotes) == 0 {
+		result.WriteString("No remotes configured\n")
+		return result.String()
+	}
+	writeRemotes(&result, remotes)
+	return result.String()
+}
+
+func formatSubmodules(repository string, submodules []Submodule) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Submodules of %s (%d):\n", repository, len(submodules)))
+	result.WriteString(strings.Repeat("=", 50) + "\n")
+
+	if len(submodules) == 0 {
+		result.WriteString("No submodules\n")
+		return result.String()
+	}
+	for _, submodule := range submodules {
+		commit := submodule.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		result.WriteString(fmt.Sprintf("  %s @ %s: %s", submodule.Path, commit, submodule.URL))
+		switch {
+		case !submodule.Initialized:
+			result.WriteString(" (not initialized)")
+		case submodule.Modified:
+			result.WriteString(" (checked out commit differs)")
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// writeRemotes writes one "name: url" line per remote, adding the push URL
+// when it differs from the fetch URL
+func writeRemotes(result *strings.Builder, remotes []Remote) {
+	for _, remote := range remotes {
+		result.WriteString(fmt.Sprintf("  %s: %s", remote.Name, remote.FetchURL))
+		if remote.PushURL != "" && remote.PushURL != remote.FetchURL {
+			result.WriteString(fmt.Sprintf(" (push: %s)", remote.PushURL))
+		}
+		result.WriteString("\n")
 	}
-
-	return result.String()
 }
 
-func formatCommitDiff(commitHash, diff string) string {
+func formatPullPreview(preview *PullPreview) string {
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Diff for commit %s:\n", commitHash))
+	result.WriteString(fmt.Sprintf("Pull preview %s <- %s:\n", preview.Branch, preview.Upstream))
 	result.WriteString(strings.Repeat("=", 50) + "\n\n")
-	result.WriteString(diff)
+
+	if preview.UpToDate {
+		result.WriteString("Up to date: nothing incoming.\n")
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("Incoming commits (%d):\n", len(preview.Commits)))
+	for _, commit := range preview.Commits {
+		shortHash := commit.Hash
+		if len(shortHash) > 7 {
+			shortHash = shortHash[:7]
+		}
+		result.WriteString(fmt.Sprintf("  %s %s (%s)\n", shortHash, commit.Message, commit.Author))
+	}
+	result.WriteString("\n")
+	result.WriteString(preview.Diff)
 	return result.String()
 }
 
@@ -922,6 +3199,8 @@ func formatBranches(branches []Branch, limited bool) string {
 	for _, branch := range branches {
 		if branch.IsCurrent {
 			result.WriteString(fmt.Sprintf("* %s (current)\n", branch.Name))
+		} else if branch.HasTracking {
+			result.WriteString(fmt.Sprintf("  %s (ahead %d, behind %d)\n", branch.Name, branch.Ahead, branch.Behind))
 		} else {
 			result.WriteString(fmt.Sprintf("  %s\n", branch.Name))
 		}
@@ -934,15 +3213,40 @@ func formatBranches(branches []Branch, limited bool) string {
 	return result.String()
 }
 
-func formatSearchResults(results []SearchResult, keywords []string, searchMode string) string {
+func formatSymbolMatches(symbol, keyword string, matches []MatchLine) string {
 	var result strings.Builder
 
-	var keywordStr string
-	if searchMode == "or" {
-		keywordStr = strings.Join(keywords, " OR ")
-	} else {
-		keywordStr = strings.Join(keywords, " AND ")
+	result.WriteString(fmt.Sprintf("Matches for %q in %s (%d found)\n", keyword, symbol, len(matches)))
+	result.WriteString(strings.Repeat("-", 50) + "\n")
+
+	if len(matches) == 0 {
+		result.WriteString("No matches found within the symbol.\n")
+		return result.String()
+	}
+
+	for _, match := range matches {
+		result.WriteString(fmt.Sprintf("%s:%d: %s\n", match.Path, match.LineNumber, strings.TrimSpace(match.Content)))
+	}
+
+	return result.String()
+}
+
+// searchKeywordLabel describes the search in result headers, e.g.
+// "a AND b", "a OR b" or "\"a b\"" for a phrase
+func searchKeywordLabel(keywords []string, searchMode string) string {
+	switch searchMode {
+	case "or":
+		return strings.Join(keywords, " OR ")
+	case "phrase":
+		return fmt.Sprintf("%q", strings.Join(keywords, " "))
 	}
+	return strings.Join(keywords, " AND ")
+}
+
+func formatSearchResults(results []SearchResult, keywords []string, searchMode string) string {
+	var result strings.Builder
+
+	keywordStr := searchKeywordLabel(keywords, searchMode)
 	result.WriteString(fmt.Sprintf("Search Results for: %s (%d files found)\n", keywordStr, len(results)))
 	result.WriteString(strings.Repeat("-", 50) + "\n")
 
@@ -966,19 +3270,128 @@ func formatSearchResults(results []SearchResult, keywords []string, searchMode s
 			matchTypeStr = " [filename + content match]"
 		}
 
-		result.WriteString(fmt.Sprintf("📄 %s%s\n", searchResult.Path, matchTypeStr))
+		result.WriteString(fmt.Sprintf("%s %s%s\n", markers().File, searchResult.Path, matchTypeStr))
 
 		// Show detailed matches
-		if len(searchResult.Matches) > 0 {
-			for _, match := range searchResult.Matches {
-				if match.LineNumber == 0 {
-					// Filename match
-					result.WriteString(fmt.Sprintf("   └─ Filename: %s\n", match.Content))
-				} else {
-					// Content match with line number
-					result.WriteString(fmt.Sprintf("   └─ Line %d: %s\n", match.LineNumber, strings.TrimSpace(match.Content)))
+		writeSearchMatches(&result, searchResult.Matches, searchResult.OmittedMatches)
+	}
+
+	return result.String()
+}
+
+// writeSearchMatches writes one line per match under a search result's path,
+// followed by a summary of any matches dropped by the per-file cap. Context
+// lines are indented further, with a "|" gutter instead of the match's "└─";
+// context shared by nearby matches is written once. A match's function line
+// (with function context) is written before it with a "=" gutter, once per
+// function. A file that matched by both name and content gets an
+// "In filename:" section before an "In content:" section.
+func writeSearchMatches(result *strings.Builder, matches []MatchLine, omitted int) {
+	var filenameMatches, contentMatches []MatchLine
+	for _, match := range matches {
+		if match.LineNumber == 0 {
+			filenameMatches = append(filenameMatches, match)
+		} else {
+			contentMatches = append(contentMatches, match)
+		}
+	}
+
+	if len(filenameMatches) > 0 && len(contentMatches) > 0 {
+		result.WriteString("   In filename:\n")
+		for _, match := range filenameMatches {
+			result.WriteString(fmt.Sprintf("   └─ %s\n", match.Content))
+		}
+		result.WriteString("   In content:\n")
+	} else {
+		for _, match := range filenameMatches {
+			result.WriteString(fmt.Sprintf("   └─ Filename: %s\n", match.Content))
+		}
+	}
+
+	written := 0 // last line number written, so overlapping context is not repeated
+	for i, match := range contentMatches {
+
+		// Context after this match stops where the next match's own lines begin
+		next := 0
+		if i+1 < len(contentMatches) {
+			next = contentMatches[i+1].LineNumber
+		}
+		writeContext := func(after bool) {
+			for j, text := range match.Context {
+				lineNum := match.ContextStart + j
+				if lineNum >= match.LineNumber {
+					lineNum++
+				}
+				if (lineNum > match.LineNumber) != after || lineNum <= written || (after && next > 0 && lineNum >= next) {
+					continue
+				}
+				gutter := "|"
+				if lineNum == match.FunctionLine {
+					gutter = "="
 				}
+				result.WriteString(strings.TrimRight(fmt.Sprintf("      %4d %s %s", lineNum, gutter, text), " \t\r") + "\n")
+				written = lineNum
+			}
+		}
+
+		// The function line is written here unless the context before the match covers it
+		if match.Function != "" && match.FunctionLine > written && (len(match.Context) == 0 || match.FunctionLine < match.ContextStart) {
+			result.WriteString(strings.TrimRight(fmt.Sprintf("      %4d = %s", match.FunctionLine, match.Function), " \t\r") + "\n")
+			written = match.FunctionLine
+		}
+		writeContext(false)
+		// Content match with line number
+		result.WriteString(fmt.Sprintf("   └─ Line %d: %s\n", match.LineNumber, strings.TrimSpace(match.Content)))
+		written = max(written, match.LineNumber)
+		writeContext(true)
+	}
+	if omitted > 0 {
+		result.WriteString(fmt.Sprintf("   ... and %d more matches\n", omitted))
+	}
+}
+
+// formatGroupedSearchResults renders search results in sections by match
+// type: files matching by name and content, by name only, and by content only
+func formatGroupedSearchResults(results []SearchResult, keywords []string, searchMode string) string {
+	var result strings.Builder
+
+	keywordStr := searchKeywordLabel(keywords, searchMode)
+	result.WriteString(fmt.Sprintf("Search Results for: %s (%d files found)\n", keywordStr, len(results)))
+	result.WriteString(strings.Repeat("-", 50) + "\n")
+
+	if len(results) == 0 {
+		result.WriteString("No files found matching the specified keywords.\n")
+		return result.String()
+	}
+
+	groups := []struct {
+		matchType string
+		title     string
+	}{
+		{"both", "Filename + content matches"},
+		{"filename", "Filename matches"},
+		{"content", "Content matches"},
+	}
+
+	for _, group := range groups {
+		var members []SearchResult
+		for _, searchResult := range results {
+			matchType := searchResult.MatchType
+			if matchType == "" {
+				matchType = "content"
 			}
+			if matchType == group.matchType {
+				members = append(members, searchResult)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		result.WriteString(fmt.Sprintf("\n%s (%d):\n", group.title, len(members)))
+		for _, searchResult := range members {
+			result.WriteString(fmt.Sprintf("%s %s\n", markers().File, searchResult.Path))
+			writeSearchMatches(&result, searchResult.Matches, searchResult.OmittedMatches)
 		}
 	}
 
@@ -997,35 +3410,149 @@ func formatFileList(files []FileInfo, directory string, recursive bool, limit in
 	result.WriteString(strings.Repeat("-", 50) + "\n")
 
 	for _, file := range files {
-		infoStr := ""
-		if file.Size > 0 || file.LineCount > 0 {
-			var parts []string
-
-			// Add file size
-			if file.Size > 0 {
-				if file.Size < 1024 {
-					parts = append(parts, fmt.Sprintf("%dB", file.Size))
-				} else if file.Size < 1024*1024 {
-					parts = append(parts, fmt.Sprintf("%.1fKB", float64(file.Size)/1024))
-				} else {
-					parts = append(parts, fmt.Sprintf("%.1fMB", float64(file.Size)/(1024*1024)))
-				}
+		result.WriteString(fmt.Sprintf("%s%s\n", file.Path, fileInfoSuffix(file)))
+	}
+
+	if len(files) > 0 {
+		result.WriteString(summarizeFiles(files, directory).String() + "\n")
+	}
+
+	if len(files) == limit {
+		result.WriteString(fmt.Sprintf("\n(Limited to %d results)", limit))
+	}
+
+	return result.String()
+}
+
+// fileListTotals aggregates the entries of a file listing
+type fileListTotals struct {
+	Files int
+	Dirs  int // directories below the listed one that hold listed files
+	Size  int64
+	Lines int // summed over text files
+}
+
+// summarizeFiles totals files, counting every directory between directory and
+// a listed file once
+func summarizeFiles(files []FileInfo, directory string) fileListTotals {
+	totals := fileListTotals{Files: len(files)}
+	dirs := make(map[string]bool)
+	for _, file := range files {
+		totals.Size += file.Size
+		totals.Lines += file.LineCount
+
+		relPath := file.Path
+		if rel, err := filepath.Rel(directory, file.Path); err == nil {
+			relPath = rel
+		}
+		for dir := filepath.Dir(relPath); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+	totals.Dirs = len(dirs)
+	return totals
+}
+
+// String renders the totals as "Total: 12 files, 3 dirs, 540.0 KB, 8,204 lines"
+func (t fileListTotals) String() string {
+	return fmt.Sprintf("Total: %s files, %s dirs, %s, %s lines",
+		formatThousands(t.Files), formatThousands(t.Dirs), formatByteSize(t.Size), formatThousands(t.Lines))
+}
+
+// fileInfoSuffix returns " (size, lines)" for a file listing entry, or "" when
+// neither is known
+func fileInfoSuffix(file FileInfo) string {
+	var parts []string
+
+	// Add file size
+	if file.Size > 0 {
+		if file.Size < 1024 {
+			parts = append(parts, fmt.Sprintf("%dB", file.Size))
+		} else if file.Size < 1024*1024 {
+			parts = append(parts, fmt.Sprintf("%.1fKB", float64(file.Size)/1024))
+		} else {
+			parts = append(parts, fmt.Sprintf("%.1fMB", float64(file.Size)/(1024*1024)))
+		}
+	}
+
+	// Add line count
+	if file.LineCount > 0 {
+		parts = append(parts, fmt.Sprintf("%dL", file.LineCount))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+}
+
+// fileTreeNode is a directory or file in the tree rendered by formatFileTree
+type fileTreeNode struct {
+	name     string
+	file     *FileInfo // nil for directories
+	children []*fileTreeNode
+}
+
+// formatFileTree renders files (sorted by lessPathDirsFirst) as an indented
+// tree below directory. limit caps the rendered entries, directories included.
+func formatFileTree(files []FileInfo, directory string, limit int) string {
+	root := &fileTreeNode{}
+	for i := range files {
+		relPath := files[i].Path
+		if directory != "." && directory != "" {
+			if rel, err := filepath.Rel(directory, files[i].Path); err == nil {
+				relPath = rel
 			}
+		}
+
+		node := root
+		parts := strings.Split(filepath.ToSlash(relPath), "/")
+		for _, dir := range parts[:len(parts)-1] {
+			// Sorted input keeps a directory's entries together, so only the
+			// last child can be the directory we are looking for
+			if n := len(node.children); n > 0 && node.children[n-1].file == nil && node.children[n-1].name == dir {
+				node = node.children[n-1]
+				continue
+			}
+			child := &fileTreeNode{name: dir}
+			node.children = append(node.children, child)
+			node = child
+		}
+		node.children = append(node.children, &fileTreeNode{name: parts[len(parts)-1], file: &files[i]})
+	}
 
-			// Add line count
-			if file.LineCount > 0 {
-				parts = append(parts, fmt.Sprintf("%dL", file.LineCount))
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Files in '%s' (tree, %d files):\n", directory, len(files)))
+	result.WriteString(strings.Repeat("-", 50) + "\n")
+	result.WriteString(strings.TrimSuffix(directory, "/") + "/\n")
+
+	rendered := 0
+	truncated := len(files) == limit
+	var render func(node *fileTreeNode, prefix string)
+	render = func(node *fileTreeNode, prefix string) {
+		for i, child := range node.children {
+			if limit > 0 && rendered >= limit {
+				truncated = true
+				return
 			}
+			rendered++
 
-			if len(parts) > 0 {
-				infoStr = fmt.Sprintf(" (%s)", strings.Join(parts, ", "))
+			connector, childPrefix := "├── ", prefix+"│   "
+			if i == len(node.children)-1 {
+				connector, childPrefix = "└── ", prefix+"    "
+			}
+			if child.file != nil {
+				result.WriteString(fmt.Sprintf("%s%s%s%s\n", prefix, connector, child.name, fileInfoSuffix(*child.file)))
+				continue
 			}
+			result.WriteString(fmt.Sprintf("%s%s%s/\n", prefix, connector, child.name))
+			render(child, childPrefix)
 		}
-		result.WriteString(fmt.Sprintf("%s%s\n", file.Path, infoStr))
 	}
+	render(root, "")
 
-	if len(files) == limit {
-		result.WriteString(fmt.Sprintf("\n(Limited to %d results)", limit))
+	if truncated {
+		result.WriteString(fmt.Sprintf("\n(Limited to %d entries)", limit))
 	}
 
 	return result.String()
@@ -1044,7 +3571,7 @@ func formatWorkspaceRepositories(repositories []string, workspaceDir string) str
 	}
 
 	for _, repo := range repositories {
-		result.WriteString(fmt.Sprintf("📁 %s\n", repo))
+		result.WriteString(fmt.Sprintf("%s %s\n", markers().Dir, repo))
 	}
 
 	result.WriteString(fmt.Sprintf("\nTotal: %d repositories\n", len(repositories)))
@@ -1052,6 +3579,93 @@ func formatWorkspaceRepositories(repositories []string, workspaceDir string) str
 	return result.String()
 }
 
+// formatByteSize renders a byte count as B, KB, MB or GB
+func formatByteSize(size int64) string {
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d B", size)
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	case size < 1024*1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	default:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1024*1024*1024))
+	}
+}
+
+// formatThousands renders n with comma thousands separators, e.g. 8,204
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	start := 0
+	if n < 0 {
+		start = 1
+	}
+	var b strings.Builder
+	b.WriteString(digits[:start])
+	for i := start; i < len(digits); i++ {
+		if i > start && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(digits[i])
+	}
+	return b.String()
+}
+
+func formatRepositoryTable(workspaceDir string, overviews []RepositoryOverview) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Workspace Repositories (%s):\n", workspaceDir))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+
+	if len(overviews) == 0 {
+		result.WriteString("No repositories found in workspace.\n")
+		result.WriteString("Use 'clone_repository' tool to add repositories.\n")
+		return result.String()
+	}
+
+	var totalSize int64
+	table := tabwriter.NewWriter(&result, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tBRANCH\tSTATUS\tSIZE\tUPDATED\tREMOTE")
+	for _, o := range overviews {
+		if o.Error != "" {
+			fmt.Fprintf(table, "%s\t-\terror: %s\t-\t-\t-\n", o.Name, o.Error)
+			continue
+		}
+		status := "clean"
+		if o.HasChanges {
+			status = "dirty"
+		}
+		branch := o.CurrentBranch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		remote := o.RemoteURL
+		if remote == "" {
+			remote = "-"
+		}
+		updated := o.LastCommit
+		if updated == "" {
+			updated = "-"
+		}
+		totalSize += o.SizeBytes
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", o.Name, branch, status, formatByteSize(o.SizeBytes), updated, remote)
+	}
+	table.Flush()
+
+	result.WriteString(fmt.Sprintf("\nTotal: %d repositories (%s)\n", len(overviews), formatByteSize(totalSize)))
+
+	return result.String()
+}
+
+// fileContentHeader returns the "[path Lstart-end/total]" line that precedes
+// file content, naming the source encoding when it was not UTF-8
+func fileContentHeader(r FileContentResult) string {
+	if r.Encoding != "" && r.Encoding != encodingUTF8 {
+		return fmt.Sprintf("[%s L%d-%d/%d %s]\n", r.FilePath, r.StartLine, r.EndLine, r.TotalLines, r.Encoding)
+	}
+	return fmt.Sprintf("[%s L%d-%d/%d]\n", r.FilePath, r.StartLine, r.EndLine, r.TotalLines)
+}
+
 func formatMultipleFileContents(results []FileContentResult) string {
 	var result strings.Builder
 
@@ -1063,7 +3677,7 @@ func formatMultipleFileContents(results []FileContentResult) string {
 		if fileResult.Error != "" {
 			result.WriteString(fmt.Sprintf("[%s ERR:%s]\n", fileResult.FilePath, fileResult.Error))
 		} else {
-			result.WriteString(fmt.Sprintf("[%s L%d-%d/%d]\n", fileResult.FilePath, fileResult.StartLine, fileResult.EndLine, fileResult.TotalLines))
+			result.WriteString(fileContentHeader(fileResult))
 			result.WriteString(fileResult.Content)
 		}
 	}
@@ -1071,6 +3685,98 @@ func formatMultipleFileContents(results []FileContentResult) string {
 	return result.String()
 }
 
+func formatFileStats(stats *FileStats) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("File: %s\n", stats.Path))
+	result.WriteString(fmt.Sprintf("Bytes: %d\n", stats.Bytes))
+	result.WriteString(fmt.Sprintf("Lines: %d (%d blank)\n", stats.Lines, stats.BlankLines))
+	result.WriteString(fmt.Sprintf("Words: %d\n", stats.Words))
+	if stats.LongestLineNumber > 0 {
+		result.WriteString(fmt.Sprintf("Longest line: %d chars (line %d)\n", stats.LongestLine, stats.LongestLineNumber))
+	}
+	result.WriteString(fmt.Sprintf("Ends with newline: %t\n", stats.EndsWithNewline))
+
+	return result.String()
+}
+
+func formatFileMetadata(metadata *FileMetadata) string {
+	var result strings.Builder
+
+	fileType := "text"
+	switch {
+	case metadata.IsSymlink:
+		fileType = "symlink"
+	case metadata.IsDir:
+		fileType = "directory"
+	case metadata.IsBinary:
+		fileType = "binary"
+	}
+
+	result.WriteString(fmt.Sprintf("File: %s\n", metadata.Path))
+	result.WriteString(fmt.Sprintf("Type: %s\n", fileType))
+	if metadata.LinkTarget != "" {
+		result.WriteString(fmt.Sprintf("Target: %s\n", metadata.LinkTarget))
+	}
+	result.WriteString(fmt.Sprintf("Size: %d bytes\n", metadata.Size))
+	result.WriteString(fmt.Sprintf("Mode: %s", metadata.Mode))
+	if metadata.IsExecutable {
+		result.WriteString(" (executable)")
+	}
+	result.WriteString("\n")
+	if metadata.MIMEType != "" {
+		result.WriteString(fmt.Sprintf("MIME: %s\n", metadata.MIMEType))
+	}
+	result.WriteString(fmt.Sprintf("Modified: %s\n", metadata.ModTime.Format("2006-01-02 15:04:05")))
+
+	return result.String()
+}
+
+func formatGovernanceFiles(files []GovernanceFile) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Governance Files (%d found):\n", len(files)))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+
+	if len(files) == 0 {
+		result.WriteString("No CODEOWNERS, CONTRIBUTING, SECURITY or similar files found.\n")
+		return result.String()
+	}
+
+	for _, file := range files {
+		result.WriteString(fmt.Sprintf("%s %s [%s] (%s)\n", markers().File, file.Path, file.Kind, formatByteSize(file.Size)))
+		if file.Preview != "" {
+			for _, line := range strings.Split(strings.TrimRight(file.Preview, "\n"), "\n") {
+				result.WriteString("   " + line + "\n")
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String()
+}
+
+func formatLintIssues(files []FileLintIssue) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Lint Results (%d files with issues):\n", len(files)))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+
+	if len(files) == 0 {
+		result.WriteString(fmt.Sprintf("%s No line ending, whitespace or encoding issues found.\n", markers().OK))
+		return result.String()
+	}
+
+	for _, file := range files {
+		result.WriteString(fmt.Sprintf("%s %s\n", markers().File, file.Path))
+		for _, issue := range file.Issues {
+			result.WriteString("   - " + issue + "\n")
+		}
+	}
+
+	return result.String()
+}
+
 func formatReadmeFiles(readmeFiles []ReadmeFileInfo, recursive bool) string {
 	var result strings.Builder
 
@@ -1088,7 +3794,7 @@ func formatReadmeFiles(readmeFiles []ReadmeFileInfo, recursive bool) string {
 	}
 
 	for _, readme := range readmeFiles {
-		result.WriteString(fmt.Sprintf("📄 %s\n", readme.Path))
+		result.WriteString(fmt.Sprintf("%s %s\n", markers().File, readme.Path))
 		if readme.Size > 0 {
 			var sizeStr string
 			if readme.Size < 1024 {
@@ -1108,91 +3814,32 @@ func formatReadmeFiles(readmeFiles []ReadmeFileInfo, recursive bool) string {
 		if !readme.ModTime.IsZero() {
 			result.WriteString(fmt.Sprintf("   Modified: %s\n", readme.ModTime.Format("2006-01-02 15:04:05")))
 		}
+		if readme.Content != "" {
+			lines := strings.Split(strings.TrimRight(readme.Content, "\n"), "\n")
+			result.WriteString("   Content:\n")
+			for _, line := range lines {
+				result.WriteString("   | " + line + "\n")
+			}
+			if readme.LineCount > len(lines) {
+				result.WriteString(fmt.Sprintf("   | ... (%d more lines)\n", readme.LineCount-len(lines)))
+			}
+		}
 		result.WriteString("\n")
 	}
 
 	return result.String()
 }
 
-// Unified session handler
-
-func handleSession(ctx context.Context, req *mcp.CallToolRequest, args SessionParams) (*mcp.CallToolResult, any, error) {
-	switch args.Action {
-	case "set":
-		config := &SessionConfig{
-			DefaultRepository:      args.DefaultRepository,
-			DefaultIncludePatterns: args.DefaultIncludePatterns,
-			DefaultExcludePatterns: args.DefaultExcludePatterns,
-			DefaultSearchLimit:     args.DefaultSearchLimit,
-			DefaultListFilesLimit:  args.DefaultListFilesLimit,
-			DefaultMaxLines:        args.DefaultMaxLines,
-			DefaultCommitLimit:     args.DefaultCommitLimit,
-		}
-		SetSessionConfigValues(config)
-
-		var result strings.Builder
-		result.WriteString("Session configuration updated:\n")
-		result.WriteString(strings.Repeat("-", 30) + "\n")
-		if args.DefaultRepository != "" {
-			result.WriteString(fmt.Sprintf("default_repository: %s\n", args.DefaultRepository))
-		}
-		if len(args.DefaultIncludePatterns) > 0 {
-			result.WriteString(fmt.Sprintf("default_include_patterns: %v\n", args.DefaultIncludePatterns))
-		}
-		if len(args.DefaultExcludePatterns) > 0 {
-			result.WriteString(fmt.Sprintf("default_exclude_patterns: %v\n", args.DefaultExcludePatterns))
-		}
-		if args.DefaultSearchLimit > 0 {
-			result.WriteString(fmt.Sprintf("default_search_limit: %d\n", args.DefaultSearchLimit))
-		}
-		if args.DefaultListFilesLimit > 0 {
-			result.WriteString(fmt.Sprintf("default_list_files_limit: %d\n", args.DefaultListFilesLimit))
-		}
-		if args.DefaultMaxLines > 0 {
-			result.WriteString(fmt.Sprintf("default_max_lines: %d\n", args.DefaultMaxLines))
-		}
-		if args.DefaultCommitLimit > 0 {
-			result.WriteString(fmt.Sprintf("default_commit_limit: %d\n", args.DefaultCommitLimit))
-		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
-		}, nil, nil
-
-	case "get":
-		sc := GetSessionConfig()
-		if sc.IsEmpty() {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{&mcp.TextContent{Text: "No session configuration set.\nUse action='set' with: default_repository, default_include_patterns, default_exclude_patterns, default_search_limit, default_list_files_limit, default_max_lines, default_commit_limit"}},
-			}, nil, nil
-		}
-		var result strings.Builder
-		result.WriteString("Current Session Configuration:\n")
-		result.WriteString(strings.Repeat("=", 50) + "\n\n")
-		configMap := sc.ToMap()
-		for key, value := range configMap {
-			result.WriteString(fmt.Sprintf("%s: %v\n", key, value))
-		}
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
-		}, nil, nil
-
-	case "clear":
-		ClearSessionConfig()
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Session configuration cleared. All values reset to defaults."}},
-		}, nil, nil
+// Unified batch handler
 
-	default:
+func handleBatch(ctx context.Context, req *mcp.CallToolRequest, args BatchParams) (*mcp.CallToolResult, any, error) {
+	if readOnlyMode && (args.Operation == "clone" || args.Operation == "pull") {
 		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Error: action must be 'set', 'get', or 'clear'"}},
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Error: batch %s is disabled in read-only mode", args.Operation)}},
 			IsError: true,
 		}, nil, nil
 	}
-}
-
-// Unified batch handler
 
-func handleBatch(ctx context.Context, req *mcp.CallToolRequest, args BatchParams) (*mcp.CallToolResult, any, error) {
 	wm := GetWorkspaceManager()
 	if wm == nil {
 		return &mcp.CallToolResult{
@@ -1210,32 +3857,11 @@ func handleBatch(ctx context.Context, req *mcp.CallToolRequest, args BatchParams
 			}, nil, nil
 		}
 
-		var results []BatchResult
-		for _, url := range args.URLs {
-			result := BatchResult{URL: url}
-			output, actualName, err := CloneRepository(url, "")
-			result.Name = actualName
-
-			if err != nil {
-				if strings.Contains(err.Error(), "already exists in workspace") {
-					pullOutput, pullErr := PullRepository(actualName)
-					if pullErr != nil {
-						result.Success = false
-						result.Error = fmt.Sprintf("Already exists but pull failed: %v", pullErr)
-					} else {
-						result.Success = true
-						result.Message = fmt.Sprintf("Already exists, pulled: %s", strings.TrimSpace(pullOutput))
-					}
-				} else {
-					result.Success = false
-					result.Error = err.Error()
-				}
-			} else {
-				result.Success = true
-				result.Message = fmt.Sprintf("Cloned: %s", strings.TrimSpace(output))
-			}
-			results = append(results, result)
+		entries := make([]CloneRepositoryEntry, len(args.URLs))
+		for i, url := range args.URLs {
+			entries[i] = CloneRepositoryEntry{URL: url}
 		}
+		results := cloneRepositories(ctx, entries, CloneOptions{})
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: formatBatchResults("clone", results)}},
 		}, nil, nil
@@ -1312,6 +3938,7 @@ func handleBatch(ctx context.Context, req *mcp.CallToolRequest, args BatchParams
 
 func formatBatchResults(operation string, results []BatchResult) string {
 	var sb strings.Builder
+	m := markers()
 
 	successCount := 0
 	for _, r := range results {
@@ -1332,28 +3959,28 @@ func formatBatchResults(operation string, results []BatchResult) string {
 		switch operation {
 		case "clone":
 			if r.Success {
-				sb.WriteString(fmt.Sprintf("✓ %s → %s\n", r.URL, r.Name))
+				sb.WriteString(fmt.Sprintf("%s %s %s %s\n", m.OK, r.URL, m.Arrow, r.Name))
 				if r.Message != "" {
 					sb.WriteString(fmt.Sprintf("  %s\n", r.Message))
 				}
 			} else {
-				sb.WriteString(fmt.Sprintf("✗ %s: %s\n", r.URL, r.Error))
+				sb.WriteString(fmt.Sprintf("%s %s: %s\n", m.Error, r.URL, r.Error))
 			}
 		case "pull":
 			if r.Success {
-				sb.WriteString(fmt.Sprintf("✓ %s: %s\n", name, r.Message))
+				sb.WriteString(fmt.Sprintf("%s %s: %s\n", m.OK, name, r.Message))
 			} else {
-				sb.WriteString(fmt.Sprintf("✗ %s: %s\n", name, r.Error))
+				sb.WriteString(fmt.Sprintf("%s %s: %s\n", m.Error, name, r.Error))
 			}
 		case "status":
 			if r.Error != "" {
-				sb.WriteString(fmt.Sprintf("✗ %s: %s\n", name, r.Error))
+				sb.WriteString(fmt.Sprintf("%s %s: %s\n", m.Error, name, r.Error))
 			} else {
 				changeStatus := "clean"
 				if r.HasChanges {
 					changeStatus = "changes"
 				}
-				sb.WriteString(fmt.Sprintf("📁 %s [%s] (%s)\n", name, r.Branch, changeStatus))
+				sb.WriteString(fmt.Sprintf("%s %s [%s] (%s)\n", m.Dir, name, r.Branch, changeStatus))
 			}
 		}
 	}
@@ -1363,6 +3990,7 @@ func formatBatchResults(operation string, results []BatchResult) string {
 
 func formatWorkspaceOverview(workspaceDir string, overviews []RepositoryOverview, includeCommits bool) string {
 	var result strings.Builder
+	m := markers()
 
 	result.WriteString(fmt.Sprintf("Workspace Overview (%s)\n", workspaceDir))
 	result.WriteString(strings.Repeat("=", 50) + "\n")
@@ -1375,16 +4003,16 @@ func formatWorkspaceOverview(workspaceDir string, overviews []RepositoryOverview
 
 	for _, o := range overviews {
 		if o.Error != "" {
-			result.WriteString(fmt.Sprintf("📁 %s: Error - %s\n\n", o.Name, o.Error))
+			result.WriteString(fmt.Sprintf("%s %s: Error - %s\n\n", m.Dir, o.Name, o.Error))
 			continue
 		}
 
-		changeStatus := "✓"
+		changeStatus := m.OK
 		if o.HasChanges {
-			changeStatus = "●"
+			changeStatus = m.Changed
 		}
 
-		result.WriteString(fmt.Sprintf("📁 %s %s\n", o.Name, changeStatus))
+		result.WriteString(fmt.Sprintf("%s %s %s\n", m.Dir, o.Name, changeStatus))
 		result.WriteString(fmt.Sprintf("   Branch: %s", o.CurrentBranch))
 		if o.BranchCount > 0 {
 			result.WriteString(fmt.Sprintf(" (%d total)", o.BranchCount))
@@ -1417,6 +4045,7 @@ func formatWorkspaceOverview(workspaceDir string, overviews []RepositoryOverview
 
 func formatMultiRepoSearchResults(results []RepoSearchResult, keywords []string, searchMode string) string {
 	var sb strings.Builder
+	m := markers()
 
 	totalMatches := 0
 	reposWithMatches := 0
@@ -1427,12 +4056,7 @@ func formatMultiRepoSearchResults(results []RepoSearchResult, keywords []string,
 		}
 	}
 
-	var keywordStr string
-	if searchMode == "or" {
-		keywordStr = strings.Join(keywords, " OR ")
-	} else {
-		keywordStr = strings.Join(keywords, " AND ")
-	}
+	keywordStr := searchKeywordLabel(keywords, searchMode)
 
 	sb.WriteString(fmt.Sprintf("Multi-Repository Search: %s\n", keywordStr))
 	sb.WriteString(strings.Repeat("=", 50) + "\n")
@@ -1440,7 +4064,7 @@ func formatMultiRepoSearchResults(results []RepoSearchResult, keywords []string,
 
 	for _, r := range results {
 		if r.Error != "" {
-			sb.WriteString(fmt.Sprintf("📁 %s: Error - %s\n\n", r.Repository, r.Error))
+			sb.WriteString(fmt.Sprintf("%s %s: Error - %s\n\n", m.Dir, r.Repository, r.Error))
 			continue
 		}
 
@@ -1448,17 +4072,25 @@ func formatMultiRepoSearchResults(results []RepoSearchResult, keywords []string,
 			continue
 		}
 
-		sb.WriteString(fmt.Sprintf("📁 %s (%d matches)\n", r.Repository, r.TotalCount))
+		sb.WriteString(fmt.Sprintf("%s %s (%d matches)\n", m.Dir, r.Repository, r.TotalCount))
 
 		for _, searchResult := range r.Results {
-			sb.WriteString(fmt.Sprintf("  📄 %s\n", searchResult.Path))
+			sb.WriteString(fmt.Sprintf("  %s %s\n", m.File, searchResult.Path))
 			if len(searchResult.Matches) > 0 {
+				function := 0
 				for _, match := range searchResult.Matches {
+					if match.FunctionLine > function {
+						sb.WriteString(fmt.Sprintf("     L%d= %s\n", match.FunctionLine, strings.TrimSpace(match.Function)))
+						function = match.FunctionLine
+					}
 					if match.LineNumber > 0 {
 						sb.WriteString(fmt.Sprintf("     L%d: %s\n", match.LineNumber, strings.TrimSpace(match.Content)))
 					}
 				}
 			}
+			if searchResult.OmittedMatches > 0 {
+				sb.WriteString(fmt.Sprintf("     ... and %d more matches\n", searchResult.OmittedMatches))
+			}
 		}
 		sb.WriteString("\n")
 	}
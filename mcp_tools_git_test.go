@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -46,3 +50,527 @@ func TestHandleCloneRepository(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleCloneRepositories(t *testing.T) {
+	first := CreateTestRepositoryWithContent(t)
+	second := CreateTestRepositoryWithContent(t)
+	globalWorkspaceManager = nil
+
+	workspaceDir := t.TempDir()
+	InitializeWorkspace(workspaceDir)
+	defer func() { globalWorkspaceManager = nil }()
+
+	args := CloneRepositoriesParams{Repositories: []CloneRepositoryEntry{
+		{URL: first.Path, Name: "first"},
+		{URL: second.Path, Name: "second"},
+		{URL: filepath.Join(t.TempDir(), "missing.git"), Name: "broken"},
+		{URL: second.Path, Name: "first"},
+	}}
+	results := cloneRepositories(context.Background(), args.Repositories, CloneOptions{})
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	for i, name := range []string{"first", "second"} {
+		if !results[i].Success || results[i].Name != name || !isGitRepository(filepath.Join(workspaceDir, name)) {
+			t.Errorf("Expected %s to be cloned, got %+v", name, results[i])
+		}
+	}
+	if results[2].Success || results[2].Error == "" {
+		t.Errorf("Expected the bad URL to fail, got %+v", results[2])
+	}
+	if _, err := os.Stat(filepath.Join(workspaceDir, "broken")); !os.IsNotExist(err) {
+		t.Errorf("Expected no directory left behind for the failed clone")
+	}
+	if results[3].Success || !strings.Contains(results[3].Error, "already used by another entry") {
+		t.Errorf("Expected a duplicate name error, got %+v", results[3])
+	}
+
+	// A second run pulls the existing clones and still reports the failure
+	result, _, err := handleCloneRepositories(context.Background(), nil, CloneRepositoriesParams{Repositories: args.Repositories[:3]})
+	if err != nil || result.IsError {
+		t.Fatalf("handleCloneRepositories failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "Batch clone (2/3 successful)") || strings.Count(text, "Already exists, pulled") != 2 || !strings.Contains(text, "missing.git") {
+		t.Errorf("Unexpected summary:\n%s", text)
+	}
+
+	result, _, _ = handleCloneRepositories(context.Background(), nil, CloneRepositoriesParams{})
+	if !result.IsError {
+		t.Error("Expected an error without repositories")
+	}
+}
+
+func TestHandleGetFileContentMergesPaths(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	getText := func(args GetFileContentParams) string {
+		t.Helper()
+		result, _, err := handleGetFileContent(context.Background(), nil, args)
+		if err != nil {
+			t.Fatalf("handleGetFileContent returned error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("handleGetFileContent returned tool error: %s", result.Content[0].(*mcp.TextContent).Text)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	t.Run("both fields with different files are merged", func(t *testing.T) {
+		text := getText(GetFileContentParams{
+			Repository: repo.Path,
+			FilePath:   "README.md",
+			FilePaths:  []string{"main.go", "version.txt"},
+		})
+		readme := strings.Index(text, "[README.md ")
+		mainGo := strings.Index(text, "[main.go ")
+		version := strings.Index(text, "[version.txt ")
+		if readme < 0 || mainGo < 0 || version < 0 {
+			t.Fatalf("Expected all three files in output:\n%s", text)
+		}
+		if !(readme < mainGo && mainGo < version) {
+			t.Errorf("Expected file_path first, then file_paths in order:\n%s", text)
+		}
+	})
+
+	t.Run("duplicates are removed", func(t *testing.T) {
+		text := getText(GetFileContentParams{
+			Repository: repo.Path,
+			FilePath:   "main.go",
+			FilePaths:  []string{"main.go", "version.txt", "version.txt"},
+		})
+		if strings.Count(text, "[main.go ") != 1 || strings.Count(text, "[version.txt ") != 1 {
+			t.Errorf("Expected each file exactly once:\n%s", text)
+		}
+	})
+
+	t.Run("same single file in both fields", func(t *testing.T) {
+		text := getText(GetFileContentParams{
+			Repository: repo.Path,
+			FilePath:   "main.go",
+			FilePaths:  []string{"main.go"},
+		})
+		if strings.Count(text, "[main.go ") != 1 {
+			t.Errorf("Expected main.go once:\n%s", text)
+		}
+	})
+}
+
+func TestHandleGetFileContentEndLine(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	read := func(t *testing.T, params GetFileContentParams) (string, bool) {
+		t.Helper()
+		params.Repository = repo.Path
+		params.FilePath = "src/utils.go"
+		result, _, err := handleGetFileContent(context.Background(), nil, params)
+		if err != nil {
+			t.Fatalf("handleGetFileContent failed: %v", err)
+		}
+		return result.Content[0].(*mcp.TextContent).Text, result.IsError
+	}
+
+	t.Run("mid-file range", func(t *testing.T) {
+		// max_lines is ignored when end_line is set
+		text, isError := read(t, GetFileContentParams{StartLine: 3, EndLine: 5, MaxLines: 1})
+		if isError || !strings.HasPrefix(text, "[src/utils.go L3-5/9]\n") {
+			t.Fatalf("Expected lines 3-5 of 9, got:\n%s", text)
+		}
+		if !strings.Contains(text, "func Add(a, b int) int {") || !strings.Contains(text, "return a + b") || strings.Contains(text, "Multiply") {
+			t.Errorf("Expected exactly the Add function body:\n%s", text)
+		}
+	})
+
+	t.Run("end_line beyond EOF", func(t *testing.T) {
+		text, isError := read(t, GetFileContentParams{StartLine: 7, EndLine: 500})
+		if isError || !strings.HasPrefix(text, "[src/utils.go L7-9/9]\n") {
+			t.Errorf("Expected the range clamped to line 9, got:\n%s", text)
+		}
+	})
+
+	t.Run("end_line before start_line", func(t *testing.T) {
+		text, isError := read(t, GetFileContentParams{StartLine: 5, EndLine: 4})
+		if !isError || !strings.Contains(text, "end_line (4) must be >= start_line (5)") {
+			t.Errorf("Expected a validation error, got:\n%s", text)
+		}
+	})
+}
+
+func TestHandleGetFileContentBinary(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01"
+	repo.WriteFile("logo.png", png)
+	repo.AddCommit("Add image")
+
+	call := func(args GetFileContentParams) *mcp.CallToolResult {
+		t.Helper()
+		result, _, err := handleGetFileContent(context.Background(), nil, args)
+		if err != nil {
+			t.Fatalf("handleGetFileContent returned error: %v", err)
+		}
+		return result
+	}
+
+	t.Run("single binary file is refused", func(t *testing.T) {
+		result := call(GetFileContentParams{Repository: repo.Path, FilePath: "logo.png"})
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !result.IsError || !strings.Contains(text, "binary file (20 bytes)") {
+			t.Errorf("Expected binary error with size, got: %s", text)
+		}
+	})
+
+	t.Run("binary file among several reports an error entry", func(t *testing.T) {
+		result := call(GetFileContentParams{Repository: repo.Path, FilePaths: []string{"logo.png", "main.go"}})
+		if result.IsError {
+			t.Fatalf("Unexpected tool error: %v", result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "[logo.png ERR:binary file") || !strings.Contains(text, "package main") {
+			t.Errorf("Expected binary error entry and text file content, got: %s", text)
+		}
+
+		results, err := GetMultipleFileContentsWithLineNumbers(repo.Path, []string{"logo.png"}, 1, 100, true)
+		if err != nil || len(results) != 1 || !strings.Contains(results[0].Error, "binary file") {
+			t.Errorf("Expected FileContentResult error for binary file, got %+v (%v)", results, err)
+		}
+	})
+
+	t.Run("force_binary reads the file", func(t *testing.T) {
+		result := call(GetFileContentParams{Repository: repo.Path, FilePath: "logo.png", ForceBinary: true})
+		text := result.Content[0].(*mcp.TextContent).Text
+		if result.IsError || !strings.Contains(text, "PNG") {
+			t.Errorf("Expected forced read to succeed, got: %s", text)
+		}
+	})
+
+	t.Run("text files are unchanged", func(t *testing.T) {
+		result := call(GetFileContentParams{Repository: repo.Path, FilePath: "main.go"})
+		if result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "package main") {
+			t.Errorf("Expected text file content, got: %v", result.Content)
+		}
+	})
+}
+
+func TestHandleListRepositoriesDetailed(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.runGitCommand("remote", "add", "origin", "https://example.com/test-repo.git")
+
+	clonePath := filepath.Join(filepath.Dir(repo.Path), "clone-repo")
+	if output, err := exec.Command("git", "clone", "-q", "-b", "develop", repo.Path, clonePath).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to clone: %v\n%s", err, output)
+	}
+	if err := os.WriteFile(filepath.Join(clonePath, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify clone: %v", err)
+	}
+
+	result, _, err := handleListWorkspaceRepositories(context.Background(), nil, ListWorkspaceRepositoriesParams{Detailed: true})
+	if err != nil || result.IsError {
+		t.Fatalf("handleListWorkspaceRepositories failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	// NAME BRANCH STATUS SIZE (number and unit) UPDATED REMOTE
+	rows := make(map[string][]string)
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 7 {
+			rows[fields[0]] = append(fields[1:3:3], fields[6])
+		}
+	}
+
+	expected := map[string][]string{
+		"clone-repo": {"develop", "dirty", repo.Path},
+		"test-repo":  {repo.getCurrentBranch(), "clean", "https://example.com/test-repo.git"},
+	}
+	for name, want := range expected {
+		if strings.Join(rows[name], " ") != strings.Join(want, " ") {
+			t.Errorf("Row %s: expected %v, got %v in:\n%s", name, want, rows[name], text)
+		}
+	}
+	if strings.Index(text, "clone-repo") > strings.Index(text, "test-repo") {
+		t.Errorf("Expected rows in listing order:\n%s", text)
+	}
+}
+
+func TestHandlersUseSessionDefaults(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	SetSessionConfigValues(&SessionConfig{
+		DefaultRepository:     repo.Path,
+		DefaultListFilesLimit: 2,
+		DefaultCommitLimit:    1,
+	})
+	defer ClearSessionConfig()
+
+	textOf := func(name string, result *mcp.CallToolResult, err error) string {
+		t.Helper()
+		if err != nil || result.IsError {
+			t.Fatalf("%s failed with session default repository: %v %v", name, err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	result, _, err := handleListFiles(context.Background(), nil, ListFilesParams{Recursive: true})
+	text := textOf("list_files", result, err)
+	if !strings.Contains(text, ", 2 files)") {
+		t.Errorf("Expected default list_files limit of 2, got:\n%s", text)
+	}
+
+	result, _, err = handleListCommits(context.Background(), nil, ListCommitsParams{})
+	text = textOf("list_commits", result, err)
+	if !strings.Contains(text, "(1 commits") {
+		t.Errorf("Expected default commit limit of 1, got:\n%s", text)
+	}
+
+	result, _, err = handleGetFileContent(context.Background(), nil, GetFileContentParams{FilePaths: []string{"main.go"}})
+	textOf("get_file_content", result, err)
+
+	result, _, err = handleSearchFiles(context.Background(), nil, SearchFilesParams{Keywords: []string{"postgres"}})
+	text = textOf("search_files", result, err)
+	if !strings.Contains(text, "config.json") {
+		t.Errorf("Expected search_files to find config.json in the default repository, got:\n%s", text)
+	}
+
+	result, _, err = handleListBranches(context.Background(), nil, ListBranchesParams{})
+	textOf("list_branches", result, err)
+
+	result, _, err = handleGetReadmeFiles(context.Background(), nil, GetReadmeFilesParams{})
+	textOf("get_readme_files", result, err)
+
+	ClearSessionConfig()
+	result, _, err = handleListFiles(context.Background(), nil, ListFilesParams{})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected error without a default repository, got: %v", result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "no default set") {
+		t.Errorf("Expected 'no default set' error, got: %s", text)
+	}
+}
+
+func TestHandleRead(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	ClearSessionConfig()
+	defer ClearSessionConfig()
+
+	// Without a default repository read fails cleanly
+	result, _, err := handleRead(context.Background(), nil, ReadParams{Path: "main.go"})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected error without a default repository, got: %v", result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "default repository") {
+		t.Errorf("Expected default repository error, got: %s", text)
+	}
+
+	SetSessionConfigValues(&SessionConfig{DefaultRepository: repo.Path})
+
+	result, _, err = handleRead(context.Background(), nil, ReadParams{Path: "main.go"})
+	if err != nil || result.IsError {
+		t.Fatalf("read failed with a default repository: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.HasPrefix(text, "[main.go L1-") || !strings.Contains(text, "Hello, World!") {
+		t.Errorf("Expected main.go content, got:\n%s", text)
+	}
+
+	result, _, err = handleRead(context.Background(), nil, ReadParams{Path: "main.go", StartLine: 3, EndLine: 3})
+	if err != nil || result.IsError {
+		t.Fatalf("read with a line range failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.HasPrefix(text, "[main.go L3-3/") {
+		t.Errorf("Expected line 3 only, got:\n%s", text)
+	}
+
+	result, _, err = handleRead(context.Background(), nil, ReadParams{})
+	if err != nil || !result.IsError {
+		t.Errorf("Expected error for missing path, got: %v", result.Content)
+	}
+}
+
+func TestHandlersJSONOutput(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	ctx := context.Background()
+
+	decode := func(t *testing.T, result *mcp.CallToolResult, err error, v any) {
+		t.Helper()
+		if err != nil || result.IsError {
+			t.Fatalf("Handler failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if err := json.Unmarshal([]byte(text), v); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, text)
+		}
+	}
+
+	t.Run("search_files", func(t *testing.T) {
+		result, _, err := handleSearchFiles(ctx, nil, SearchFilesParams{Repository: repo.Path, Keywords: []string{"postgres"}, OutputFormat: "json"})
+		var results []SearchResult
+		decode(t, result, err, &results)
+		if len(results) != 1 || results[0].Path != "config.json" || len(results[0].Matches) != 1 || results[0].Matches[0].LineNumber != 2 {
+			t.Errorf("Unexpected search results: %+v", results)
+		}
+
+		result, _, err = handleSearchFiles(ctx, nil, SearchFilesParams{Repository: repo.Path, Keywords: []string{"nonexistent-keyword"}, OutputFormat: "json"})
+		if text := result.Content[0].(*mcp.TextContent).Text; err != nil || text != "[]" {
+			t.Errorf("Expected [] for no matches, got %q", text)
+		}
+	})
+
+	t.Run("list_files", func(t *testing.T) {
+		result, _, err := handleListFiles(ctx, nil, ListFilesParams{Repository: repo.Path, Directory: "src", OutputFormat: "json"})
+		var files []FileInfo
+		decode(t, result, err, &files)
+		if len(files) != 1 || files[0].Path != filepath.Join("src", "utils.go") || files[0].LineCount != 9 {
+			t.Errorf("Unexpected files: %+v", files)
+		}
+	})
+
+	t.Run("list_commits", func(t *testing.T) {
+		result, _, err := handleListCommits(ctx, nil, ListCommitsParams{Repository: repo.Path, Limit: 2, OutputFormat: "json"})
+		var commits []Commit
+		decode(t, result, err, &commits)
+		if len(commits) != 2 || commits[0].Message != "Add configuration" || commits[0].Hash == "" {
+			t.Errorf("Unexpected commits: %+v", commits)
+		}
+	})
+
+	t.Run("get_file_content", func(t *testing.T) {
+		result, _, err := handleGetFileContent(ctx, nil, GetFileContentParams{Repository: repo.Path, FilePath: "src/utils.go", StartLine: 3, EndLine: 4, OutputFormat: "json"})
+		var contents []FileContentResult
+		decode(t, result, err, &contents)
+		if len(contents) != 1 || contents[0].StartLine != 3 || contents[0].EndLine != 4 || contents[0].TotalLines != 9 {
+			t.Fatalf("Unexpected content: %+v", contents)
+		}
+		if contents[0].Content != "func Add(a, b int) int {\n\treturn a + b\n" {
+			t.Errorf("Expected raw lines without number prefixes, got %q", contents[0].Content)
+		}
+
+		result, _, err = handleGetFileContent(ctx, nil, GetFileContentParams{Repository: repo.Path, FilePaths: []string{"main.go", "missing.go"}, OutputFormat: "json"})
+		decode(t, result, err, &contents)
+		if len(contents) != 2 || contents[0].Error != "" || contents[1].Error == "" {
+			t.Errorf("Expected content for main.go and an error for missing.go, got %+v", contents)
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		result, _, _ := handleListFiles(ctx, nil, ListFilesParams{Repository: repo.Path, OutputFormat: "yaml"})
+		if !result.IsError {
+			t.Error("Expected error for unknown output_format")
+		}
+	})
+}
+
+func TestFormatFileTree(t *testing.T) {
+	files := []FileInfo{
+		{Path: filepath.Join("pkg", "a", "x.go"), Size: 10, LineCount: 1},
+		{Path: filepath.Join("pkg", "a", "y.go")},
+		{Path: filepath.Join("pkg", "b.go")},
+		{Path: filepath.Join("pkg", "c.go")},
+	}
+
+	text := formatFileTree(files, "pkg", 0)
+	want := "pkg/\n" +
+		"├── a/\n" +
+		"│   ├── x.go (10B, 1L)\n" +
+		"│   └── y.go\n" +
+		"├── b.go\n" +
+		"└── c.go\n"
+	if !strings.HasSuffix(text, want) {
+		t.Errorf("Unexpected tree, want suffix:\n%s\ngot:\n%s", want, text)
+	}
+
+	// Directory lines count toward the limit
+	text = formatFileTree(files, "pkg", 2)
+	if !strings.Contains(text, "│   ├── x.go") || strings.Contains(text, "y.go") || !strings.Contains(text, "(Limited to 2 entries)") {
+		t.Errorf("Expected the tree cut after 2 entries:\n%s", text)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		repo := CreateTestRepositoryWithContent(t)
+		result, _, err := handleListFiles(context.Background(), nil, ListFilesParams{Repository: repo.Path, Recursive: true, Tree: true})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListFiles failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"├── docs/\n│   └── api.md", "├── src/\n│   └── utils.go", "└── version.txt"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+}
+
+func TestListFilesTotals(t *testing.T) {
+	for n, want := range map[int]string{0: "0", 999: "999", 1000: "1,000", 8204: "8,204", 1234567: "1,234,567", -4321: "-4,321"} {
+		if got := formatThousands(n); got != want {
+			t.Errorf("formatThousands(%d) = %s, want %s", n, got, want)
+		}
+	}
+
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("stats/a.txt", "one\ntwo\n")
+	repo.WriteFile("stats/sub/b.txt", "x\n")
+	repo.WriteFile("stats/sub/deep/c.txt", strings.Repeat("line\n", 1200))
+	repo.WriteFile("stats/other/d.txt", "")
+	repo.AddCommit("Add stats fixtures")
+
+	totals := summarizeFiles([]FileInfo{
+		{Path: filepath.Join("stats", "a.txt"), Size: 8, LineCount: 2},
+		{Path: filepath.Join("stats", "sub", "deep", "c.txt"), Size: 6000, LineCount: 1200},
+	}, "stats")
+	if totals != (fileListTotals{Files: 2, Dirs: 2, Size: 6008, Lines: 1202}) {
+		t.Errorf("Unexpected totals %+v", totals)
+	}
+
+	list := func(recursive bool) string {
+		t.Helper()
+		result, _, err := handleListFiles(context.Background(), nil, ListFilesParams{Repository: repo.Path, Directory: "stats", Recursive: recursive})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	// 8 + 2 + 6000 + 0 bytes, 2 + 1 + 1200 + 0 lines in sub, sub/deep and other
+	if text := list(true); !strings.Contains(text, "Total: 4 files, 3 dirs, 5.9 KB, 1,203 lines\n") {
+		t.Errorf("Expected recursive totals:\n%s", text)
+	}
+	if text := list(false); !strings.Contains(text, "Total: 1 files, 0 dirs, 8 B, 2 lines\n") {
+		t.Errorf("Expected non-recursive totals:\n%s", text)
+	}
+
+	result, _, err := handleListFiles(context.Background(), nil, ListFilesParams{Repository: repo.Path, Directory: "stats", OutputFormat: "json"})
+	if err != nil || result.IsError || strings.Contains(result.Content[0].(*mcp.TextContent).Text, "Total:") {
+		t.Errorf("Expected the JSON output to stay a plain file array: %v %v", err, result.Content)
+	}
+}
+
+func TestHandleGetFileContentBOM(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("bom.txt", "\ufeffhello  \nworld\t\n")
+
+	read := func(t *testing.T, params GetFileContentParams) string {
+		t.Helper()
+		params.Repository = repo.Path
+		params.FilePath = "bom.txt"
+		result, _, err := handleGetFileContent(context.Background(), nil, params)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetFileContent failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := read(t, GetFileContentParams{})
+	if strings.Contains(text, "\ufeff") || !strings.Contains(text, "   1: hello  \n") {
+		t.Errorf("Expected the BOM dropped and whitespace kept:\n%q", text)
+	}
+
+	text = read(t, GetFileContentParams{TrimTrailingWhitespace: true})
+	if !strings.Contains(text, "   1: hello\n   2: world\n") {
+		t.Errorf("Expected trailing whitespace trimmed:\n%q", text)
+	}
+
+	text = read(t, GetFileContentParams{OutputFormat: "json"})
+	if strings.Contains(text, "\ufeff") || !strings.Contains(text, `"content": "hello  \nworld\t\n"`) {
+		t.Errorf("Expected the BOM dropped from JSON content:\n%s", text)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -41,20 +42,46 @@ type ListMemosParams struct {
 	Query      string   `json:"query,omitempty"`      // Search query for title/content
 	Tags       []string `json:"tags,omitempty"`       // Filter by tags
 	Limit      int      `json:"limit,omitempty"`      // Maximum number of results (default: 50)
+	Offset     int      `json:"offset,omitempty"`     // Skip this many results (most recently updated first)
+}
+
+// ExportMemosParams parameters for export_memos tool
+type ExportMemosParams struct {
+	Repository string `json:"repository,omitempty"` // Only export memos for this repository (default: all)
 }
 
 // RegisterMemoTools registers all memo-related MCP tools
 func RegisterMemoTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "add_memo",
-		Description: "Add memo with title, content, optional repo/tags",
-	}, handleAddMemo)
-
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_memo",
 		Description: "Get memo by ID",
 	}, handleGetMemo)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_memos",
+		Description: "List/search memos. Filter by repo, query, tags.",
+	}, handleListMemos)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_memo_tags",
+		Description: "List all memo tags with how many memos use each",
+	}, handleListMemoTags)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "export_memos",
+		Description: "Export memos as a Markdown document, optionally for one repo",
+	}, handleExportMemos)
+
+	// Tools that write memos.json are not registered in read-only mode
+	if readOnlyMode {
+		return
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "add_memo",
+		Description: "Add memo with title, content, optional repo/tags",
+	}, handleAddMemo)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "update_memo",
 		Description: "Update memo by ID",
@@ -65,11 +92,6 @@ func RegisterMemoTools(server *mcp.Server) {
 		Description: "Delete memo by ID",
 	}, handleDeleteMemo)
 
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "list_memos",
-		Description: "List/search memos. Filter by repo, query, tags.",
-	}, handleListMemos)
-
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "delete_all_memos",
 		Description: "Delete all memos (caution)",
@@ -221,19 +243,29 @@ func handleListMemos(ctx context.Context, req *mcp.CallToolRequest, args ListMem
 		limit = 50 // Default limit
 	}
 
-	memos := store.SearchMemos(args.Query, args.Repository, args.Tags, limit)
+	if args.Offset < 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: offset must not be negative"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	memos, total := store.SearchMemosPage(args.Query, args.Repository, args.Tags, args.Offset, limit)
 
 	var result strings.Builder
-	result.WriteString(fmt.Sprintf("Found %d memo(s)", len(memos)))
+	result.WriteString(fmt.Sprintf("Found %d memo(s)", total))
 	if args.Repository != "" {
 		result.WriteString(fmt.Sprintf(" for repository: %s", args.Repository))
 	}
+	if len(memos) > 0 && len(memos) < total {
+		result.WriteString(fmt.Sprintf(", showing %d-%d", args.Offset+1, args.Offset+len(memos)))
+	}
 	result.WriteString("\n")
 	result.WriteString(strings.Repeat("=", 50) + "\n\n")
 
 	for i, memo := range memos {
 		// Show full ID for AI usability (was truncated to 8 chars before)
-		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, memo.Title))
+		result.WriteString(fmt.Sprintf("%d. %s\n", args.Offset+i+1, memo.Title))
 		result.WriteString(fmt.Sprintf("   ID: %s\n", memo.ID))
 		if memo.Repository != "" {
 			result.WriteString(fmt.Sprintf("   Repository: %s\n", memo.Repository))
@@ -254,7 +286,9 @@ func handleListMemos(ctx context.Context, req *mcp.CallToolRequest, args ListMem
 		result.WriteString("\n")
 	}
 
-	if len(memos) == 0 {
+	if len(memos) == 0 && total > 0 {
+		result.WriteString(fmt.Sprintf("No memos at offset %d.\n", args.Offset))
+	} else if len(memos) == 0 {
 		result.WriteString("No memos found")
 		if args.Query != "" || len(args.Tags) > 0 || args.Repository != "" {
 			result.WriteString(" matching the search criteria")
@@ -262,12 +296,76 @@ func handleListMemos(ctx context.Context, req *mcp.CallToolRequest, args ListMem
 		result.WriteString(".\n")
 	}
 
+	if next := args.Offset + len(memos); len(memos) > 0 && next < total {
+		result.WriteString(fmt.Sprintf("More memos available: use offset=%d for the next page.\n", next))
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
 		IsError: false,
 	}, nil, nil
 }
 
+func handleListMemoTags(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
+	store := GetMemoStore()
+	if store == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: memo store not initialized"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	counts := store.GetAllTags()
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d tag(s)\n", len(tags)))
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+	for _, tag := range tags {
+		result.WriteString(fmt.Sprintf("%5d  %s\n", counts[tag], tag))
+	}
+	if len(tags) == 0 {
+		result.WriteString("No tagged memos.\n")
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+		IsError: false,
+	}, nil, nil
+}
+
+func handleExportMemos(ctx context.Context, req *mcp.CallToolRequest, args ExportMemosParams) (*mcp.CallToolResult, any, error) {
+	store := GetMemoStore()
+	if store == nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: memo store not initialized"}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	markdown, err := store.ExportMemos(args.Repository)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to export memos: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: markdown}},
+		IsError: false,
+	}, nil, nil
+}
+
 func handleDeleteAllMemos(ctx context.Context, req *mcp.CallToolRequest, args any) (*mcp.CallToolResult, any, error) {
 	store := GetMemoStore()
 	if store == nil {
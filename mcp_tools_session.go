@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SessionParams parameters for session tool (unified set/get/clear)
+type SessionParams struct {
+	Action                    string   `json:"action"`                                 // "set", "get", or "clear"
+	DefaultRepository         string   `json:"default_repository,omitempty"`           // for "set"
+	DefaultIncludePatterns    []string `json:"default_include_patterns,omitempty"`     // for "set"
+	DefaultExcludePatterns    []string `json:"default_exclude_patterns,omitempty"`     // for "set"
+	DefaultSearchLimit        int      `json:"default_search_limit,omitempty"`         // for "set"
+	DefaultListFilesLimit     int      `json:"default_list_files_limit,omitempty"`     // for "set"
+	DefaultMaxLines           int      `json:"default_max_lines,omitempty"`            // for "set"
+	DefaultCommitLimit        int      `json:"default_commit_limit,omitempty"`         // for "set"
+	DefaultMaxFileBytes       int64    `json:"default_max_file_bytes,omitempty"`       // for "set": largest file read without max_lines
+	DefaultCloneTimeout       int      `json:"default_clone_timeout,omitempty"`        // for "set": seconds before a clone is killed
+	DefaultReadmePreviewLines int      `json:"default_readme_preview_lines,omitempty"` // for "set": README lines shown by get_repository_info
+	NoEmoji                   *bool    `json:"no_emoji,omitempty"`                     // for "set": plain ASCII markers instead of emoji
+}
+
+// SetSessionConfigParams parameters for set_session_config tool
+type SetSessionConfigParams struct {
+	DefaultRepository         string   `json:"default_repository,omitempty"`
+	DefaultIncludePatterns    []string `json:"default_include_patterns,omitempty"`
+	DefaultExcludePatterns    []string `json:"default_exclude_patterns,omitempty"`
+	DefaultSearchLimit        int      `json:"default_search_limit,omitempty"`
+	DefaultListFilesLimit     int      `json:"default_list_files_limit,omitempty"`
+	DefaultMaxLines           int      `json:"default_max_lines,omitempty"`
+	DefaultCommitLimit        int      `json:"default_commit_limit,omitempty"`
+	DefaultMaxFileBytes       int64    `json:"default_max_file_bytes,omitempty"`       // Largest file read without max_lines (default: 10MB)
+	DefaultCloneTimeout       int      `json:"default_clone_timeout,omitempty"`        // Seconds before a clone is killed (default: 600)
+	DefaultReadmePreviewLines int      `json:"default_readme_preview_lines,omitempty"` // README lines shown by get_repository_info (default: 100)
+	NoEmoji                   *bool    `json:"no_emoji,omitempty"`                     // Plain ASCII markers instead of emoji
+}
+
+// GetSessionConfigParams parameters for get_session_config tool
+type GetSessionConfigParams struct{}
+
+// ClearSessionConfigParams parameters for clear_session_config tool
+type ClearSessionConfigParams struct{}
+
+// RegisterSessionTools registers all session configuration MCP tools
+func RegisterSessionTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "session",
+		Description: "Session config: action=set/get/clear. Set defaults for repo, patterns, limits.",
+	}, handleSession)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "set_session_config",
+		Description: "Set session defaults for repo, patterns, limits. Unset fields keep their value.",
+	}, handleSetSessionConfig)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_session_config",
+		Description: "Show current session defaults",
+	}, handleGetSessionConfig)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "clear_session_config",
+		Description: "Reset all session defaults",
+	}, handleClearSessionConfig)
+}
+
+// Unified session handler
+
+func handleSession(ctx context.Context, req *mcp.CallToolRequest, args SessionParams) (*mcp.CallToolResult, any, error) {
+	switch args.Action {
+	case "set":
+		return handleSetSessionConfig(ctx, req, SetSessionConfigParams{
+			DefaultRepository:         args.DefaultRepository,
+			DefaultIncludePatterns:    args.DefaultIncludePatterns,
+			DefaultExcludePatterns:    args.DefaultExcludePatterns,
+			DefaultSearchLimit:        args.DefaultSearchLimit,
+			DefaultListFilesLimit:     args.DefaultListFilesLimit,
+			DefaultMaxLines:           args.DefaultMaxLines,
+			DefaultCommitLimit:        args.DefaultCommitLimit,
+			DefaultMaxFileBytes:       args.DefaultMaxFileBytes,
+			DefaultCloneTimeout:       args.DefaultCloneTimeout,
+			DefaultReadmePreviewLines: args.DefaultReadmePreviewLines,
+			NoEmoji:                   args.NoEmoji,
+		})
+
+	case "get":
+		return handleGetSessionConfig(ctx, req, GetSessionConfigParams{})
+
+	case "clear":
+		return handleClearSessionConfig(ctx, req, ClearSessionConfigParams{})
+
+	default:
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Error: action must be 'set', 'get', or 'clear'"}},
+			IsError: true,
+		}, nil, nil
+	}
+}
+
+func handleSetSessionConfig(ctx context.Context, req *mcp.CallToolRequest, args SetSessionConfigParams) (*mcp.CallToolResult, any, error) {
+	config := &SessionConfig{
+		DefaultRepository:         args.DefaultRepository,
+		DefaultIncludePatterns:    args.DefaultIncludePatterns,
+		DefaultExcludePatterns:    args.DefaultExcludePatterns,
+		DefaultSearchLimit:        args.DefaultSearchLimit,
+		DefaultListFilesLimit:     args.DefaultListFilesLimit,
+		DefaultMaxLines:           args.DefaultMaxLines,
+		DefaultCommitLimit:        args.DefaultCommitLimit,
+		DefaultMaxFileBytes:       args.DefaultMaxFileBytes,
+		DefaultCloneTimeout:       args.DefaultCloneTimeout,
+		DefaultReadmePreviewLines: args.DefaultReadmePreviewLines,
+		NoEmoji:                   args.NoEmoji,
+	}
+	SetSessionConfigValues(config)
+
+	var result strings.Builder
+	result.WriteString("Session configuration updated:\n")
+	result.WriteString(strings.Repeat("-", 30) + "\n")
+	if args.DefaultRepository != "" {
+		result.WriteString(fmt.Sprintf("default_repository: %s\n", args.DefaultRepository))
+	}
+	if len(args.DefaultIncludePatterns) > 0 {
+		result.WriteString(fmt.Sprintf("default_include_patterns: %v\n", args.DefaultIncludePatterns))
+	}
+	if len(args.DefaultExcludePatterns) > 0 {
+		result.WriteString(fmt.Sprintf("default_exclude_patterns: %v\n", args.DefaultExcludePatterns))
+	}
+	if args.DefaultSearchLimit > 0 {
+		result.WriteString(fmt.Sprintf("default_search_limit: %d\n", args.DefaultSearchLimit))
+	}
+	if args.DefaultListFilesLimit > 0 {
+		result.WriteString(fmt.Sprintf("default_list_files_limit: %d\n", args.DefaultListFilesLimit))
+	}
+	if args.DefaultMaxLines > 0 {
+		result.WriteString(fmt.Sprintf("default_max_lines: %d\n", args.DefaultMaxLines))
+	}
+	if args.DefaultCommitLimit > 0 {
+		result.WriteString(fmt.Sprintf("default_commit_limit: %d\n", args.DefaultCommitLimit))
+	}
+	if args.DefaultMaxFileBytes > 0 {
+		result.WriteString(fmt.Sprintf("default_max_file_bytes: %d\n", args.DefaultMaxFileBytes))
+	}
+	if args.DefaultCloneTimeout > 0 {
+		result.WriteString(fmt.Sprintf("default_clone_timeout: %d\n", args.DefaultCloneTimeout))
+	}
+	if args.DefaultReadmePreviewLines > 0 {
+		result.WriteString(fmt.Sprintf("default_readme_preview_lines: %d\n", args.DefaultReadmePreviewLines))
+	}
+	if args.NoEmoji != nil {
+		result.WriteString(fmt.Sprintf("no_emoji: %t\n", *args.NoEmoji))
+	}
+	if err := persistSessionConfig(); err != nil {
+		result.WriteString(fmt.Sprintf("\nWarning: configuration applied but not saved: %v\n", err))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
+func handleGetSessionConfig(ctx context.Context, req *mcp.CallToolRequest, args GetSessionConfigParams) (*mcp.CallToolResult, any, error) {
+	sc := GetSessionConfig()
+	if sc.IsEmpty() {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "No session configuration set.\nUse set_session_config with: default_repository, default_include_patterns, default_exclude_patterns, default_search_limit, default_list_files_limit, default_max_lines, default_commit_limit, default_max_file_bytes, default_clone_timeout, default_readme_preview_lines, no_emoji"}},
+		}, nil, nil
+	}
+
+	configMap := sc.ToMap()
+	keys := make([]string, 0, len(configMap))
+	for key := range configMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result strings.Builder
+	result.WriteString("Current Session Configuration:\n")
+	result.WriteString(strings.Repeat("=", 50) + "\n\n")
+	for _, key := range keys {
+		result.WriteString(fmt.Sprintf("%s: %v\n", key, configMap[key]))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
+func handleClearSessionConfig(ctx context.Context, req *mcp.CallToolRequest, args ClearSessionConfigParams) (*mcp.CallToolResult, any, error) {
+	ClearSessionConfig()
+	text := "Session configuration cleared. All values reset to defaults."
+	if err := persistSessionConfig(); err != nil {
+		text += fmt.Sprintf("\nWarning: cleared configuration not saved: %v", err)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, nil, nil
+}
+
+// persistSessionConfig saves the session configuration into the workspace, if
+// one is initialized. In read-only mode the configuration only lives in memory.
+func persistSessionConfig() error {
+	wm := GetWorkspaceManager()
+	if wm == nil || readOnlyMode {
+		return nil
+	}
+	return SaveSessionConfig(wm.GetWorkspaceDir())
+}
@@ -2,8 +2,13 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"unicode"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -64,6 +69,106 @@ func TestHandleGetRepositoryInfo(t *testing.T) {
 	}
 }
 
+func TestHandleGetRepositoryInfoDetachedHEAD(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("CHANGELOG.md", "# Changelog\n")
+	repo.AddCommit("Add changelog")
+	repo.runGitCommand("checkout", "--detach", "HEAD~1")
+
+	output, err := exec.Command("git", "-C", repo.Path, "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("Failed to resolve HEAD: %v", err)
+	}
+	want := "Branch: (detached HEAD at " + strings.TrimSpace(string(output)) + ")\n"
+
+	result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repo.Path})
+	if err != nil || result.IsError {
+		t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, want) {
+		t.Errorf("Expected %q in output:\n%s", want, text)
+	}
+}
+
+func TestHandleGetRepositoryInfoDefaultBranch(t *testing.T) {
+	source := CreateTestRepositoryWithContent(t)
+	clonePath := filepath.Join(filepath.Dir(source.Path), "clone-repo")
+	if output, err := exec.Command("git", "clone", "-q", source.Path, clonePath).CombinedOutput(); err != nil {
+		t.Fatalf("Failed to clone: %v\n%s", err, output)
+	}
+	if output, err := exec.Command("git", "-C", clonePath, "checkout", "-q", "develop").CombinedOutput(); err != nil {
+		t.Fatalf("Failed to switch branch: %v\n%s", err, output)
+	}
+
+	info, err := GetRepositoryInfo(clonePath)
+	if err != nil {
+		t.Fatalf("GetRepositoryInfo failed: %v", err)
+	}
+	if info.CurrentBranch != "develop" || info.DefaultBranch != "main" {
+		t.Errorf("Expected current develop and default main, got %s and %s", info.CurrentBranch, info.DefaultBranch)
+	}
+
+	result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: clonePath})
+	if err != nil || result.IsError {
+		t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Branch: develop\nDefault Branch: main\n") {
+		t.Errorf("Expected both branches in output:\n%s", text)
+	}
+
+	// Without origin/HEAD the local main branch is used
+	source.runGitCommand("checkout", "develop")
+	if info, err := GetRepositoryInfo(source.Path); err != nil || info.DefaultBranch != "main" {
+		t.Errorf("Expected default branch main without a remote, got %+v (%v)", info, err)
+	}
+}
+
+func TestHandleGetRepositoryInfoReadmePreview(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	var readme strings.Builder
+	for i := 1; i <= 150; i++ {
+		readme.WriteString(fmt.Sprintf("README line %d\n", i))
+	}
+	repo.WriteFile("README.md", readme.String())
+	repo.AddCommit("Long README")
+
+	read := func(t *testing.T, readmeLines int) string {
+		t.Helper()
+		result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repo.Path, ReadmeLines: readmeLines})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	t.Run("default", func(t *testing.T) {
+		text := read(t, 0)
+		if !strings.Contains(text, "README line 100\n(truncated, 50 more lines)\n") || strings.Contains(text, "README line 101\n") {
+			t.Errorf("Expected the README cut after 100 lines:\n%s", text)
+		}
+	})
+
+	t.Run("session default", func(t *testing.T) {
+		SetSessionConfigValues(&SessionConfig{DefaultReadmePreviewLines: 20})
+		defer ClearSessionConfig()
+		if text := read(t, 0); !strings.Contains(text, "README line 20\n(truncated, 130 more lines)\n") {
+			t.Errorf("Expected the README cut after 20 lines:\n%s", text)
+		}
+	})
+
+	t.Run("whole README", func(t *testing.T) {
+		if text := read(t, 150); !strings.HasSuffix(text, "README line 150\n") || strings.Contains(text, "truncated") {
+			t.Errorf("Expected the full README:\n%s", text)
+		}
+	})
+
+	// The cached info keeps the full README for export_overview and context_pack
+	info, err := GetRepositoryInfo(repo.Path)
+	if err != nil || info.ReadmeContent != readme.String() {
+		t.Errorf("Expected the full README in repository info (%v)", err)
+	}
+}
+
 func TestHandleSearchFiles(t *testing.T) {
 	repo := CreateTestRepositoryWithContent(t)
 
@@ -143,6 +248,63 @@ func TestFormatSearchResults(t *testing.T) {
 	}
 }
 
+// Test that formatters switch between emoji and plain markers
+func TestFormatMarkers(t *testing.T) {
+	defer ClearSessionConfig()
+	defer func() { defaultNoEmoji = false }()
+
+	render := func() string {
+		var out strings.Builder
+		out.WriteString(formatSearchResults([]SearchResult{{Path: "file1.go"}}, []string{"keyword"}, "and"))
+		out.WriteString(formatWorkspaceRepositories([]string{"repo1"}, "/workspace"))
+		out.WriteString(formatBatchResults("clone", []BatchResult{
+			{URL: "https://example.com/a.git", Name: "a", Success: true},
+			{URL: "https://example.com/b.git", Error: "failed"},
+		}))
+		out.WriteString(formatWorkspaceOverview("/workspace", []RepositoryOverview{{Name: "repo1", HasChanges: true}}, false))
+		return out.String()
+	}
+	hasNonASCII := func(s string) bool {
+		for _, r := range s {
+			if r > unicode.MaxASCII {
+				return true
+			}
+		}
+		return false
+	}
+
+	output := render()
+	for _, marker := range []string{"📄 file1.go", "📁 repo1", "✓ https://example.com/a.git → a", "✗ https://example.com/b.git"} {
+		if !strings.Contains(output, marker) {
+			t.Errorf("Expected %q in emoji output:\n%s", marker, output)
+		}
+	}
+
+	noEmoji := true
+	SetSessionConfigValues(&SessionConfig{NoEmoji: &noEmoji})
+	output = render()
+	if hasNonASCII(output) {
+		t.Errorf("Expected plain ASCII output with no_emoji set:\n%s", output)
+	}
+	for _, marker := range []string{"[file] file1.go", "[dir] repo1", "[ok] https://example.com/a.git -> a", "[error] https://example.com/b.git", "[changed]"} {
+		if !strings.Contains(output, marker) {
+			t.Errorf("Expected %q in plain output:\n%s", marker, output)
+		}
+	}
+
+	// The --no-emoji default applies until the session overrides it
+	ClearSessionConfig()
+	defaultNoEmoji = true
+	if hasNonASCII(render()) {
+		t.Error("Expected plain ASCII output with --no-emoji")
+	}
+	useEmoji := false
+	SetSessionConfigValues(&SessionConfig{NoEmoji: &useEmoji})
+	if !strings.Contains(render(), "📁 repo1") {
+		t.Error("Expected session setting to override --no-emoji")
+	}
+}
+
 func TestHandleSearchFilesEnhanced(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -276,3 +438,425 @@ func TestHandleSearchFilesEnhanced(t *testing.T) {
 		})
 	}
 }
+
+func TestReadOnlyMode(t *testing.T) {
+	listTools := func(t *testing.T) map[string]bool {
+		t.Helper()
+		ctx := context.Background()
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := CreateMCPServer().Connect(ctx, serverTransport, nil); err != nil {
+			t.Fatalf("Failed to connect server: %v", err)
+		}
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect client: %v", err)
+		}
+		defer session.Close()
+
+		result, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		names := make(map[string]bool)
+		for _, tool := range result.Tools {
+			names[tool.Name] = true
+		}
+		return names
+	}
+
+	mutating := []string{"clone_repository", "remove_repository", "rename_repository", "pull_repository", "fetch_repository", "preview_pull", "switch_branch",
+		"add_memo", "update_memo", "delete_memo", "delete_all_memos"}
+	reading := []string{"get_repository_info", "list_branches", "get_file_content", "search_files", "list_commits", "batch",
+		"get_memo", "list_memos", "session"}
+
+	t.Run("default registers mutating tools", func(t *testing.T) {
+		tools := listTools(t)
+		for _, name := range append(mutating, reading...) {
+			if !tools[name] {
+				t.Errorf("Expected %s to be registered", name)
+			}
+		}
+	})
+
+	t.Run("read-only skips mutating tools", func(t *testing.T) {
+		readOnlyMode = true
+		defer func() { readOnlyMode = false }()
+
+		tools := listTools(t)
+		for _, name := range mutating {
+			if tools[name] {
+				t.Errorf("Expected %s not to be registered in read-only mode", name)
+			}
+		}
+		for _, name := range reading {
+			if !tools[name] {
+				t.Errorf("Expected %s to be registered in read-only mode", name)
+			}
+		}
+
+		result, _, err := handleBatch(context.Background(), nil, BatchParams{Operation: "pull"})
+		if err != nil {
+			t.Fatalf("handleBatch returned error: %v", err)
+		}
+		if !result.IsError || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "read-only") {
+			t.Errorf("Expected batch pull to be refused, got %v", result.Content)
+		}
+	})
+
+	t.Run("read-only keeps session configuration in memory", func(t *testing.T) {
+		readOnlyMode = true
+		defer func() { readOnlyMode = false }()
+		ClearSessionConfig()
+		defer ClearSessionConfig()
+
+		workspaceDir := t.TempDir()
+		if err := InitializeWorkspace(workspaceDir); err != nil {
+			t.Fatalf("Failed to initialize workspace: %v", err)
+		}
+
+		result, _, err := handleSession(context.Background(), nil, SessionParams{Action: "set", DefaultRepository: "read-only-repo"})
+		if err != nil || result.IsError {
+			t.Fatalf("handleSession failed: %v %v", err, result.Content)
+		}
+		if got := GetSessionConfig().GetRepository(""); got != "read-only-repo" {
+			t.Errorf("Expected session value to apply, got %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(workspaceDir, "session_config.json")); !os.IsNotExist(err) {
+			t.Errorf("Expected session_config.json not to be written in read-only mode, got %v", err)
+		}
+	})
+}
+
+func TestHandleSearchFilesGrouped(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("widget_name_only.txt", "nothing relevant here\n")
+	repo.WriteFile("notes.txt", "the widget is configured here\n")
+	repo.WriteFile("widget.txt", "widget settings\n")
+	repo.AddCommit("Add grouped search fixtures")
+
+	result, _, err := handleSearchFiles(context.Background(), nil, SearchFilesParams{
+		Repository:      repo.Path,
+		Keywords:        []string{"widget"},
+		IncludeFilename: true,
+		OutputFormat:    "grouped",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+
+	sections := map[string]string{}
+	var current string
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Filename + content matches"):
+			current = "both"
+		case strings.HasPrefix(line, "Filename matches"):
+			current = "filename"
+		case strings.HasPrefix(line, "Content matches"):
+			current = "content"
+		case current != "" && strings.Contains(line, ".txt") && !strings.Contains(line, "└─"):
+			sections[current] += strings.Fields(line)[len(strings.Fields(line))-1] + " "
+		}
+	}
+
+	expected := map[string]string{
+		"both":     "widget.txt ",
+		"filename": "widget_name_only.txt ",
+		"content":  "notes.txt ",
+	}
+	for group, files := range expected {
+		if sections[group] != files {
+			t.Errorf("Group %s: expected %q, got %q in:\n%s", group, files, sections[group], text)
+		}
+	}
+	if strings.Index(text, "Filename + content matches") > strings.Index(text, "Filename matches") ||
+		strings.Index(text, "Filename matches") > strings.Index(text, "Content matches") {
+		t.Errorf("Unexpected section order:\n%s", text)
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		result, _, _ := handleSearchFiles(context.Background(), nil, SearchFilesParams{
+			Repository:   repo.Path,
+			Keywords:     []string{"widget"},
+			OutputFormat: "xml",
+		})
+		if !result.IsError {
+			t.Error("Expected error for unknown output_format")
+		}
+	})
+}
+
+func TestHandleSearchFilesFilenameAndContentSections(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("widget.txt", "intro\nwidget settings\nmore widget notes\n")
+	repo.WriteFile("widget_name_only.txt", "nothing relevant here\n")
+	repo.AddCommit("Add filename and content fixtures")
+
+	search := func(t *testing.T, maxMatches int) string {
+		t.Helper()
+		result, _, err := handleSearchFiles(context.Background(), nil, SearchFilesParams{
+			Repository:        repo.Path,
+			Keywords:          []string{"widget"},
+			IncludeFilename:   true,
+			MaxMatchesPerFile: maxMatches,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := search(t, 0)
+	want := "widget.txt [filename + content match]\n" +
+		"   In filename:\n" +
+		"   └─ widget.txt\n" +
+		"   In content:\n" +
+		"   └─ Line 2: widget settings\n" +
+		"   └─ Line 3: more widget notes\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected labeled filename and content sections:\n%s\nin:\n%s", want, text)
+	}
+	if !strings.Contains(text, "widget_name_only.txt [filename match]\n   └─ Filename: widget_name_only.txt\n") {
+		t.Errorf("Expected an unsectioned filename-only match:\n%s", text)
+	}
+
+	t.Run("filename match does not count toward the per-file cap", func(t *testing.T) {
+		text := search(t, 1)
+		if !strings.Contains(text, "   └─ widget.txt\n   In content:\n   └─ Line 2: widget settings\n   ... and 1 more matches\n") {
+			t.Errorf("Expected the filename plus one content line:\n%s", text)
+		}
+	})
+}
+
+func TestHandleSearchFilesMaxMatchesPerFile(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("many.txt", strings.Repeat("needle here\n", 500))
+	repo.AddCommit("Add file with many matches")
+
+	search := func(maxMatches int) string {
+		t.Helper()
+		result, _, err := handleSearchFiles(context.Background(), nil, SearchFilesParams{
+			Repository:        repo.Path,
+			Keywords:          []string{"needle"},
+			MaxMatchesPerFile: maxMatches,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := search(0)
+	if got := strings.Count(text, "└─ Line"); got != DefaultMaxMatchesPerFile {
+		t.Errorf("Expected %d match lines by default, got %d", DefaultMaxMatchesPerFile, got)
+	}
+	if !strings.Contains(text, "... and 490 more matches") {
+		t.Errorf("Expected truncation summary in:\n%s", text)
+	}
+	if !strings.Contains(text, "Line 1: needle here") || strings.Contains(text, "Line 11:") {
+		t.Errorf("Expected the first matches in line order:\n%s", text)
+	}
+
+	text = search(3)
+	if got := strings.Count(text, "└─ Line"); got != 3 || !strings.Contains(text, "... and 497 more matches") {
+		t.Errorf("Expected 3 match lines and 497 omitted, got %d in:\n%s", got, text)
+	}
+
+	text = search(-1)
+	if got := strings.Count(text, "└─ Line"); got != 500 || strings.Contains(text, "more matches") {
+		t.Errorf("Expected all 500 matches with the cap disabled, got %d", got)
+	}
+}
+
+func TestHandleSearchFilesContextLines(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("pair.txt", "one\nneedle a\nneedle b\nfour\n")
+	repo.AddCommit("Add adjacent matches")
+
+	search := func(keyword string) string {
+		t.Helper()
+		result, _, err := handleSearchFiles(context.Background(), nil, SearchFilesParams{
+			Repository:   repo.Path,
+			Keywords:     []string{keyword},
+			ContextLines: 1,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := search("return a + b")
+	want := "         3 | func Add(a, b int) int {\n" +
+		"   └─ Line 4: return a + b\n" +
+		"         5 | }\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected the line before and after around the match:\n%s\ngot:\n%s", want, text)
+	}
+
+	// Adjacent matches share their context, which is written only once
+	text = search("needle")
+	want = "         1 | one\n" +
+		"   └─ Line 2: needle a\n" +
+		"   └─ Line 3: needle b\n" +
+		"         4 | four\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected shared context written once:\n%s\ngot:\n%s", want, text)
+	}
+}
+
+func TestHandleSearchFilesFunctionContext(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("calc/calc.go", "package calc\n\nfunc Alpha(x int) int {\n\ty := x * 2\n\treturn y + 1 // needle\n}\n\nfunc Beta() {\n\t// needle\n\t// needle again\n}\n")
+	repo.AddCommit("Add calc package")
+
+	search := func(params SearchFilesParams) string {
+		t.Helper()
+		params.Repository = repo.Path
+		params.Keywords = []string{"needle"}
+		result, _, err := handleSearchFiles(context.Background(), nil, params)
+		if err != nil || result.IsError {
+			t.Fatalf("handleSearchFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := search(SearchFilesParams{FunctionContext: true})
+	want := "         3 = func Alpha(x int) int {\n" +
+		"   └─ Line 5: return y + 1 // needle\n" +
+		"         8 = func Beta() {\n" +
+		"   └─ Line 9: // needle\n" +
+		"   └─ Line 10: // needle again\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected each match under its func signature:\n%s\ngot:\n%s", want, text)
+	}
+
+	// Context before the match is shown after the function line
+	text = search(SearchFilesParams{FunctionContext: true, ContextLines: 1, IncludePatterns: []string{"calc/*"}})
+	want = "         3 = func Alpha(x int) int {\n" +
+		"         4 | \ty := x * 2\n" +
+		"   └─ Line 5: return y + 1 // needle\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected the function line before the context:\n%s\ngot:\n%s", want, text)
+	}
+
+	if text := search(SearchFilesParams{}); strings.Contains(text, "func Alpha") {
+		t.Errorf("Expected no function lines without function_context:\n%s", text)
+	}
+
+	results, err := SearchFilesWithFunctionContext(repo.Path, []string{"return y"}, "and", false, 0, nil, nil, nil, 0)
+	if err != nil || len(results) != 1 || len(results[0].Matches) != 1 {
+		t.Fatalf("SearchFilesWithFunctionContext failed: %v %+v", err, results)
+	}
+	if match := results[0].Matches[0]; match.Function != "func Alpha(x int) int {" || match.FunctionLine != 3 {
+		t.Errorf("Expected the match inside Alpha, got %+v", match)
+	}
+}
+
+func TestSessionConfigTools(t *testing.T) {
+	ClearSessionConfig()
+	defer ClearSessionConfig()
+
+	textOf := func(result *mcp.CallToolResult, _ any, err error) string {
+		t.Helper()
+		if err != nil || result.IsError {
+			t.Fatalf("Session tool failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	text := textOf(handleGetSessionConfig(context.Background(), nil, GetSessionConfigParams{}))
+	if !strings.Contains(text, "No session configuration set") {
+		t.Errorf("Expected empty configuration, got:\n%s", text)
+	}
+
+	textOf(handleSetSessionConfig(context.Background(), nil, SetSessionConfigParams{
+		DefaultRepository:      "test-repo",
+		DefaultIncludePatterns: []string{"*.go"},
+		DefaultListFilesLimit:  5,
+		DefaultCommitLimit:     3,
+	}))
+
+	sc := GetSessionConfig()
+	if got := sc.GetRepository(""); got != "test-repo" {
+		t.Errorf("Expected default repository 'test-repo', got %q", got)
+	}
+	if got := sc.GetListFilesLimit(0); got != 5 {
+		t.Errorf("Expected default list files limit 5, got %d", got)
+	}
+	if got := sc.GetCommitLimit(0); got != 3 {
+		t.Errorf("Expected default commit limit 3, got %d", got)
+	}
+
+	text = textOf(handleGetSessionConfig(context.Background(), nil, GetSessionConfigParams{}))
+	expected := "default_commit_limit: 3\ndefault_include_patterns: [*.go]\ndefault_list_files_limit: 5\ndefault_repository: test-repo\n"
+	if !strings.HasSuffix(text, expected) {
+		t.Errorf("Expected sorted configuration ending in:\n%s\ngot:\n%s", expected, text)
+	}
+
+	// A later set only overrides the fields it provides
+	textOf(handleSetSessionConfig(context.Background(), nil, SetSessionConfigParams{DefaultCommitLimit: 7}))
+	if got := sc.GetRepository(""); got != "test-repo" {
+		t.Errorf("Expected default repository to be kept, got %q", got)
+	}
+	if got := sc.GetCommitLimit(0); got != 7 {
+		t.Errorf("Expected default commit limit 7, got %d", got)
+	}
+
+	textOf(handleClearSessionConfig(context.Background(), nil, ClearSessionConfigParams{}))
+	if !sc.IsEmpty() {
+		t.Errorf("Expected configuration to be empty after clear, got %v", sc.ToMap())
+	}
+
+	// The unified session tool still works
+	textOf(handleSession(context.Background(), nil, SessionParams{Action: "set", DefaultRepository: "other-repo"}))
+	if got := sc.GetRepository(""); got != "other-repo" {
+		t.Errorf("Expected session action=set to apply, got %q", got)
+	}
+}
+
+func TestSessionConfigPersistence(t *testing.T) {
+	ClearSessionConfig()
+	defer ClearSessionConfig()
+
+	tmpDir, err := os.MkdirTemp("", "session-persist-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A workspace without a saved file loads as an empty configuration
+	if err := LoadSessionConfig(tmpDir); err != nil {
+		t.Fatalf("Loading a missing session config should not fail: %v", err)
+	}
+	if !GetSessionConfig().IsEmpty() {
+		t.Errorf("Expected empty configuration, got %v", GetSessionConfig().ToMap())
+	}
+
+	// Save a default repository, then drop it from memory
+	SetSessionConfigValues(&SessionConfig{DefaultRepository: "persist-repo", DefaultCommitLimit: 4})
+	if err := SaveSessionConfig(tmpDir); err != nil {
+		t.Fatalf("Failed to save session config: %v", err)
+	}
+	ClearSessionConfig()
+
+	// Reload (as on server startup) and confirm the values survived
+	if err := LoadSessionConfig(tmpDir); err != nil {
+		t.Fatalf("Failed to reload session config: %v", err)
+	}
+	sc := GetSessionConfig()
+	if got := sc.GetRepository(""); got != "persist-repo" {
+		t.Errorf("Expected default repository 'persist-repo', got %q", got)
+	}
+	if got := sc.GetCommitLimit(0); got != 4 {
+		t.Errorf("Expected default commit limit 4, got %d", got)
+	}
+
+	// Verify file exists
+	filePath := filepath.Join(tmpDir, "session_config.json")
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		t.Error("Session config file should exist")
+	}
+}
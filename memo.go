@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -213,8 +214,15 @@ func (ms *MemoStore) DeleteAllMemos() error {
 	return nil
 }
 
-// SearchMemos searches for memos matching the criteria
+// SearchMemos searches for memos matching the criteria, most recently updated first
 func (ms *MemoStore) SearchMemos(query, repository string, tags []string, limit int) []*Memo {
+	memos, _ := ms.SearchMemosPage(query, repository, tags, 0, limit)
+	return memos
+}
+
+// SearchMemosPage returns the page of matching memos starting at offset, sorted
+// by UpdatedAt descending, along with the total number of matches
+func (ms *MemoStore) SearchMemosPage(query, repository string, tags []string, offset, limit int) ([]*Memo, int) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
 
@@ -263,14 +271,33 @@ func (ms *MemoStore) SearchMemos(query, repository string, tags []string, limit
 		if matches {
 			results = append(results, memo)
 		}
+	}
 
-		// Apply limit
-		if limit > 0 && len(results) >= limit {
-			break
+	// Sort before slicing so the limit keeps the newest memos and pages are
+	// stable across calls
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
 		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	total := len(results)
+	if offset >= total {
+		return []*Memo{}, total
+	}
+	if offset > 0 {
+		results = results[offset:]
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
 	}
 
-	return results
+	return results, total
 }
 
 // GetMemosByRepository returns all memos for a specific repository
@@ -278,6 +305,58 @@ func (ms *MemoStore) GetMemosByRepository(repository string, limit int) []*Memo
 	return ms.SearchMemos("", repository, nil, limit)
 }
 
+// ExportMemos renders the memos of a repository (all memos if repository is
+// empty) as a Markdown document, most recently updated first
+func (ms *MemoStore) ExportMemos(repository string) (string, error) {
+	memos := ms.SearchMemos("", repository, nil, 0)
+
+	var doc strings.Builder
+	if repository != "" {
+		doc.WriteString(fmt.Sprintf("# Memos for %s\n\n", repository))
+	} else {
+		doc.WriteString("# Memos\n\n")
+	}
+	doc.WriteString(fmt.Sprintf("Generated at %s (%d memos)\n", time.Now().Format("2006-01-02 15:04:05"), len(memos)))
+
+	for _, memo := range memos {
+		doc.WriteString(fmt.Sprintf("\n## %s\n\n", memo.Title))
+		if memo.Repository != "" && repository == "" {
+			doc.WriteString(fmt.Sprintf("- Repository: %s\n", memo.Repository))
+		}
+		if len(memo.Tags) > 0 {
+			doc.WriteString(fmt.Sprintf("- Tags: %s\n", strings.Join(memo.Tags, ", ")))
+		}
+		doc.WriteString(fmt.Sprintf("- Created: %s\n", memo.CreatedAt.Format("2006-01-02 15:04:05")))
+		doc.WriteString(fmt.Sprintf("- Updated: %s\n", memo.UpdatedAt.Format("2006-01-02 15:04:05")))
+		doc.WriteString(fmt.Sprintf("- ID: %s\n\n", memo.ID))
+		doc.WriteString(strings.TrimRight(memo.Content, "\n") + "\n")
+	}
+
+	return doc.String(), nil
+}
+
+// GetAllTags counts how many memos carry each tag. Tags are merged
+// case-insensitively and reported in lower case.
+func (ms *MemoStore) GetAllTags() map[string]int {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, memo := range ms.memos {
+		seen := make(map[string]bool)
+		for _, tag := range memo.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			counts[tag]++
+		}
+	}
+
+	return counts
+}
+
 // ListAllMemos returns all memos
 func (ms *MemoStore) ListAllMemos() []*Memo {
 	ms.mu.RLock()
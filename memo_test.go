@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func setupTestMemoStore(t *testing.T) (*MemoStore, string) {
@@ -293,6 +299,181 @@ func TestGetMemosByRepository(t *testing.T) {
 	}
 }
 
+func TestSearchMemosOrderedByRecency(t *testing.T) {
+	store, tmpDir := setupTestMemoStore(t)
+	defer cleanupTestMemoStore(tmpDir)
+
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		title            string
+		created, updated time.Duration // offsets from base
+	}{
+		{"Oldest", 0, 0},
+		{"Newest", 1 * time.Hour, 10 * time.Hour},
+		{"Middle", 2 * time.Hour, 5 * time.Hour},
+		{"Tie created later", 4 * time.Hour, 8 * time.Hour},
+		{"Tie created earlier", 3 * time.Hour, 8 * time.Hour},
+	}
+	for _, f := range fixtures {
+		memo, err := store.AddMemo("", f.title, "content", nil)
+		if err != nil {
+			t.Fatalf("Failed to add memo: %v", err)
+		}
+		memo.CreatedAt = base.Add(f.created)
+		memo.UpdatedAt = base.Add(f.updated)
+	}
+
+	expected := []string{"Newest", "Tie created later", "Tie created earlier", "Middle", "Oldest"}
+
+	// Repeat to catch map iteration order leaking into the results
+	for run := 0; run < 5; run++ {
+		var titles []string
+		for _, memo := range store.SearchMemos("", "", nil, 0) {
+			titles = append(titles, memo.Title)
+		}
+		if strings.Join(titles, ",") != strings.Join(expected, ",") {
+			t.Fatalf("Expected order %v, got %v", expected, titles)
+		}
+	}
+
+	// The limit keeps the newest memos rather than an arbitrary subset
+	limited := store.SearchMemos("", "", nil, 2)
+	if len(limited) != 2 || limited[0].Title != "Newest" || limited[1].Title != "Tie created later" {
+		t.Errorf("Expected the two newest memos, got %v", limited)
+	}
+}
+
+func TestSearchMemosPagination(t *testing.T) {
+	store, tmpDir := setupTestMemoStore(t)
+	defer cleanupTestMemoStore(tmpDir)
+
+	// Distinct timestamps make the recency order predictable
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 25; i++ {
+		memo, err := store.AddMemo("paged-repo", fmt.Sprintf("Memo %02d", i), "content", nil)
+		if err != nil {
+			t.Fatalf("Failed to add memo: %v", err)
+		}
+		memo.UpdatedAt = base.Add(time.Duration(i) * time.Minute)
+	}
+
+	seen := make(map[string]bool)
+	for page, expected := range []int{10, 10, 5} {
+		memos, total := store.SearchMemosPage("", "", nil, page*10, 10)
+		if total != 25 {
+			t.Errorf("Page %d: expected total 25, got %d", page, total)
+		}
+		if len(memos) != expected {
+			t.Fatalf("Page %d: expected %d memos, got %d", page, expected, len(memos))
+		}
+		// Newest first: page 0 starts with Memo 24
+		if want := fmt.Sprintf("Memo %02d", 24-page*10); memos[0].Title != want {
+			t.Errorf("Page %d: expected first memo %q, got %q", page, want, memos[0].Title)
+		}
+		for _, memo := range memos {
+			if seen[memo.ID] {
+				t.Errorf("Memo %q returned on more than one page", memo.Title)
+			}
+			seen[memo.ID] = true
+		}
+	}
+
+	if memos, total := store.SearchMemosPage("", "", nil, 30, 10); len(memos) != 0 || total != 25 {
+		t.Errorf("Expected empty page past the end, got %d memos (total %d)", len(memos), total)
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleListMemos(context.Background(), nil, ListMemosParams{Limit: 10, Offset: 10})
+		if err != nil || result.IsError {
+			t.Fatalf("handleListMemos failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Found 25 memo(s), showing 11-20") || !strings.Contains(text, "use offset=20") {
+			t.Errorf("Expected page summary and next offset, got:\n%s", text)
+		}
+
+		result, _, _ = handleListMemos(context.Background(), nil, ListMemosParams{Limit: 10, Offset: 20})
+		text = result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "showing 21-25") || strings.Contains(text, "More memos available") {
+			t.Errorf("Expected last page without a next offset, got:\n%s", text)
+		}
+	})
+}
+
+func TestExportMemos(t *testing.T) {
+	store, tmpDir := setupTestMemoStore(t)
+	defer cleanupTestMemoStore(tmpDir)
+
+	older, _ := store.AddMemo("repo-a", "Architecture Notes", "Layers: MCP, workspace, git.", []string{"design"})
+	newer, _ := store.AddMemo("repo-a", "Release Checklist", "1. Tag\n2. Publish", nil)
+	store.AddMemo("repo-b", "Other Repo Memo", "Not exported for repo-a", nil)
+	older.UpdatedAt = time.Now().Add(-time.Hour)
+
+	markdown, err := store.ExportMemos("repo-a")
+	if err != nil {
+		t.Fatalf("ExportMemos failed: %v", err)
+	}
+	for _, want := range []string{
+		"# Memos for repo-a",
+		"Generated at ",
+		"## Architecture Notes", "Layers: MCP, workspace, git.", "- Tags: design",
+		"## Release Checklist", "1. Tag\n2. Publish",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Expected export to contain %q, got:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "Other Repo Memo") {
+		t.Errorf("Expected only repo-a memos, got:\n%s", markdown)
+	}
+	if strings.Index(markdown, "## "+newer.Title) > strings.Index(markdown, "## "+older.Title) {
+		t.Errorf("Expected most recently updated memo first, got:\n%s", markdown)
+	}
+
+	t.Run("all repositories", func(t *testing.T) {
+		result, _, err := handleExportMemos(context.Background(), nil, ExportMemosParams{})
+		if err != nil || result.IsError {
+			t.Fatalf("handleExportMemos failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "(3 memos)") || !strings.Contains(text, "## Other Repo Memo") || !strings.Contains(text, "- Repository: repo-b") {
+			t.Errorf("Expected all memos with their repositories, got:\n%s", text)
+		}
+	})
+}
+
+func TestGetAllTags(t *testing.T) {
+	store, tmpDir := setupTestMemoStore(t)
+	defer cleanupTestMemoStore(tmpDir)
+
+	store.AddMemo("", "Memo 1", "", []string{"go", "api"})
+	store.AddMemo("", "Memo 2", "", []string{"Go", "design"})
+	store.AddMemo("", "Memo 3", "", []string{"GO", "api", "go"}) // duplicate counts once
+	store.AddMemo("", "Memo 4", "", nil)
+
+	tags := store.GetAllTags()
+	expected := map[string]int{"go": 3, "api": 2, "design": 1}
+	if len(tags) != len(expected) {
+		t.Errorf("Expected %d tags, got %v", len(expected), tags)
+	}
+	for tag, count := range expected {
+		if tags[tag] != count {
+			t.Errorf("Expected tag %q on %d memos, got %d", tag, count, tags[tag])
+		}
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleListMemoTags(context.Background(), nil, nil)
+		if err != nil || result.IsError {
+			t.Fatalf("handleListMemoTags failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.HasSuffix(text, "    3  go\n    2  api\n    1  design\n") {
+			t.Errorf("Expected tags sorted by count, got:\n%s", text)
+		}
+	})
+}
+
 func TestListAllMemos(t *testing.T) {
 	store, tmpDir := setupTestMemoStore(t)
 	defer cleanupTestMemoStore(tmpDir)
@@ -360,6 +541,57 @@ func TestMemoPersistence(t *testing.T) {
 	}
 }
 
+func TestLoadMemosWithoutRepository(t *testing.T) {
+	// Reset global store
+	globalMemoStore = nil
+	defer func() { globalMemoStore = nil }()
+
+	tmpDir, err := os.MkdirTemp("", "memo-legacy-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// memos.json written before memos carried a repository
+	legacy := `[{"id":"legacy-1","title":"Old Memo","content":"From an older version","tags":["old"],` +
+		`"created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-02T00:00:00Z"}]`
+	if err := os.WriteFile(filepath.Join(tmpDir, "memos.json"), []byte(legacy), 0644); err != nil {
+		t.Fatalf("Failed to write legacy memos file: %v", err)
+	}
+
+	if err := InitializeMemoStore(tmpDir); err != nil {
+		t.Fatalf("Failed to load legacy memos: %v", err)
+	}
+	store := GetMemoStore()
+
+	memo, err := store.GetMemo("legacy-1")
+	if err != nil {
+		t.Fatalf("Failed to get legacy memo: %v", err)
+	}
+	if memo.Repository != "" {
+		t.Errorf("Expected empty repository, got '%s'", memo.Repository)
+	}
+	if memo.Title != "Old Memo" {
+		t.Errorf("Expected title 'Old Memo', got '%s'", memo.Title)
+	}
+
+	// Legacy memos are listed without a filter but excluded by a repository filter
+	if results := store.SearchMemos("", "", nil, 0); len(results) != 1 {
+		t.Errorf("Expected 1 memo without filter, got %d", len(results))
+	}
+	if results := store.GetMemosByRepository("some-repo", 0); len(results) != 0 {
+		t.Errorf("Expected no memos for 'some-repo', got %d", len(results))
+	}
+
+	// Assigning a repository later is stored and persisted
+	if _, err := store.UpdateMemo("legacy-1", "some-repo", "", "", nil); err != nil {
+		t.Fatalf("Failed to update legacy memo: %v", err)
+	}
+	if results := store.GetMemosByRepository("some-repo", 0); len(results) != 1 {
+		t.Errorf("Expected 1 memo for 'some-repo' after update, got %d", len(results))
+	}
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	store, tmpDir := setupTestMemoStore(t)
 	defer cleanupTestMemoStore(tmpDir)
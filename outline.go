@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+)
+
+// OutlineSymbol represents a top-level declaration and the lines it spans
+type OutlineSymbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"` // "func", "method", "type", "var" or "const"
+	Receiver  string `json:"receiver,omitempty"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// outlineLanguageGlobs maps each supported outline language to its file pattern
+var outlineLanguageGlobs = map[string]string{
+	"go": "*.go",
+}
+
+// detectLanguage infers the outline language from a file extension
+func detectLanguage(filePath string) string {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".go":
+		return "go"
+	default:
+		return ""
+	}
+}
+
+// ExtractOutline returns the top-level symbols declared in content
+func ExtractOutline(filePath string, content []byte, language string) ([]OutlineSymbol, error) {
+	if language == "" {
+		language = detectLanguage(filePath)
+	}
+
+	switch language {
+	case "go":
+		return extractGoOutline(filePath, content)
+	default:
+		return nil, fmt.Errorf("unsupported outline language: %q", language)
+	}
+}
+
+func extractGoOutline(filePath string, content []byte) ([]OutlineSymbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+
+	lineRange := func(node ast.Node) (int, int) {
+		return fset.Position(node.Pos()).Line, fset.Position(node.End()).Line
+	}
+
+	var symbols []OutlineSymbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			start, end := lineRange(d)
+			symbol := OutlineSymbol{Name: d.Name.Name, Kind: "func", StartLine: start, EndLine: end}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				symbol.Kind = "method"
+				symbol.Receiver = receiverTypeName(d.Recv.List[0].Type)
+			}
+			symbols = append(symbols, symbol)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					start, end := lineRange(s)
+					symbols = append(symbols, OutlineSymbol{Name: s.Name.Name, Kind: "type", StartLine: start, EndLine: end})
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					start, end := lineRange(s)
+					for _, name := range s.Names {
+						symbols = append(symbols, OutlineSymbol{Name: name.Name, Kind: kind, StartLine: start, EndLine: end})
+					}
+				}
+			}
+		}
+	}
+
+	return symbols, nil
+}
+
+// receiverTypeName strips pointers and type parameters from a method receiver
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+// matchesSymbol reports whether name refers to the symbol, either by its bare
+// name or, for methods, as "Receiver.Method"
+func (s OutlineSymbol) matchesSymbol(name string) bool {
+	if s.Name == name {
+		return true
+	}
+	return s.Receiver != "" && s.Receiver+"."+s.Name == name
+}
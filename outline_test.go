@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const outlineTestSource = `package sample
+
+type Server struct{}
+
+func (s *Server) Start() {
+	connect("db")
+}
+
+func helper() {
+	connect("cache")
+}
+
+func other() {
+	// no calls here
+}
+`
+
+func TestExtractOutline(t *testing.T) {
+	symbols, err := ExtractOutline("sample.go", []byte(outlineTestSource), "")
+	if err != nil {
+		t.Fatalf("ExtractOutline failed: %v", err)
+	}
+
+	expected := map[string][2]int{"Server": {3, 3}, "Start": {5, 7}, "helper": {9, 11}, "other": {13, 15}}
+	if len(symbols) != len(expected) {
+		t.Fatalf("Expected %d symbols, got %+v", len(expected), symbols)
+	}
+	for _, symbol := range symbols {
+		want, ok := expected[symbol.Name]
+		if !ok {
+			t.Errorf("Unexpected symbol %s", symbol.Name)
+			continue
+		}
+		if symbol.StartLine != want[0] || symbol.EndLine != want[1] {
+			t.Errorf("%s: expected lines %d-%d, got %d-%d", symbol.Name, want[0], want[1], symbol.StartLine, symbol.EndLine)
+		}
+	}
+	if symbols[1].Kind != "method" || symbols[1].Receiver != "Server" {
+		t.Errorf("Expected Start to be a method on Server, got %+v", symbols[1])
+	}
+
+	if _, err := ExtractOutline("sample.py", []byte("def f(): pass"), ""); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestSearchInSymbol(t *testing.T) {
+	repoName, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repoPath := GetWorkspaceManager().GetRepositoryPath(repoName)
+	if err := os.WriteFile(filepath.Join(repoPath, "sample.go"), []byte(outlineTestSource), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	for _, args := range [][]string{{"add", "sample.go"}, {"commit", "-m", "Add sample"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoPath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	matches, err := SearchInSymbol(repoName, "helper", "connect", "go")
+	if err != nil {
+		t.Fatalf("SearchInSymbol failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].LineNumber != 10 || matches[0].Path != "sample.go" {
+		t.Errorf("Expected single match at sample.go:10, got %+v", matches)
+	}
+
+	matches, err = SearchInSymbol(repoName, "Server.Start", "CONNECT", "")
+	if err != nil {
+		t.Fatalf("SearchInSymbol failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].LineNumber != 6 {
+		t.Errorf("Expected single match at line 6, got %+v", matches)
+	}
+
+	matches, err = SearchInSymbol(repoName, "other", "connect", "go")
+	if err != nil {
+		t.Fatalf("SearchInSymbol failed: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Expected no matches inside other, got %+v", matches)
+	}
+
+	if _, err := SearchInSymbol(repoName, "helper", "connect", "cobol"); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+
+	result, _, err := handleSearchInSymbol(context.Background(), nil, SearchInSymbolParams{
+		Repository: repoName,
+		Symbol:     "helper",
+		Keyword:    "connect",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("handleSearchInSymbol failed: %v %v", err, result.Content)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, `sample.go:10: connect("cache")`) || strings.Contains(text, `"db"`) {
+		t.Errorf("Unexpected output: %s", text)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathEntry is one child of a directory in a PathContext level
+type PathEntry struct {
+	Name   string `json:"name"`
+	IsDir  bool   `json:"is_dir"`
+	OnPath bool   `json:"on_path"` // the entry leads to (or is) the requested path
+}
+
+// PathLevel lists the immediate children of one ancestor directory
+type PathLevel struct {
+	Dir     string      `json:"dir"` // "" for the repository root, otherwise e.g. "src/"
+	Entries []PathEntry `json:"entries"`
+}
+
+// PathContext holds the breadcrumb of a path: every ancestor directory from
+// the repository root down to the path's parent, with its siblings at each level
+type PathContext struct {
+	Path   string      `json:"path"`
+	Levels []PathLevel `json:"levels"`
+}
+
+// GetPathContext returns the ancestor directories of filePath, from the
+// repository root down, with the immediate children of each. The .git
+// directory is never listed.
+func GetPathContext(repoPath, filePath string) (*PathContext, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	filePath = path.Clean(strings.ReplaceAll(filePath, "\\", "/"))
+	if filePath == "." || filePath == ".." || strings.HasPrefix(filePath, "../") || path.IsAbs(filePath) {
+		return nil, fmt.Errorf("invalid path: %s", filePath)
+	}
+
+	// os.ReadDir follows symlinks, so every ancestor must resolve inside the
+	// repository before anything under it is looked at
+	parts := strings.Split(filePath, "/")
+	for i := 1; i < len(parts); i++ {
+		if err := checkRepositoryDir(repoPath, filepath.FromSlash(strings.Join(parts[:i], "/"))); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(repoPath, filepath.FromSlash(filePath))); err != nil {
+		return nil, fmt.Errorf("path not found: %s", filePath)
+	}
+
+	result := &PathContext{Path: filePath}
+	for i := range parts {
+		dir := strings.Join(parts[:i], "/")
+		entries, err := os.ReadDir(filepath.Join(repoPath, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory: %v", err)
+		}
+
+		level := PathLevel{}
+		if dir != "" {
+			level.Dir = dir + "/"
+		}
+		for _, entry := range entries {
+			if entry.Name() == ".git" {
+				continue
+			}
+			level.Entries = append(level.Entries, PathEntry{
+				Name:   entry.Name(),
+				IsDir:  entry.IsDir(),
+				OnPath: entry.Name() == parts[i],
+			})
+		}
+		// Directories first, then files, alphabetically within each group
+		sort.SliceStable(level.Entries, func(a, b int) bool {
+			if level.Entries[a].IsDir != level.Entries[b].IsDir {
+				return level.Entries[a].IsDir
+			}
+			return level.Entries[a].Name < level.Entries[b].Name
+		})
+		result.Levels = append(result.Levels, level)
+	}
+
+	return result, nil
+}
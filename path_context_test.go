@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGetPathContext(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	pc, err := GetPathContext(repo.Path, "src/utils.go")
+	if err != nil {
+		t.Fatalf("GetPathContext failed: %v", err)
+	}
+
+	if len(pc.Levels) != 2 {
+		t.Fatalf("Expected root and src/ levels, got %+v", pc.Levels)
+	}
+
+	root := pc.Levels[0]
+	if root.Dir != "" {
+		t.Errorf("Expected root level first, got %q", root.Dir)
+	}
+	if len(root.Entries) == 0 || !root.Entries[0].IsDir {
+		t.Errorf("Expected directories listed first at the root, got %+v", root.Entries)
+	}
+	for _, entry := range root.Entries {
+		if entry.Name == ".git" {
+			t.Errorf(".git should not be listed")
+		}
+		if entry.OnPath != (entry.Name == "src") {
+			t.Errorf("Unexpected on_path=%v for %s", entry.OnPath, entry.Name)
+		}
+	}
+
+	src := pc.Levels[1]
+	if src.Dir != "src/" {
+		t.Errorf("Expected src/ level, got %q", src.Dir)
+	}
+	found := false
+	for _, entry := range src.Entries {
+		if entry.Name == "utils.go" {
+			found = true
+			if entry.IsDir || !entry.OnPath {
+				t.Errorf("Expected utils.go as the on-path file, got %+v", entry)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected utils.go among src/ entries, got %+v", src.Entries)
+	}
+
+	for _, bad := range []string{"", ".", "../outside", "/etc/passwd", "src/missing.go"} {
+		if _, err := GetPathContext(repo.Path, bad); err == nil {
+			t.Errorf("Expected error for path %q", bad)
+		}
+	}
+
+	t.Run("symlinked directory outside the repository", func(t *testing.T) {
+		outside := t.TempDir()
+		if err := os.WriteFile(filepath.Join(outside, "hostname"), []byte("host\n"), 0644); err != nil {
+			t.Fatalf("Failed to write outside file: %v", err)
+		}
+		if err := os.Symlink(outside, filepath.Join(repo.Path, "ext")); err != nil {
+			t.Skipf("Symlinks not supported: %v", err)
+		}
+		defer os.Remove(filepath.Join(repo.Path, "ext"))
+
+		if pc, err := GetPathContext(repo.Path, "ext/hostname"); err == nil {
+			t.Errorf("Expected the symlinked directory to be refused, got %+v", pc)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handlePathContext(context.Background(), nil, PathContextParams{Repository: repo.Path, FilePath: "src/utils.go"})
+		if err != nil || result.IsError {
+			t.Fatalf("handlePathContext failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"(repository root)", "→ 📁 src/", "📁 src/\n", "→ 📄 utils.go", "📄 README.md"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -85,7 +86,7 @@ func TestPerformanceWithLargeRepository(t *testing.T) {
 		start := time.Now()
 
 		// Test search performance
-		results, err := SearchFiles(repo.Path, []string{"database"}, "and", false, 0, nil, nil, 50)
+		results, err := SearchFiles(repo.Path, []string{"database"}, "and", false, 0, nil, nil, nil, 50)
 		if err != nil {
 			t.Fatalf("Failed to search files: %v", err)
 		}
@@ -270,7 +271,7 @@ func BenchmarkOperations(b *testing.B) {
 	b.Run("SearchFiles", func(b *testing.B) {
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			_, err := SearchFiles(repo.Path, []string{"database"}, "and", false, 0, nil, nil, 10)
+			_, err := SearchFiles(repo.Path, []string{"database"}, "and", false, 0, nil, nil, nil, 10)
 			if err != nil {
 				b.Fatalf("Benchmark failed: %v", err)
 			}
@@ -339,6 +340,109 @@ func BenchmarkMCPHandlers(b *testing.B) {
 	})
 }
 
+// BenchmarkSearchAndMode compares the two ways of confirming that files found
+// for the first keyword also contain the rest: reading every candidate file
+// (filterResultsByKeywords) versus intersecting `git grep -l` file sets
+// (filterResultsByAllKeywords)
+func BenchmarkSearchAndMode(b *testing.B) {
+	repo := CreateTestRepositoryWithContent(&testing.T{})
+
+	filler := strings.Repeat("lorem ipsum dolor sit amet consectetur adipiscing elit\n", 5000) // ~280KB
+	for i := 0; i < 40; i++ {
+		content := "database connection\n" + filler
+		if i%2 == 0 {
+			content += "redis cache\n"
+		}
+		repo.WriteFile(fmt.Sprintf("data/large_%02d.txt", i), content)
+	}
+	repo.AddCommit("Add large files")
+
+	output, err := exec.Command("git", "-C", repo.Path, "grep", "-n", "database").Output()
+	if err != nil {
+		b.Fatalf("git grep failed: %v", err)
+	}
+	candidates := parseGrepOutput(string(output), "content", 0)
+
+	b.Run("read file contents", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if got := filterResultsByKeywords(repo.Path, candidates, []string{"redis"}); len(got) != 21 {
+				b.Fatalf("Expected 21 files, got %d", len(got))
+			}
+		}
+	})
+
+	b.Run("git grep -l intersection", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if got := filterResultsByAllKeywords(repo.Path, candidates, []string{"redis"}); len(got) != 21 {
+				b.Fatalf("Expected 21 files, got %d", len(got))
+			}
+		}
+	})
+}
+
+func BenchmarkSearchEngines(b *testing.B) {
+	repo := CreateTestRepositoryWithContent(&testing.T{})
+
+	filler := strings.Repeat("lorem ipsum dolor sit amet consectetur adipiscing elit\n", 5000) // ~280KB
+	for i := 0; i < 40; i++ {
+		repo.WriteFile(fmt.Sprintf("data/large_%02d.txt", i), fmt.Sprintf("keyword%d\n", i%5)+filler)
+	}
+	repo.AddCommit("Add large files")
+
+	keywords := []string{"keyword0", "keyword1", "keyword2", "keyword3", "keyword4"}
+
+	b.Run("git grep", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if got, _ := searchInContentGitGrep(repo.Path, keywords, "or", 0, false, nil, nil); len(got) != 40 {
+				b.Fatalf("Expected 40 files, got %d", len(got))
+			}
+		}
+	})
+
+	b.Run("ripgrep", func(b *testing.B) {
+		if !ripgrepAvailable() {
+			b.Skip("rg not found on PATH")
+		}
+		for i := 0; i < b.N; i++ {
+			if got, _ := searchInContentRipgrep(repo.Path, keywords, "or", 0, nil, nil); len(got) != 40 {
+				b.Fatalf("Expected 40 files, got %d", len(got))
+			}
+		}
+	})
+}
+
+func BenchmarkGetMultipleFileContents(b *testing.B) {
+	repo := CreateTestRepositoryWithContent(&testing.T{})
+
+	filler := strings.Repeat("lorem ipsum dolor sit amet consectetur adipiscing elit\n", 2000) // ~110KB
+	var filePaths []string
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("data/file_%02d.txt", i)
+		repo.WriteFile(path, filler)
+		filePaths = append(filePaths, path)
+	}
+
+	for _, bench := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"parallel", maxConcurrentFileReads},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			original := maxConcurrentFileReads
+			maxConcurrentFileReads = bench.concurrency
+			defer func() { maxConcurrentFileReads = original }()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := GetMultipleFileContentsWithLineNumbers(repo.Path, filePaths, 1, 0, true); err != nil {
+					b.Fatalf("Benchmark failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
 // TestResourceLimits tests behavior under resource constraints
 func TestResourceLimits(t *testing.T) {
 	if testing.Short() {
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // TestGetReadmeFiles tests the README file discovery functionality
@@ -28,7 +34,7 @@ func TestGetReadmeFiles(t *testing.T) {
 	repo.AddCommit("Add test files for README discovery")
 
 	t.Run("Get README files non-recursive", func(t *testing.T) {
-		readmeFiles, err := GetReadmeFiles(repo.Path, false)
+		readmeFiles, err := GetReadmeFiles(repo.Path, false, nil, nil)
 		if err != nil {
 			t.Fatalf("GetReadmeFiles failed: %v", err)
 		}
@@ -60,7 +66,7 @@ func TestGetReadmeFiles(t *testing.T) {
 	})
 
 	t.Run("Get README files recursive", func(t *testing.T) {
-		readmeFiles, err := GetReadmeFiles(repo.Path, true)
+		readmeFiles, err := GetReadmeFiles(repo.Path, true, nil, nil)
 		if err != nil {
 			t.Fatalf("GetReadmeFiles failed: %v", err)
 		}
@@ -96,6 +102,53 @@ func TestGetReadmeFiles(t *testing.T) {
 	})
 }
 
+func TestIsReadmeName(t *testing.T) {
+	for _, name := range []string{"README", "README.md", "readme.txt", "README.rst", "README.adoc", "README.markdown", "ReadMe.MD", "README.ja.md"} {
+		if !isReadmeName(name) {
+			t.Errorf("Expected %s to be a README", name)
+		}
+	}
+	for _, name := range []string{"READMENOT.md", "other.md", "README.json", "README.md.bak", "my-readme.md", "README.a.b.md"} {
+		if isReadmeName(name) {
+			t.Errorf("Expected %s not to be a README", name)
+		}
+	}
+}
+
+func TestReadmeVariants(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	if err := os.Remove(filepath.Join(repo.Path, "README.md")); err != nil {
+		t.Fatalf("Failed to remove README.md: %v", err)
+	}
+	repo.WriteFile("README.rst", "Project\n=======\n")
+	repo.WriteFile("docs/ReadMe.MD", "# Docs\n")
+	repo.WriteFile("READMENOT.md", "Not a README\n")
+	repo.AddCommit("Add README variants")
+
+	readmeFiles, err := GetReadmeFiles(repo.Path, true, nil, nil)
+	if err != nil {
+		t.Fatalf("GetReadmeFiles failed: %v", err)
+	}
+	var paths []string
+	for _, readme := range readmeFiles {
+		paths = append(paths, filepath.ToSlash(readme.Path))
+	}
+	sort.Strings(paths)
+	if strings.Join(paths, ",") != "README.rst,docs/ReadMe.MD" {
+		t.Errorf("Expected README.rst and docs/ReadMe.MD, got %v", paths)
+	}
+
+	// Repository info falls back to the .rst README, preferring Markdown when both exist
+	content, err := findAndReadReadme(repo.Path)
+	if err != nil || content != "Project\n=======\n" {
+		t.Errorf("Expected README.rst content, got %q (%v)", content, err)
+	}
+	repo.WriteFile("readme.markdown", "# Markdown\n")
+	if content, _ := findAndReadReadme(repo.Path); content != "# Markdown\n" {
+		t.Errorf("Expected readme.markdown to be preferred, got %q", content)
+	}
+}
+
 // TestGetFileContentWithLineNumbers tests the line numbers functionality
 func TestGetFileContentWithLineNumbers(t *testing.T) {
 	repo := CreateTestRepositoryWithContent(t)
@@ -293,4 +346,108 @@ func TestGetMultipleFileContentsWithLineNumbers(t *testing.T) {
 			}
 		}
 	})
-}
\ No newline at end of file
+}
+func TestHandleGetReadmeFilesIncludeContent(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("README.md", "# Project\nline 2\nline 3\nline 4\nline 5\n")
+	repo.WriteFile("docs/README.md", "# Docs\n")
+
+	read := func(t *testing.T, args GetReadmeFilesParams) string {
+		t.Helper()
+		args.Repository = repo.Path
+		args.Recursive = true
+		result, _, err := handleGetReadmeFiles(context.Background(), nil, args)
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetReadmeFiles failed: %v %v", err, result.Content)
+		}
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	t.Run("without content", func(t *testing.T) {
+		text := read(t, GetReadmeFilesParams{})
+		if strings.Contains(text, "Content:") || strings.Contains(text, "# Project") {
+			t.Errorf("Expected metadata only:\n%s", text)
+		}
+	})
+
+	t.Run("with content", func(t *testing.T) {
+		text := read(t, GetReadmeFilesParams{IncludeContent: true, MaxLines: 2})
+		for _, want := range []string{
+			"   Content:\n   | # Project\n   | line 2\n   | ... (3 more lines)\n",
+			"   Content:\n   | # Docs\n\n",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in output:\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, "line 3") {
+			t.Errorf("Expected content capped at max_lines:\n%s", text)
+		}
+	})
+
+	t.Run("library", func(t *testing.T) {
+		readmeFiles, err := GetReadmeFiles(repo.Path, false, nil, nil)
+		if err != nil {
+			t.Fatalf("GetReadmeFiles failed: %v", err)
+		}
+		if len(readmeFiles) != 1 || readmeFiles[0].Content != "" {
+			t.Fatalf("Expected one README without content, got %+v", readmeFiles)
+		}
+		AddReadmeContents(repo.Path, readmeFiles, 0)
+		if readmeFiles[0].Content != "# Project\nline 2\nline 3\nline 4\nline 5\n" {
+			t.Errorf("Expected the full README, got %q", readmeFiles[0].Content)
+		}
+	})
+}
+
+func TestGetReadmeFilesPatterns(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("docs/README.md", "# Docs\n")
+	repo.WriteFile("node_modules/left-pad/README.md", "# left-pad\n")
+	repo.WriteFile("web/node_modules/react/README.md", "# react\n")
+	repo.WriteFile("vendor/github.com/pkg/errors/README.md", "# errors\n")
+
+	find := func(t *testing.T, includePatterns, excludePatterns []string) string {
+		t.Helper()
+		readmeFiles, err := GetReadmeFiles(repo.Path, true, includePatterns, excludePatterns)
+		if err != nil {
+			t.Fatalf("GetReadmeFiles failed: %v", err)
+		}
+		var paths []string
+		for _, readme := range readmeFiles {
+			paths = append(paths, filepath.ToSlash(readme.Path))
+		}
+		sort.Strings(paths)
+		return strings.Join(paths, ",")
+	}
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    string
+	}{
+		{"vendor dirs skipped by default", nil, nil, "README.md,docs/README.md"},
+		{"exclude pattern", nil, []string{"docs/"}, "README.md,node_modules/left-pad/README.md,vendor/github.com/pkg/errors/README.md,web/node_modules/react/README.md"},
+		{"include pattern", []string{"node_modules/**"}, nil, "node_modules/left-pad/README.md"},
+		{"include and exclude", []string{"**/README.md"}, []string{"vendor/", "**/node_modules/**"}, "README.md,docs/README.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := find(t, tt.include, tt.exclude); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleGetReadmeFiles(context.Background(), nil, GetReadmeFilesParams{Repository: repo.Path, Recursive: true, ExcludePatterns: []string{"docs/"}})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetReadmeFiles failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if strings.Contains(text, "docs/README.md") || !strings.Contains(text, "node_modules/left-pad/README.md") {
+			t.Errorf("Expected docs/ excluded and vendor READMEs listed:\n%s", text)
+		}
+	})
+}
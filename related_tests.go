@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+)
+
+// jsExtensions are the JavaScript/TypeScript extensions that share test conventions
+var jsExtensions = map[string]bool{
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+	".ts": true, ".tsx": true,
+}
+
+// FindRelatedTests returns tracked files related to filePath by common test
+// naming conventions: the tests for a source file, or the source for a test file
+func FindRelatedTests(repoPath, filePath string) ([]string, error) {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	repoPath = validPath
+
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
+
+	cmd := exec.Command("git", "-c", "core.quotePath=false", "ls-files")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+	tracked := strings.Split(strings.TrimSpace(string(output)), "\n")
+
+	filePath = path.Clean(strings.ReplaceAll(filePath, "\\", "/"))
+	candidates, err := relatedTestCandidates(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var related []string
+	for _, trackedPath := range tracked {
+		if trackedPath == filePath {
+			continue
+		}
+		if candidates(trackedPath) {
+			related = append(related, trackedPath)
+		}
+	}
+	sort.Strings(related)
+
+	return related, nil
+}
+
+// relatedTestCandidates returns a matcher for paths related to filePath
+func relatedTestCandidates(filePath string) (func(string) bool, error) {
+	dir, base := path.Split(filePath)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	inSet := func(paths ...string) func(string) bool {
+		set := make(map[string]bool)
+		for _, p := range paths {
+			set[p] = true
+		}
+		return func(candidate string) bool { return set[candidate] }
+	}
+
+	switch {
+	case ext == ".go":
+		// x.go <-> x_test.go in the same package directory
+		if strings.HasSuffix(stem, "_test") {
+			return inSet(dir + strings.TrimSuffix(stem, "_test") + ".go"), nil
+		}
+		return inSet(dir + stem + "_test.go"), nil
+
+	case jsExtensions[ext]:
+		// x.js <-> x.test.js / x.spec.js / __tests__/x.js
+		for _, suffix := range []string{".test", ".spec"} {
+			if strings.HasSuffix(stem, suffix) {
+				source := strings.TrimSuffix(stem, suffix) + ext
+				return inSet(dir+source, parentOfTestsDir(dir)+source), nil
+			}
+		}
+		if path.Base(dir) == "__tests__" {
+			return inSet(parentOfTestsDir(dir) + base), nil
+		}
+		return inSet(
+			dir+stem+".test"+ext,
+			dir+stem+".spec"+ext,
+			dir+"__tests__/"+base,
+			dir+"__tests__/"+stem+".test"+ext,
+		), nil
+
+	case ext == ".py":
+		// x.py <-> test_x.py / x_test.py; Python tests commonly live in a
+		// separate tests/ tree, so match by file name anywhere in the repo
+		var names []string
+		switch {
+		case strings.HasPrefix(stem, "test_"):
+			names = []string{strings.TrimPrefix(stem, "test_") + ".py"}
+		case strings.HasSuffix(stem, "_test"):
+			names = []string{strings.TrimSuffix(stem, "_test") + ".py"}
+		default:
+			names = []string{"test_" + base, stem + "_test.py"}
+		}
+		return func(candidate string) bool {
+			for _, name := range names {
+				if path.Base(candidate) == name {
+					return true
+				}
+			}
+			return false
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("no test conventions known for %q files", ext)
+	}
+}
+
+// parentOfTestsDir returns the directory containing a __tests__ directory
+// (with trailing slash), or dir unchanged if it is not a __tests__ directory
+func parentOfTestsDir(dir string) string {
+	if path.Base(dir) != "__tests__" {
+		return dir
+	}
+	parent := path.Dir(strings.TrimSuffix(dir, "/"))
+	if parent == "." {
+		return ""
+	}
+	return parent + "/"
+}
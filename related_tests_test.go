@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFindRelatedTests(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile("src/utils_test.go", "package src\n")
+	repo.WriteFile("web/app.js", "export {}\n")
+	repo.WriteFile("web/app.test.js", "test()\n")
+	repo.WriteFile("web/__tests__/app.js", "test()\n")
+	repo.WriteFile("pkg/parser.py", "def parse(): pass\n")
+	repo.WriteFile("tests/test_parser.py", "def test_parse(): pass\n")
+	repo.AddCommit("Add related test fixtures")
+
+	tests := []struct {
+		name     string
+		filePath string
+		expected []string
+	}{
+		{"go source to test", "src/utils.go", []string{"src/utils_test.go"}},
+		{"go test to source", "src/utils_test.go", []string{"src/utils.go"}},
+		{"go source without tests", "main.go", nil},
+		{"js source to tests", "web/app.js", []string{"web/__tests__/app.js", "web/app.test.js"}},
+		{"js test to source", "web/app.test.js", []string{"web/app.js"}},
+		{"js __tests__ to source", "web/__tests__/app.js", []string{"web/app.js"}},
+		{"python source to test", "pkg/parser.py", []string{"tests/test_parser.py"}},
+		{"python test to source", "tests/test_parser.py", []string{"pkg/parser.py"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			related, err := FindRelatedTests(repo.Path, tt.filePath)
+			if err != nil {
+				t.Fatalf("FindRelatedTests failed: %v", err)
+			}
+			if strings.Join(related, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, related)
+			}
+		})
+	}
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		if _, err := FindRelatedTests(repo.Path, "docs/api.md"); err == nil {
+			t.Error("Expected error for unsupported extension")
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		result, _, err := handleFindRelatedTests(context.Background(), nil, FindRelatedTestsParams{
+			Repository: repo.Path,
+			FilePath:   "src/utils.go",
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("handleFindRelatedTests failed: %v %v", err, result.Content)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "src/utils_test.go") {
+			t.Errorf("Unexpected output: %s", text)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// repoInfoCacheTTL is how long GetRepositoryInfo reuses a result for an unchanged HEAD
+const repoInfoCacheTTL = 30 * time.Second
+
+// repoInfoCacheEntry is a RepositoryInfo along with the HEAD it was collected at
+type repoInfoCacheEntry struct {
+	info     RepositoryInfo
+	head     string
+	storedAt time.Time
+}
+
+// repoInfoCache holds GetRepositoryInfo results keyed by repository path
+type repoInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]repoInfoCacheEntry
+	now     func() time.Time // replaced in tests
+}
+
+var globalRepoInfoCache = &repoInfoCache{
+	entries: make(map[string]repoInfoCacheEntry),
+	now:     time.Now,
+}
+
+// get returns a copy of the cached info for repoPath if it was stored at the
+// same HEAD less than repoInfoCacheTTL ago
+func (c *repoInfoCache) get(repoPath, head string) (*RepositoryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[repoPath]
+	if !ok || entry.head != head || c.now().Sub(entry.storedAt) >= repoInfoCacheTTL {
+		return nil, false
+	}
+	info := entry.info
+	return &info, true
+}
+
+func (c *repoInfoCache) put(repoPath, head string, info *RepositoryInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[repoPath] = repoInfoCacheEntry{info: *info, head: head, storedAt: c.now()}
+}
+
+func (c *repoInfoCache) delete(repoPath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, repoPath)
+}
+
+// InvalidateRepositoryInfo drops the cached GetRepositoryInfo result for a
+// repository so the next call recomputes it
+func InvalidateRepositoryInfo(repoPath string) error {
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return err
+	}
+
+	globalRepoInfoCache.delete(validPath)
+	return nil
+}
+
+// headState identifies what HEAD points at: the commit hash and the branch
+// ref, so switching between branches at the same commit also counts as a
+// change. It is empty when HEAD cannot be resolved (no commits yet).
+func headState(repoPath string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD", "--symbolic-full-name", "HEAD")
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRepositoryInfoCache(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+
+	now := time.Now()
+	originalNow := globalRepoInfoCache.now
+	globalRepoInfoCache.now = func() time.Time { return now }
+	defer func() { globalRepoInfoCache.now = originalNow }()
+
+	readme := func(t *testing.T) string {
+		t.Helper()
+		info, err := GetRepositoryInfo(repo.Path)
+		if err != nil {
+			t.Fatalf("GetRepositoryInfo failed: %v", err)
+		}
+		return info.ReadmeContent
+	}
+
+	if got := readme(t); !strings.Contains(got, "# Test Repository") {
+		t.Fatalf("Unexpected README: %q", got)
+	}
+
+	// An uncommitted edit is not seen while the entry is fresh
+	repo.WriteFile("README.md", "# Edited\n")
+	now = now.Add(repoInfoCacheTTL / 2)
+	if got := readme(t); !strings.Contains(got, "# Test Repository") {
+		t.Errorf("Expected the cached README within the TTL, got %q", got)
+	}
+
+	t.Run("expires after the TTL", func(t *testing.T) {
+		now = now.Add(repoInfoCacheTTL)
+		if got := readme(t); got != "# Edited\n" {
+			t.Errorf("Expected the README to be re-read after the TTL, got %q", got)
+		}
+	})
+
+	t.Run("invalidated when HEAD moves", func(t *testing.T) {
+		repo.WriteFile("README.md", "# Committed\n")
+		repo.AddCommit("Update README")
+		if got := readme(t); got != "# Committed\n" {
+			t.Errorf("Expected a new commit to invalidate the cache, got %q", got)
+		}
+
+		repo.runGitCommand("checkout", "-q", "-b", "same-commit")
+		info, err := GetRepositoryInfo(repo.Path)
+		if err != nil || info.CurrentBranch != "same-commit" {
+			t.Errorf("Expected a branch switch to invalidate the cache, got %+v (%v)", info, err)
+		}
+	})
+
+	t.Run("refresh bypasses the cache", func(t *testing.T) {
+		repo.WriteFile("README.md", "# Refreshed\n")
+
+		result, _, err := handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repo.Path})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; strings.Contains(text, "# Refreshed") {
+			t.Errorf("Expected the cached README without refresh:\n%s", text)
+		}
+
+		result, _, err = handleGetRepositoryInfo(context.Background(), nil, GetRepositoryInfoParams{Repository: repo.Path, Refresh: true})
+		if err != nil || result.IsError {
+			t.Fatalf("handleGetRepositoryInfo failed: %v %v", err, result.Content)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "# Refreshed") {
+			t.Errorf("Expected refresh to re-read the README:\n%s", text)
+		}
+	})
+}
@@ -3,14 +3,30 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 )
 
-// SearchFilesEnhanced searches for files with enhanced features
-func SearchFilesEnhanced(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, includePatterns, excludePatterns []string, maxResults int) ([]SearchResult, error) {
+// SearchFilesEnhanced searches for files with enhanced features. searchMode is
+// "and", "or" or "phrase", which matches the keywords joined by spaces as one
+// literal string. Files whose content contains any of excludeKeywords are
+// dropped before the limit applies.
+func SearchFilesEnhanced(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, includePatterns, excludePatterns, excludeKeywords []string, maxResults int) ([]SearchResult, error) {
+	return searchFiles(repoPath, keywords, searchMode, includeFilename, contextLines, false, includePatterns, excludePatterns, excludeKeywords, maxResults)
+}
+
+// SearchFilesWithFunctionContext is SearchFilesEnhanced with each content
+// match's enclosing function (git grep -p) in MatchLine.Function. It always
+// uses git grep, as ripgrep cannot report the function.
+func SearchFilesWithFunctionContext(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, includePatterns, excludePatterns, excludeKeywords []string, maxResults int) ([]SearchResult, error) {
+	return searchFiles(repoPath, keywords, searchMode, includeFilename, contextLines, true, includePatterns, excludePatterns, excludeKeywords, maxResults)
+}
+
+func searchFiles(repoPath string, keywords []string, searchMode string, includeFilename bool, contextLines int, functionContext bool, includePatterns, excludePatterns, excludeKeywords []string, maxResults int) ([]SearchResult, error) {
 	// Validate workspace path
 	validPath, err := ValidateWorkspacePath(repoPath)
 	if err != nil {
@@ -26,10 +42,19 @@ func SearchFilesEnhanced(repoPath string, keywords []string, searchMode string,
 		return []SearchResult{}, nil
 	}
 
+	// A phrase is one literal keyword: the keywords joined by single spaces,
+	// escaped for the content engines, which take basic regular expressions
+	contentKeywords := keywords
+	if searchMode == "phrase" {
+		keywords = []string{strings.Join(keywords, " ")}
+		contentKeywords = []string{quoteBasicRegexp(keywords[0])}
+		searchMode = "and"
+	}
+
 	var allResults []SearchResult
 
 	// Search in file contents
-	contentResults, err := searchInContent(repoPath, keywords, searchMode, contextLines, includePatterns, excludePatterns)
+	contentResults, err := searchInContent(repoPath, contentKeywords, searchMode, contextLines, functionContext, includePatterns, excludePatterns)
 	if err == nil {
 		allResults = append(allResults, contentResults...)
 	}
@@ -42,8 +67,12 @@ func SearchFilesEnhanced(repoPath string, keywords []string, searchMode string,
 		}
 	}
 
-	// Remove duplicates and apply limit
+	// Remove duplicates and excluded files, then apply limit
 	uniqueResults := removeDuplicateResults(allResults)
+	uniqueResults, err = filterResultsByExcludedKeywords(repoPath, uniqueResults, excludeKeywords)
+	if err != nil {
+		return nil, err
+	}
 	if maxResults > 0 && len(uniqueResults) > maxResults {
 		uniqueResults = uniqueResults[:maxResults]
 	}
@@ -51,35 +80,151 @@ func SearchFilesEnhanced(repoPath string, keywords []string, searchMode string,
 	return uniqueResults, nil
 }
 
-// searchInContent searches for keywords in file contents
-func searchInContent(repoPath string, keywords []string, searchMode string, contextLines int, includePatterns, excludePatterns []string) ([]SearchResult, error) {
-	if len(keywords) == 0 {
-		return []SearchResult{}, nil
+// SearchInSymbol searches for keyword only within the line range of the named
+// outline symbol. symbolName may be a bare name or "Receiver.Method".
+func SearchInSymbol(repoPath, symbolName, keyword, language string) ([]MatchLine, error) {
+	// Validate workspace path
+	validPath, err := ValidateWorkspacePath(repoPath)
+	if err != nil {
+		return nil, err
 	}
+	repoPath = validPath
 
-	// Build git grep command
-	args := []string{"grep"}
+	if !isGitRepository(repoPath) {
+		return nil, fmt.Errorf("not a git repository: %s", repoPath)
+	}
 
-	// Add context lines if requested
-	if contextLines > 0 {
-		args = append(args, "-C", strconv.Itoa(contextLines))
+	if symbolName == "" || keyword == "" {
+		return nil, fmt.Errorf("symbol and keyword are required")
 	}
 
-	// Add line numbers
-	args = append(args, "-n")
+	if language == "" {
+		language = "go"
+	}
+	glob, ok := outlineLanguageGlobs[language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported outline language: %q", language)
+	}
+
+	cmd := exec.Command("git", "ls-files", "--", glob)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+
+	keywordLower := strings.ToLower(keyword)
+	var matches []MatchLine
+
+	for _, filePath := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if filePath == "" {
+			continue
+		}
+
+		fullPath, err := ResolveWorkspaceFile(repoPath, filePath)
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+
+		// Files that fail to parse are skipped rather than failing the search
+		symbols, err := ExtractOutline(filePath, content, language)
+		if err != nil {
+			continue
+		}
+
+		lines := strings.Split(string(content), "\n")
+		for _, symbol := range symbols {
+			if !symbol.matchesSymbol(symbolName) {
+				continue
+			}
+			for lineNum := symbol.StartLine; lineNum <= symbol.EndLine && lineNum <= len(lines); lineNum++ {
+				line := lines[lineNum-1]
+				if strings.Contains(strings.ToLower(line), keywordLower) {
+					matches = append(matches, MatchLine{
+						Path:       filePath,
+						LineNumber: lineNum,
+						Content:    line,
+					})
+				}
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// quoteBasicRegexp escapes the characters special in a POSIX basic regular
+// expression so s matches literally
+func quoteBasicRegexp(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`\.[]*^$`, s[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// searchInContent searches for keywords in file contents, with ripgrep when it
+// is installed and git grep otherwise (or when ripgrep fails, e.g. on a
+// pattern it cannot compile). Only git grep can find the enclosing function.
+func searchInContent(repoPath string, keywords []string, searchMode string, contextLines int, functionContext bool, includePatterns, excludePatterns []string) ([]SearchResult, error) {
+	if ripgrepAvailable() && !functionContext {
+		if results, err := searchInContentRipgrep(repoPath, keywords, searchMode, contextLines, includePatterns, excludePatterns); err == nil {
+			return results, nil
+		}
+	}
+	return searchInContentGitGrep(repoPath, keywords, searchMode, contextLines, functionContext, includePatterns, excludePatterns)
+}
+
+// searchInContentGitGrep searches for keywords in file contents with git grep.
+// With functionContext, -p reports the function around each match.
+func searchInContentGitGrep(repoPath string, keywords []string, searchMode string, contextLines int, functionContext bool, includePatterns, excludePatterns []string) ([]SearchResult, error) {
+	if len(keywords) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	// Build git grep command with line numbers. With context, --heading --break
+	// print each file's name once, so context lines ("N-text") and function
+	// lines ("N=text") can be told apart from matches ("N:text") whatever the
+	// path contains.
+	headings := contextLines > 0 || functionContext
+	grepArgs := func(withContext bool, extra ...string) []string {
+		args := []string{"grep", "-n"}
+		if withContext && contextLines > 0 {
+			args = append(args, "-C", strconv.Itoa(contextLines))
+		}
+		if withContext && functionContext {
+			args = append(args, "-p")
+		}
+		if withContext && headings {
+			args = append(args, "--heading", "--break")
+		}
+		return append(args, extra...)
+	}
+	parse := func(output string, withContext bool) []SearchResult {
+		if withContext && headings {
+			return parseGrepHeadingOutput(output, "content", contextLines)
+		}
+		return parseGrepOutput(output, "content", 0)
+	}
 
 	var results []SearchResult
 
 	if searchMode == "or" {
 		// For OR mode, search each keyword separately and merge results
 		for _, keyword := range keywords {
-			keywordArgs := append(args, keyword)
-			cmd := exec.Command("git", keywordArgs...)
+			cmd := exec.Command("git", grepArgs(true, keyword)...)
 			cmd.Dir = repoPath
 			output, err := cmd.Output()
 
 			if err == nil {
-				keywordResults := parseGrepOutput(string(output), "content", contextLines > 0)
+				keywordResults := parse(string(output), true)
 				// Filter results based on include/exclude patterns
 				filteredResults := filterResultsByPatterns(keywordResults, includePatterns, excludePatterns)
 				results = append(results, filteredResults...)
@@ -89,30 +234,41 @@ func searchInContent(repoPath string, keywords []string, searchMode string, cont
 		// For AND mode, use multiple grep commands piped together
 		if len(keywords) == 1 {
 			// Single keyword
-			args = append(args, keywords[0])
-			cmd := exec.Command("git", args...)
+			cmd := exec.Command("git", grepArgs(true, keywords[0])...)
 			cmd.Dir = repoPath
 
 			output, err := cmd.Output()
 
 			if err == nil {
-				results = parseGrepOutput(string(output), "content", contextLines > 0)
+				results = parse(string(output), true)
 				// Filter results based on include/exclude patterns
 				results = filterResultsByPatterns(results, includePatterns, excludePatterns)
 			}
 		} else {
 			// Multiple keywords - implement AND logic by filtering results
-			firstKeywordArgs := append(args, keywords[0])
-			cmd := exec.Command("git", firstKeywordArgs...)
+			cmd := exec.Command("git", grepArgs(false, keywords[0])...)
 			cmd.Dir = repoPath
 			output, err := cmd.Output()
 
 			if err == nil {
-				results = parseGrepOutput(string(output), "content", contextLines > 0)
+				results = parse(string(output), false)
 				// Filter results based on include/exclude patterns
 				results = filterResultsByPatterns(results, includePatterns, excludePatterns)
 				// Filter results to only include files that contain all keywords
 				results = filterResultsByAllKeywords(repoPath, results, keywords[1:])
+
+				// Context is only fetched for the files that survived the intersection
+				if headings && len(results) > 0 {
+					args := append(grepArgs(true, keywords[0]), "--")
+					for _, result := range results {
+						args = append(args, result.Path)
+					}
+					cmd := exec.Command("git", append([]string{"--literal-pathspecs"}, args...)...)
+					cmd.Dir = repoPath
+					if output, err := cmd.Output(); err == nil {
+						results = parse(string(output), true)
+					}
+				}
 			}
 		}
 	}
@@ -182,11 +338,16 @@ func searchInFilenames(repoPath string, keywords []string, searchMode string, in
 	return results, nil
 }
 
-// parseGrepOutput parses git grep output into SearchResult structs
-func parseGrepOutput(output string, matchType string, hasContext bool) []SearchResult {
+// parseGrepOutput parses git grep output into SearchResult structs. With
+// contextLines > 0 the output must come from `git grep -n -C N --heading
+// --break`, and each match's Context holds the lines up to contextLines away.
+func parseGrepOutput(output string, matchType string, contextLines int) []SearchResult {
 	if output == "" {
 		return []SearchResult{}
 	}
+	if contextLines > 0 {
+		return parseGrepHeadingOutput(output, matchType, contextLines)
+	}
 
 	lines := strings.Split(output, "\n")
 	resultMap := make(map[string]*SearchResult)
@@ -237,27 +398,134 @@ func parseGrepOutput(output string, matchType string, hasContext bool) []SearchR
 	return results
 }
 
-// filterResultsByAllKeywords filters results to only include files containing all keywords
-func filterResultsByAllKeywords(repoPath string, results []SearchResult, keywords []string) []SearchResult {
-	var filtered []SearchResult
+// parseGrepHeadingOutput parses `git grep -n --heading --break` output: each
+// file is a heading line followed by "N:text" matches and "N-text" context
+// lines, with "--" between hunks and an empty line between files. With -p,
+// "N=text" function lines come before the matches inside that function and
+// are recorded on each of them; git prints a function line only once, so it
+// carries over to later matches in the file.
+func parseGrepHeadingOutput(output string, matchType string, contextLines int) []SearchResult {
+	var results []SearchResult
+	var current *SearchResult
+	var fileLines map[int]string
+	var function string
+	var functionLine int
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		attachContext(current, fileLines, contextLines)
+		results = append(results, *current)
+		current = nil
+	}
 
-	for _, result := range results {
-		content, err := GetFileContent(repoPath, result.Path, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if line == "" {
+			flush()
+			continue
+		}
+		if current == nil {
+			current = &SearchResult{Path: line, MatchType: matchType, Matches: []MatchLine{}}
+			fileLines = make(map[int]string)
+			function, functionLine = "", 0
+			continue
+		}
+		if line == "--" {
+			continue
+		}
+
+		digits := 0
+		for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || digits == len(line) || (line[digits] != ':' && line[digits] != '-' && line[digits] != '=') {
+			continue
+		}
+		lineNum, err := strconv.Atoi(line[:digits])
 		if err != nil {
 			continue
 		}
 
-		contentLower := strings.ToLower(content)
-		allMatch := true
+		text := line[digits+1:]
+		fileLines[lineNum] = text
+		switch line[digits] {
+		case '=':
+			function, functionLine = text, lineNum
+		case ':':
+			match := MatchLine{LineNumber: lineNum, Content: text}
+			if isFunctionLine(text) {
+				// git prints no function line for a match that is one itself
+				// (by its default rule); it is the function for the matches after it
+				function, functionLine = text, lineNum
+			} else if function != "" {
+				match.Function, match.FunctionLine = function, functionLine
+			}
+			current.Matches = append(current.Matches, match)
+		}
+	}
+	flush()
+
+	return results
+}
 
-		for _, keyword := range keywords {
-			if !strings.Contains(contentLower, strings.ToLower(keyword)) {
-				allMatch = false
-				break
+// isFunctionLine applies git's default function line rule for -p, a line
+// starting with a letter, '_' or '$'. Diff drivers set in .gitattributes may
+// use other rules.
+func isFunctionLine(text string) bool {
+	if text == "" {
+		return false
+	}
+	c := text[0]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$'
+}
+
+// attachContext fills each match's Context with the lines of fileLines (match
+// and context lines of one file, by line number) up to contextLines away
+func attachContext(result *SearchResult, fileLines map[int]string, contextLines int) {
+	for i := range result.Matches {
+		lineNum := result.Matches[i].LineNumber
+		for n := lineNum - contextLines; n <= lineNum+contextLines; n++ {
+			if text, ok := fileLines[n]; ok && n != lineNum {
+				if result.Matches[i].Context == nil {
+					result.Matches[i].ContextStart = n
+				}
+				result.Matches[i].Context = append(result.Matches[i].Context, text)
 			}
 		}
+	}
+}
 
-		if allMatch {
+// filterResultsByAllKeywords keeps only the results whose file contains every
+// keyword. The file sets come from one `git grep -l` per keyword, so no file
+// content is read into memory. Keywords match as fixed, case-insensitive strings.
+func filterResultsByAllKeywords(repoPath string, results []SearchResult, keywords []string) []SearchResult {
+	if len(results) == 0 || len(keywords) == 0 {
+		return results
+	}
+
+	var common map[string]bool
+	for _, keyword := range keywords {
+		files, err := filesContainingKeyword(repoPath, keyword)
+		if err != nil {
+			return nil
+		}
+		if common != nil {
+			for path := range files {
+				if !common[path] {
+					delete(files, path)
+				}
+			}
+		}
+		common = files
+		if len(common) == 0 {
+			return nil
+		}
+	}
+
+	var filtered []SearchResult
+	for _, result := range results {
+		if common[result.Path] {
 			filtered = append(filtered, result)
 		}
 	}
@@ -265,14 +533,103 @@ func filterResultsByAllKeywords(repoPath string, results []SearchResult, keyword
 	return filtered
 }
 
+// filterResultsByExcludedKeywords drops the results whose file contains any of
+// keywords, matched like filterResultsByAllKeywords as fixed, case-insensitive
+// strings with one `git grep -l` per keyword.
+func filterResultsByExcludedKeywords(repoPath string, results []SearchResult, keywords []string) ([]SearchResult, error) {
+	if len(results) == 0 || len(keywords) == 0 {
+		return results, nil
+	}
+
+	excluded := make(map[string]bool)
+	for _, keyword := range keywords {
+		if keyword == "" {
+			continue
+		}
+		files, err := filesContainingKeyword(repoPath, keyword)
+		if err != nil {
+			return nil, err
+		}
+		for path := range files {
+			excluded[path] = true
+		}
+	}
+
+	var filtered []SearchResult
+	for _, result := range results {
+		if !excluded[result.Path] {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered, nil
+}
+
+// filesContainingKeyword returns the set of tracked files that contain keyword
+// (git grep -l). No match is an empty set, not an error.
+func filesContainingKeyword(repoPath, keyword string) (map[string]bool, error) {
+	cmd := exec.Command("git", "grep", "-l", "-I", "-i", "-F", "-e", keyword)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("git grep failed: %v", err)
+	}
+
+	files := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files[line] = true
+		}
+	}
+	return files, nil
+}
+
+// DefaultMaxMatchesPerFile is the per-file match cap used by search_files when none is given
+const DefaultMaxMatchesPerFile = 10
+
+// limitMatchesPerFile keeps the first maxMatches content matches of each result
+// in line order and records how many were dropped. A filename match (line 0)
+// sorts first and does not count toward the cap. maxMatches <= 0 disables the cap.
+func limitMatchesPerFile(results []SearchResult, maxMatches int) []SearchResult {
+	if maxMatches <= 0 {
+		return results
+	}
+	for i := range results {
+		matches := results[i].Matches
+		filenameMatches := 0
+		for _, match := range matches {
+			if match.LineNumber == 0 {
+				filenameMatches++
+			}
+		}
+		if len(matches)-filenameMatches <= maxMatches {
+			continue
+		}
+		// OR mode merges matches from several greps, so restore line order first
+		sort.SliceStable(matches, func(a, b int) bool {
+			return matches[a].LineNumber < matches[b].LineNumber
+		})
+		results[i].OmittedMatches += len(matches) - filenameMatches - maxMatches
+		results[i].Matches = matches[:filenameMatches+maxMatches]
+	}
+	return results
+}
+
 // removeDuplicateResults removes duplicate search results by path
 func removeDuplicateResults(results []SearchResult) []SearchResult {
 	seen := make(map[string]*SearchResult)
 
 	for _, result := range results {
 		if existing, exists := seen[result.Path]; exists {
-			// Merge matches from duplicate results
-			existing.Matches = append(existing.Matches, result.Matches...)
+			// Merge matches from duplicate results, keeping filename matches first
+			if result.MatchType == "filename" {
+				existing.Matches = append(append([]MatchLine{}, result.Matches...), existing.Matches...)
+			} else {
+				existing.Matches = append(existing.Matches, result.Matches...)
+			}
 			// If one result has content matches and another has filename matches, combine them
 			if result.MatchType == "filename" && existing.MatchType == "content" {
 				existing.MatchType = "both"
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// maxRipgrepIgnoredPaths caps how many tracked files matched by ignore rules
+// are passed to rg by name; past it the git grep engine is used instead
+const maxRipgrepIgnoredPaths = 1000
+
+// ripgrepAvailable reports whether rg is on PATH. The lookup runs once per process.
+var ripgrepAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("rg")
+	return err == nil
+})
+
+// searchInContentRipgrep is the ripgrep engine behind searchInContent. OR mode
+// runs a single rg for all keywords; AND mode searches the first keyword and
+// intersects with the others like the git grep engine does.
+//
+// rg walks the working tree, so it searches the same files as git grep only
+// with help: tracked files that ignore rules would hide from the walk are
+// named on its command line, and untracked files are dropped from its results.
+func searchInContentRipgrep(repoPath string, keywords []string, searchMode string, contextLines int, includePatterns, excludePatterns []string) ([]SearchResult, error) {
+	if len(keywords) == 0 {
+		return []SearchResult{}, nil
+	}
+
+	tracked, ignored, err := trackedFiles(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(ignored) > maxRipgrepIgnoredPaths {
+		return nil, fmt.Errorf("too many tracked files match ignore rules (%d)", len(ignored))
+	}
+
+	patterns := keywords
+	if searchMode != "or" {
+		patterns = keywords[:1]
+	}
+
+	found, err := runRipgrep(repoPath, patterns, contextLines, ignored)
+	if err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	for _, result := range found {
+		if tracked[result.Path] {
+			results = append(results, result)
+		}
+	}
+
+	// Filter results based on include/exclude patterns
+	results = filterResultsByPatterns(results, includePatterns, excludePatterns)
+	if searchMode != "or" && len(keywords) > 1 {
+		results = filterResultsByAllKeywords(repoPath, results, keywords[1:])
+	}
+
+	return removeDuplicateResults(results), nil
+}
+
+// trackedFiles returns the files git grep searches, those in the index, and
+// the ones among them that ignore rules match and that exist in the working
+// tree. rg skips ignored files while walking, so those are named to it.
+func trackedFiles(repoPath string) (map[string]bool, []string, error) {
+	list := func(args ...string) ([]string, error) {
+		cmd := exec.Command("git", append([]string{"ls-files", "-z"}, args...)...)
+		cmd.Dir = repoPath
+		output, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git ls-files failed: %v", err)
+		}
+		return strings.FieldsFunc(string(output), func(r rune) bool { return r == 0 }), nil
+	}
+
+	paths, err := list()
+	if err != nil {
+		return nil, nil, err
+	}
+	tracked := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		tracked[path] = true
+	}
+
+	ignoredPaths, err := list("--cached", "--ignored", "--exclude-standard")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ignored []string
+	for _, path := range ignoredPaths {
+		if info, err := os.Lstat(filepath.Join(repoPath, path)); err == nil && info.Mode().IsRegular() {
+			ignored = append(ignored, path)
+		}
+	}
+	return tracked, ignored, nil
+}
+
+// rgMessage is one line of `rg --json` output. Only the fields used for
+// "match" and "context" messages are decoded; paths and lines that are not
+// valid UTF-8 arrive as base64 "bytes" and are skipped.
+type rgMessage struct {
+	Type string `json:"type"`
+	Data struct {
+		Path struct {
+			Text string `json:"text"`
+		} `json:"path"`
+		Lines struct {
+			Text string `json:"text"`
+		} `json:"lines"`
+		LineNumber int `json:"line_number"`
+	} `json:"data"`
+}
+
+// runRipgrep runs one rg over the repository, and over extraPaths even if
+// they are ignored, for patterns (any of them may match) and converts its
+// JSON output to SearchResults shaped like the git grep engine's
+func runRipgrep(repoPath string, patterns []string, contextLines int, extraPaths []string) ([]SearchResult, error) {
+	// --no-config, --no-ignore-dot and --no-ignore-parent keep user settings,
+	// .ignore files and ignore files above the repository from changing what
+	// is searched; --hidden matches git grep, which searches tracked dotfiles
+	args := []string{"--json", "--no-config", "--hidden", "--no-ignore-dot", "--no-ignore-parent", "--glob", "!.git", "--line-number"}
+	if contextLines > 0 {
+		args = append(args, "-C", strconv.Itoa(contextLines))
+	}
+	for _, pattern := range patterns {
+		args = append(args, "-e", basicRegexpToRipgrep(pattern))
+	}
+	args = append(args, "--", ".")
+	args = append(args, extraPaths...)
+
+	cmd := exec.Command("rg", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.Output()
+	if err != nil {
+		// rg exits 1 when nothing matched
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return []SearchResult{}, nil
+		}
+		return nil, fmt.Errorf("rg failed: %v", err)
+	}
+
+	resultMap := make(map[string]*SearchResult)
+	fileLines := make(map[string]map[int]string)
+	var order []string
+
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var msg rgMessage
+		if err := decoder.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("failed to parse rg output: %v", err)
+		}
+		if (msg.Type != "match" && msg.Type != "context") || msg.Data.Path.Text == "" || msg.Data.LineNumber == 0 {
+			continue
+		}
+
+		filePath := strings.TrimPrefix(msg.Data.Path.Text, "./")
+		text := strings.TrimSuffix(msg.Data.Lines.Text, "\n")
+		if contextLines == 0 {
+			// The git grep engine trims trailing whitespace from matches when
+			// there is no context
+			text = strings.TrimRightFunc(text, unicode.IsSpace)
+		}
+
+		if resultMap[filePath] == nil {
+			resultMap[filePath] = &SearchResult{Path: filePath, MatchType: "content", Matches: []MatchLine{}}
+			fileLines[filePath] = make(map[int]string)
+			order = append(order, filePath)
+		}
+		fileLines[filePath][msg.Data.LineNumber] = text
+		if msg.Type == "match" {
+			resultMap[filePath].Matches = append(resultMap[filePath].Matches, MatchLine{
+				LineNumber: msg.Data.LineNumber,
+				Content:    text,
+			})
+		}
+	}
+
+	var results []SearchResult
+	for _, filePath := range order {
+		result := resultMap[filePath]
+		if len(result.Matches) == 0 {
+			continue
+		}
+		if contextLines > 0 {
+			attachContext(result, fileLines[filePath], contextLines)
+		}
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// basicRegexpToRipgrep translates a POSIX basic regular expression, as git
+// grep interprets keywords, to ripgrep's syntax: + ? ( ) { } | are literal
+// unless escaped in BRE and the other way round in ripgrep. Bracket
+// expressions are copied unchanged.
+func basicRegexpToRipgrep(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			if strings.IndexByte("+?(){}|", pattern[i]) < 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(pattern[i])
+		case c == '[':
+			// A ']' right after the opening '[' or '[^' is a literal member
+			end := i + 1
+			if end < len(pattern) && pattern[end] == '^' {
+				end++
+			}
+			if end < len(pattern) && pattern[end] == ']' {
+				end++
+			}
+			for end < len(pattern) && pattern[end] != ']' {
+				end++
+			}
+			if end >= len(pattern) {
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(pattern[i : end+1])
+			i = end
+		case strings.IndexByte("+?(){}|", c) >= 0:
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBasicRegexpToRipgrep(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"plain text", "plain text"},
+		{"return a + b", `return a \+ b`},
+		{"f(x)?", `f\(x\)\?`},
+		{`a\|b`, "a|b"},
+		{`ab\{2\}`, "ab{2}"},
+		{"^func .*Add$", "^func .*Add$"},
+		{`a\.b`, `a\.b`},
+		{"[(+]x", "[(+]x"},
+		{"[]a]", "[]a]"},
+		{"[unterminated", `\[unterminated`},
+	}
+	for _, tt := range tests {
+		if got := basicRegexpToRipgrep(tt.pattern); got != tt.want {
+			t.Errorf("basicRegexpToRipgrep(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestTrackedFiles(t *testing.T) {
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile(".gitignore", "build/\n")
+	repo.WriteFile("build/generated.go", "package build\n")
+	repo.runGitCommand("add", "-f", "build/generated.go")
+	repo.AddCommit("Add ignored but tracked file")
+	repo.WriteFile("scratch.txt", "untracked\n")
+	repo.WriteFile("build/local.go", "untracked and ignored\n")
+
+	tracked, ignored, err := trackedFiles(repo.Path)
+	if err != nil {
+		t.Fatalf("trackedFiles failed: %v", err)
+	}
+	for path, want := range map[string]bool{"README.md": true, "src/utils.go": true, "build/generated.go": true, "scratch.txt": false, "build/local.go": false} {
+		if tracked[path] != want {
+			t.Errorf("tracked[%q] = %v, want %v", path, tracked[path], want)
+		}
+	}
+	if strings.Join(ignored, ",") != "build/generated.go" {
+		t.Errorf("Expected only build/generated.go named to rg, got %q", ignored)
+	}
+}
+
+func TestSearchEnginesAgree(t *testing.T) {
+	if !ripgrepAvailable() {
+		t.Skip("rg not found on PATH")
+	}
+
+	repo := CreateTestRepositoryWithContent(t)
+	repo.WriteFile(".config/settings.txt", "database = sqlite\n")
+	repo.WriteFile("notes/literal.txt", "a.b [x] $5 ^*\naxb [x] $5 ^*\n")
+	repo.WriteFile(".gitignore", "build/\n")
+	repo.WriteFile("build/generated.go", "// database schema, generated\n")
+	repo.runGitCommand("add", "-f", "build/generated.go")
+	repo.AddCommit("Add dotfile")
+	// Untracked files are not searched by either engine
+	repo.WriteFile("scratch.txt", "database scratch notes\n")
+
+	// summarize reduces results to "path:line,line" entries in a stable order
+	summarize := func(results []SearchResult) string {
+		var entries []string
+		for _, result := range results {
+			var lines []string
+			for _, match := range result.Matches {
+				lines = append(lines, fmt.Sprintf("%d%q%q", match.LineNumber, match.Content, match.Context))
+			}
+			sort.Strings(lines)
+			entries = append(entries, result.Path+":"+strings.Join(lines, ","))
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, "\n")
+	}
+
+	tests := []struct {
+		name         string
+		keywords     []string
+		mode         string
+		contextLines int
+	}{
+		{"single keyword", []string{"database"}, "and", 0},
+		{"or mode", []string{"Add", "postgres"}, "or", 0},
+		{"and mode", []string{"Add", "Multiply"}, "and", 0},
+		{"regex characters", []string{"return a + b"}, "and", 0},
+		{"quoted literal", []string{quoteBasicRegexp("a.b [x] $5 ^*")}, "and", 0},
+		{"context lines", []string{"Multiply"}, "and", 2},
+		{"no match", []string{"nonexistent-keyword"}, "and", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitResults, err := searchInContentGitGrep(repo.Path, tt.keywords, tt.mode, tt.contextLines, false, nil, nil)
+			if err != nil {
+				t.Fatalf("git grep engine failed: %v", err)
+			}
+			rgResults, err := searchInContentRipgrep(repo.Path, tt.keywords, tt.mode, tt.contextLines, nil, nil)
+			if err != nil {
+				t.Fatalf("ripgrep engine failed: %v", err)
+			}
+			if got, want := summarize(rgResults), summarize(gitResults); got != want {
+				t.Errorf("Engines disagree\nripgrep:\n%s\ngit grep:\n%s", got, want)
+			}
+		})
+	}
+}
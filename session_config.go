@@ -1,6 +1,23 @@
 package main
 
-import "sync"
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultMaxFileBytes is the largest file GetFileContent reads in full when
+// no session value is set
+const defaultMaxFileBytes int64 = 10 * 1024 * 1024
+
+// defaultCloneTimeout is how long a clone may run when no session value is set
+const defaultCloneTimeout = 10 * time.Minute
+
+// sessionConfigFileName is the file inside the workspace that stores the session configuration
+const sessionConfigFileName = "session_config.json"
 
 // SessionConfig holds server-side session configuration
 // This allows clients to set defaults that persist across tool calls
@@ -19,6 +36,18 @@ type SessionConfig struct {
 	DefaultListFilesLimit int `json:"default_list_files_limit,omitempty"`
 	DefaultMaxLines       int `json:"default_max_lines,omitempty"`
 	DefaultCommitLimit    int `json:"default_commit_limit,omitempty"`
+
+	// Largest file read in full without a line limit (0 falls back to 10MB)
+	DefaultMaxFileBytes int64 `json:"default_max_file_bytes,omitempty"`
+
+	// Seconds a clone may run before it is killed (0 falls back to 10 minutes)
+	DefaultCloneTimeout int `json:"default_clone_timeout,omitempty"`
+
+	// README lines shown by get_repository_info (0 falls back to 100)
+	DefaultReadmePreviewLines int `json:"default_readme_preview_lines,omitempty"`
+
+	// Use plain ASCII markers instead of emoji (nil falls back to --no-emoji)
+	NoEmoji *bool `json:"no_emoji,omitempty"`
 }
 
 // Global session config instance
@@ -55,6 +84,19 @@ func SetSessionConfigValues(config *SessionConfig) {
 	if config.DefaultCommitLimit > 0 {
 		globalSessionConfig.DefaultCommitLimit = config.DefaultCommitLimit
 	}
+	if config.DefaultMaxFileBytes > 0 {
+		globalSessionConfig.DefaultMaxFileBytes = config.DefaultMaxFileBytes
+	}
+	if config.DefaultCloneTimeout > 0 {
+		globalSessionConfig.DefaultCloneTimeout = config.DefaultCloneTimeout
+	}
+	if config.DefaultReadmePreviewLines > 0 {
+		globalSessionConfig.DefaultReadmePreviewLines = config.DefaultReadmePreviewLines
+	}
+	if config.NoEmoji != nil {
+		noEmoji := *config.NoEmoji
+		globalSessionConfig.NoEmoji = &noEmoji
+	}
 }
 
 // ClearSessionConfig resets the session configuration to defaults
@@ -69,6 +111,61 @@ func ClearSessionConfig() {
 	globalSessionConfig.DefaultListFilesLimit = 0
 	globalSessionConfig.DefaultMaxLines = 0
 	globalSessionConfig.DefaultCommitLimit = 0
+	globalSessionConfig.DefaultMaxFileBytes = 0
+	globalSessionConfig.DefaultCloneTimeout = 0
+	globalSessionConfig.DefaultReadmePreviewLines = 0
+	globalSessionConfig.NoEmoji = nil
+}
+
+// SaveSessionConfig writes the session configuration to session_config.json in workspaceDir
+func SaveSessionConfig(workspaceDir string) error {
+	globalSessionConfig.mu.RLock()
+	defer globalSessionConfig.mu.RUnlock()
+
+	data, err := json.MarshalIndent(globalSessionConfig, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(workspaceDir, sessionConfigFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write session config file: %v", err)
+	}
+
+	return nil
+}
+
+// LoadSessionConfig replaces the session configuration with the one saved in
+// workspaceDir. A missing file means no configuration has been saved yet.
+func LoadSessionConfig(workspaceDir string) error {
+	globalSessionConfig.mu.Lock()
+	defer globalSessionConfig.mu.Unlock()
+
+	data, err := os.ReadFile(filepath.Join(workspaceDir, sessionConfigFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read session config file: %v", err)
+	}
+
+	var loaded SessionConfig
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("failed to unmarshal session config: %v", err)
+	}
+
+	globalSessionConfig.DefaultRepository = loaded.DefaultRepository
+	globalSessionConfig.DefaultIncludePatterns = loaded.DefaultIncludePatterns
+	globalSessionConfig.DefaultExcludePatterns = loaded.DefaultExcludePatterns
+	globalSessionConfig.DefaultSearchLimit = loaded.DefaultSearchLimit
+	globalSessionConfig.DefaultListFilesLimit = loaded.DefaultListFilesLimit
+	globalSessionConfig.DefaultMaxLines = loaded.DefaultMaxLines
+	globalSessionConfig.DefaultCommitLimit = loaded.DefaultCommitLimit
+	globalSessionConfig.DefaultMaxFileBytes = loaded.DefaultMaxFileBytes
+	globalSessionConfig.DefaultCloneTimeout = loaded.DefaultCloneTimeout
+	globalSessionConfig.DefaultReadmePreviewLines = loaded.DefaultReadmePreviewLines
+	globalSessionConfig.NoEmoji = loaded.NoEmoji
+
+	return nil
 }
 
 // GetRepository returns the provided repository or the default if empty
@@ -153,6 +250,49 @@ func (sc *SessionConfig) GetCommitLimit(provided int) int {
 	return 20 // Default fallback
 }
 
+// GetMaxFileBytes returns the largest file size that may be read in full
+func (sc *SessionConfig) GetMaxFileBytes() int64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if sc.DefaultMaxFileBytes > 0 {
+		return sc.DefaultMaxFileBytes
+	}
+	return defaultMaxFileBytes
+}
+
+// GetCloneTimeout returns how long a clone may run before it is killed
+func (sc *SessionConfig) GetCloneTimeout() time.Duration {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if sc.DefaultCloneTimeout > 0 {
+		return time.Duration(sc.DefaultCloneTimeout) * time.Second
+	}
+	return defaultCloneTimeout
+}
+
+// GetReadmePreviewLines returns the provided README line limit or the default if zero
+func (sc *SessionConfig) GetReadmePreviewLines(provided int) int {
+	if provided > 0 {
+		return provided
+	}
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if sc.DefaultReadmePreviewLines > 0 {
+		return sc.DefaultReadmePreviewLines
+	}
+	return 100 // Default fallback
+}
+
+// GetNoEmoji reports whether formatters should use plain markers instead of emoji
+func (sc *SessionConfig) GetNoEmoji() bool {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if sc.NoEmoji != nil {
+		return *sc.NoEmoji
+	}
+	return defaultNoEmoji
+}
+
 // ToMap returns the session configuration as a map for display
 func (sc *SessionConfig) ToMap() map[string]any {
 	sc.mu.RLock()
@@ -181,6 +321,18 @@ func (sc *SessionConfig) ToMap() map[string]any {
 	if sc.DefaultCommitLimit > 0 {
 		result["default_commit_limit"] = sc.DefaultCommitLimit
 	}
+	if sc.DefaultMaxFileBytes > 0 {
+		result["default_max_file_bytes"] = sc.DefaultMaxFileBytes
+	}
+	if sc.DefaultCloneTimeout > 0 {
+		result["default_clone_timeout"] = sc.DefaultCloneTimeout
+	}
+	if sc.DefaultReadmePreviewLines > 0 {
+		result["default_readme_preview_lines"] = sc.DefaultReadmePreviewLines
+	}
+	if sc.NoEmoji != nil {
+		result["no_emoji"] = *sc.NoEmoji
+	}
 
 	return result
 }
@@ -196,5 +348,9 @@ func (sc *SessionConfig) IsEmpty() bool {
 		sc.DefaultSearchLimit == 0 &&
 		sc.DefaultListFilesLimit == 0 &&
 		sc.DefaultMaxLines == 0 &&
-		sc.DefaultCommitLimit == 0
+		sc.DefaultCommitLimit == 0 &&
+		sc.DefaultMaxFileBytes == 0 &&
+		sc.DefaultCloneTimeout == 0 &&
+		sc.DefaultReadmePreviewLines == 0 &&
+		sc.NoEmoji == nil
 }
@@ -48,7 +48,37 @@ func TestExtractRepoNameFromURL(t *testing.T) {
 		{
 			name:     "URL with query parameters",
 			url:      "https://github.com/user/repo.git?ref=main",
-			expected: "repo.git?ref=main", // This is expected behavior - query params are part of name
+			expected: "repo",
+		},
+		{
+			name:     "URL with several query parameters",
+			url:      "https://github.com/user/repo?ref=main&depth=1",
+			expected: "repo",
+		},
+		{
+			name:     "URL with fragment",
+			url:      "https://github.com/user/repo.git#readme",
+			expected: "repo",
+		},
+		{
+			name:     "URL with query and fragment",
+			url:      "https://github.com/user/repo.git?ref=main#readme",
+			expected: "repo",
+		},
+		{
+			name:     "SSH URL with query parameters",
+			url:      "git@github.com:user/repo.git?ref=main",
+			expected: "repo",
+		},
+		{
+			name:     "SSH URL with fragment",
+			url:      "git@github.com:user/repo#readme",
+			expected: "repo",
+		},
+		{
+			name:     "ssh:// URL with port",
+			url:      "ssh://git@example.com:2222/user/repo.git?ref=main",
+			expected: "repo",
 		},
 		{
 			name:        "Empty URL",
@@ -61,9 +91,28 @@ func TestExtractRepoNameFromURL(t *testing.T) {
 			expected: "not-a-valid-url", // Single segment becomes the name
 		},
 		{
-			name:        "URL ending with slash",
-			url:         "https://github.com/user/repo/",
-			expected:    "", // Empty last segment should cause error
+			name:     "URL ending with slash",
+			url:      "https://github.com/user/repo/",
+			expected: "repo",
+		},
+		{
+			name:     "URL ending with several slashes",
+			url:      "https://github.com/user/repo.git///",
+			expected: "repo",
+		},
+		{
+			name:     "SSH URL ending with slash",
+			url:      "git@github.com:user/repo/",
+			expected: "repo",
+		},
+		{
+			name:        "Only slashes",
+			url:         "///",
+			expectError: true,
+		},
+		{
+			name:        "Host without a path",
+			url:         "https://github.com/",
 			expectError: true,
 		},
 	}
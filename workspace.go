@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -62,11 +63,48 @@ func (wm *WorkspaceManager) ValidateRepositoryPath(path string) (string, error)
 	}
 
 	// Check if the path is within workspace
-	if !wm.isWithinWorkspace(absPath) {
-		return "", fmt.Errorf("repository path must be within workspace directory: %s", wm.workspaceDir)
+	if wm.isWithinWorkspace(absPath) {
+		return absPath, nil
 	}
 
-	return absPath, nil
+	// The workspace or the path may be reached through a symlink (e.g. /tmp on
+	// macOS is /private/tmp), so compare the real locations as well and hand back
+	// the path as seen from the configured workspace directory
+	if rel, ok := wm.realRelPath(absPath); ok {
+		return filepath.Join(wm.workspaceDir, rel), nil
+	}
+
+	return "", fmt.Errorf("repository path must be within workspace directory: %s", wm.workspaceDir)
+}
+
+// realRelPath returns path relative to the workspace after following symlinks
+// in both, and whether it lies within the workspace
+func (wm *WorkspaceManager) realRelPath(path string) (string, bool) {
+	workspace, err := filepath.EvalSymlinks(wm.workspaceDir)
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(workspace, evalSymlinksExisting(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// evalSymlinksExisting follows symlinks in the longest existing prefix of path,
+// so paths that do not exist yet (e.g. a clone target) resolve too
+func evalSymlinksExisting(path string) string {
+	var missing []string
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		if filepath.Dir(dir) == dir {
+			return path
+		}
+		missing = append([]string{filepath.Base(dir)}, missing...)
+	}
 }
 
 // GetRepositoryName extracts the repository name from a repository path within workspace
@@ -116,6 +154,24 @@ func (wm *WorkspaceManager) ListRepositories() ([]string, error) {
 	return repositories, nil
 }
 
+// directorySize returns the total size of the regular files under dir,
+// including the .git directory. Symlinks are not followed.
+func directorySize(dir string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
 // RepositoryExists checks if a repository exists in the workspace
 func (wm *WorkspaceManager) RepositoryExists(repoName string) bool {
 	repoPath := wm.GetRepositoryPath(repoName)
@@ -136,6 +192,99 @@ func (wm *WorkspaceManager) RemoveRepository(repoName string) error {
 	return nil
 }
 
+// RenameRepository renames a repository directory within the workspace
+func (wm *WorkspaceManager) RenameRepository(oldName, newName string) error {
+	for _, name := range []string{oldName, newName} {
+		if err := validateRepositoryName(name); err != nil {
+			return err
+		}
+	}
+
+	if !wm.RepositoryExists(oldName) {
+		return fmt.Errorf("repository '%s' does not exist", oldName)
+	}
+
+	newPath := wm.GetRepositoryPath(newName)
+	if _, err := os.Lstat(newPath); err == nil {
+		return fmt.Errorf("'%s' already exists in workspace", newName)
+	}
+
+	if err := os.Rename(wm.GetRepositoryPath(oldName), newPath); err != nil {
+		return fmt.Errorf("failed to rename repository: %v", err)
+	}
+
+	return nil
+}
+
+// validateRepositoryName checks that name is a single path component that
+// stays inside the workspace
+func validateRepositoryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid repository name: %q", name)
+	}
+	return nil
+}
+
+// WhichRepository finds the workspace repository that contains the given absolute path.
+// Returns the repository name and the path relative to the repository root ("." for the root itself).
+func (wm *WorkspaceManager) WhichRepository(absPath string) (string, string, error) {
+	if absPath == "" {
+		return "", "", fmt.Errorf("path cannot be empty")
+	}
+	if !filepath.IsAbs(absPath) {
+		return "", "", fmt.Errorf("path must be absolute: %s", absPath)
+	}
+
+	cleanPath := filepath.Clean(absPath)
+	if !wm.isWithinWorkspace(cleanPath) {
+		return "", "", fmt.Errorf("path is outside the workspace directory: %s", wm.workspaceDir)
+	}
+
+	repositories, err := wm.ListRepositories()
+	if err != nil {
+		return "", "", err
+	}
+
+	for _, repoName := range repositories {
+		repoPath := wm.GetRepositoryPath(repoName)
+		relPath, err := filepath.Rel(repoPath, cleanPath)
+		if err != nil {
+			continue
+		}
+		if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return repoName, filepath.ToSlash(relPath), nil
+	}
+
+	return "", "", fmt.Errorf("path does not belong to any repository in the workspace: %s", absPath)
+}
+
+// ResolveFilePath joins filePath onto repoPath and follows any symlinks,
+// returning an error when the real location is outside the workspace. Paths
+// that do not exist are returned unresolved for the caller to report.
+func (wm *WorkspaceManager) ResolveFilePath(repoPath, filePath string) (string, error) {
+	fullPath := filepath.Join(repoPath, filePath)
+
+	resolved, err := filepath.EvalSymlinks(fullPath)
+	if err != nil {
+		return fullPath, nil
+	}
+
+	// The workspace itself may live under a symlinked directory (e.g. /tmp on macOS)
+	workspace, err := filepath.EvalSymlinks(wm.workspaceDir)
+	if err != nil {
+		workspace = wm.workspaceDir
+	}
+
+	rel, err := filepath.Rel(workspace, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path resolves outside the workspace: %s", filePath)
+	}
+
+	return resolved, nil
+}
+
 // isWithinWorkspace checks if the given path is within the workspace directory
 func (wm *WorkspaceManager) isWithinWorkspace(path string) bool {
 	// Convert both paths to absolute paths for comparison
@@ -174,6 +323,22 @@ func GetWorkspaceManager() *WorkspaceManager {
 	return globalWorkspaceManager
 }
 
+// WhichRepository resolves an absolute path to its repository using the global workspace manager
+func WhichRepository(absPath string) (string, string, error) {
+	if globalWorkspaceManager == nil {
+		return "", "", fmt.Errorf("workspace not initialized")
+	}
+	return globalWorkspaceManager.WhichRepository(absPath)
+}
+
+// ResolveWorkspaceFile resolves a file inside a repository using the global workspace manager
+func ResolveWorkspaceFile(repoPath, filePath string) (string, error) {
+	if globalWorkspaceManager == nil {
+		return "", fmt.Errorf("workspace not initialized")
+	}
+	return globalWorkspaceManager.ResolveFilePath(repoPath, filePath)
+}
+
 // ValidateWorkspacePath validates a path using the global workspace manager
 func ValidateWorkspacePath(path string) (string, error) {
 	if globalWorkspaceManager == nil {